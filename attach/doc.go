@@ -0,0 +1,5 @@
+/*
+Package attach provides configuration options for attaching to the input and
+output streams of already running containers.
+*/
+package attach
@@ -0,0 +1,94 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/moby/moby/client"
+	"github.com/moby/term"
+)
+
+// Opt is a configuration option to attach to a container using
+// [github.com/thediveo/morbyd.Container.Attach].
+type Opt func(*Options) error
+
+// Options represents the configuration options when attaching to a container,
+// as well as the input and output streams to attach.
+type Options struct {
+	In   io.Reader
+	Out  io.Writer
+	Err  io.Writer
+	Conf client.ContainerAttachOptions
+}
+
+// WithCombinedOutput sends the container's stdout and stderr to the specified
+// io.Writer.
+//
+// Please note that whether the container's output is combined or demuxed
+// solely depends on whether the container has been created with a TTY, see
+// also [github.com/thediveo/morbyd/v2/run.WithTTY]: when using a TTY, the
+// container's output is always combined.
+func WithCombinedOutput(w io.Writer) Opt {
+	return func(o *Options) error {
+		o.Out = w
+		o.Err = w
+		return nil
+	}
+}
+
+// WithDemuxedOutput sends the container's stdout and stderr properly
+// separated to the specified out and err io.Writer. For containers with a TTY,
+// all output goes to out instead.
+func WithDemuxedOutput(out io.Writer, err io.Writer) Opt {
+	return func(o *Options) error {
+		o.Out = out
+		o.Err = err
+		return nil
+	}
+}
+
+// WithInput sends input data from the specified io.Reader to the container's
+// stdin. The container must have been created with stdin open, such as using
+// [github.com/thediveo/morbyd/v2/run.WithStdinOpen].
+func WithInput(r io.Reader) Opt {
+	return func(o *Options) error {
+		o.In = r
+		return nil
+	}
+}
+
+// WithDetachKeys overrides the key sequence for detaching from the container
+// when found in the input, in the format of a comma-separated list of either
+// single characters or “ctrl-X” keys, such as “ctrl-p,ctrl-q”.
+func WithDetachKeys(keys string) Opt {
+	return func(o *Options) error {
+		if _, err := term.ToBytes(keys); err != nil {
+			return fmt.Errorf("invalid detach keys %q, reason: %w", keys, err)
+		}
+		o.Conf.DetachKeys = keys
+		return nil
+	}
+}
+
+// WithLogs replays the container's output produced so far before streaming
+// any new output.
+func WithLogs() Opt {
+	return func(o *Options) error {
+		o.Conf.Logs = true
+		return nil
+	}
+}
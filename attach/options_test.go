@@ -0,0 +1,59 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func opts(opts ...Opt) Options {
+	o := Options{}
+	for _, opt := range opts {
+		Expect(opt(&o)).To(Succeed())
+	}
+	return o
+}
+
+var _ = Describe("attach options", func() {
+
+	It("processes attach options", func() {
+		var out, errout bytes.Buffer
+		in := strings.NewReader("")
+		o := opts(
+			WithInput(in),
+			WithCombinedOutput(&out),
+			WithDetachKeys("ctrl-x,x"),
+			WithLogs())
+		Expect(o.In).To(BeIdenticalTo(in))
+		Expect(o.Out).To(BeIdenticalTo(&out))
+		Expect(o.Err).To(BeIdenticalTo(&out))
+		Expect(o.Conf.DetachKeys).To(Equal("ctrl-x,x"))
+		Expect(o.Conf.Logs).To(BeTrue())
+
+		o = opts(WithDemuxedOutput(&out, &errout))
+		Expect(o.Out).To(BeIdenticalTo(&out))
+		Expect(o.Err).To(BeIdenticalTo(&errout))
+	})
+
+	It("rejects invalid detach keys", func() {
+		Expect(WithDetachKeys("ctrl-ü")(&Options{})).To(
+			MatchError(ContainSubstring(`invalid detach keys "ctrl-ü"`)))
+	})
+
+})
@@ -0,0 +1,100 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/platforms"
+	"github.com/moby/moby/api/types/build"
+
+	"github.com/thediveo/morbyd/v2/internal/dockercmd"
+)
+
+// DockerCommand returns a copy-pasteable “docker build” command line that
+// builds the image from the build context at the specified path with the same
+// effective configuration as these options, such as for manually reproducing
+// a failed image build. An explicitly requested builder is selected using the
+// DOCKER_BUILDKIT environment variable. Inline Dockerfile contents are passed
+// via stdin in form of a here-document.
+func (o *Options) DockerCommand(path string) string {
+	var cmd *dockercmd.Command
+	switch o.Version {
+	case build.BuilderBuildKit:
+		cmd = dockercmd.New("DOCKER_BUILDKIT=1", "docker", "build")
+	case build.BuilderV1:
+		cmd = dockercmd.New("DOCKER_BUILDKIT=0", "docker", "build")
+	default:
+		cmd = dockercmd.New("docker", "build")
+	}
+	cmd.Flags("tag", o.Tags)
+	if o.DockerfileContent != "" {
+		cmd.Flag("file", "-")
+	} else {
+		cmd.Flag("file", o.Dockerfile)
+	}
+	for _, key := range slices.Sorted(maps.Keys(o.BuildArgs)) {
+		if value := o.BuildArgs[key]; value != nil {
+			cmd.Flag("build-arg", key+"="+*value)
+			continue
+		}
+		cmd.Flag("build-arg", key)
+	}
+	cmd.Map("label", o.Labels)
+	cmd.Flag("target", o.Target)
+	if len(o.Platforms) > 0 {
+		plats := make([]string, 0, len(o.Platforms))
+		for _, plat := range o.Platforms {
+			plats = append(plats, platforms.Format(plat))
+		}
+		cmd.Flag("platform", strings.Join(plats, ","))
+	}
+	cmd.Flags("cache-from", o.CacheFrom)
+	cmd.Flag("network", o.NetworkMode)
+	cmd.Bool("no-cache", o.NoCache)
+	cmd.Bool("pull", o.PullParent)
+	cmd.Bool("squash", o.Squash)
+	cmd.Bool("force-rm", o.ForceRemove)
+	for _, secret := range o.Secrets {
+		cmd.Flag("secret", "id="+secret.ID+",src="+secret.Source)
+	}
+	for _, agent := range o.SSHAgents {
+		if len(agent.Paths) == 0 {
+			cmd.Flag("ssh", agent.ID)
+			continue
+		}
+		cmd.Flag("ssh", agent.ID+"="+strings.Join(agent.Paths, ","))
+	}
+	cmd.Flags("add-host", o.ExtraHosts)
+	cmd.Flag("cpuset-cpus", o.CPUSetCPUs)
+	cmd.Flag("cpuset-mems", o.CPUSetMems)
+	if o.Memory != 0 {
+		cmd.Flag("memory", strconv.FormatInt(o.Memory, 10))
+	}
+	if o.ShmSize != 0 {
+		cmd.Flag("shm-size", strconv.FormatInt(o.ShmSize, 10))
+	}
+	cmd.Flag("cgroup-parent", o.CgroupParent)
+	cmd.Flag("isolation", string(o.Isolation))
+	cmd.Add(path)
+	if o.DockerfileContent == "" {
+		return cmd.String()
+	}
+	return cmd.String() + " <<'DOCKERFILE'\n" +
+		strings.TrimSuffix(o.DockerfileContent, "\n") + "\nDOCKERFILE"
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("rendering docker build command lines", func() {
+
+	buildOpts := func(opts ...Opt) Options {
+		GinkgoHelper()
+		o := Options{}
+		for _, opt := range opts {
+			Expect(opt(&o)).To(Succeed())
+		}
+		return o
+	}
+
+	It("renders the effective options", func() {
+		o := buildOpts(
+			WithBuildKit(),
+			WithTag("foo:bar"),
+			WithDockerfile("Dockerfile.test"),
+			WithBuildArgs("FOO=hello world", "BAR"),
+			WithLabel("foo=bar"),
+			WithTarget("final"),
+			WithPlatforms("linux/amd64", "linux/arm64/v8"),
+			WithoutCache(),
+			WithSecret("token", "/tmp/token"),
+			WithSSHAgent(""),
+		)
+		Expect(o.DockerCommand("./test")).To(Equal(
+			"DOCKER_BUILDKIT=1 docker build --tag=foo:bar --file=Dockerfile.test " +
+				"--build-arg=BAR '--build-arg=FOO=hello world' --label=foo=bar " +
+				"--target=final --platform=linux/amd64,linux/arm64/v8 --no-cache " +
+				"--secret=id=token,src=/tmp/token --ssh=default ./test"))
+	})
+
+	It("renders inline Dockerfile contents as a here-document", func() {
+		o := buildOpts(
+			WithBuilderV1(),
+			WithDockerfileContent("FROM busybox\nRUN echo \"$HOME\"\n"),
+		)
+		Expect(o.DockerCommand(".")).To(Equal(
+			"DOCKER_BUILDKIT=0 docker build --file=- . <<'DOCKERFILE'\n" +
+				"FROM busybox\nRUN echo \"$HOME\"\nDOCKERFILE"))
+	})
+
+})
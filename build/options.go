@@ -15,8 +15,12 @@
 package build
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 
+	"github.com/containerd/platforms"
 	"github.com/moby/moby/api/types/build"
 	"github.com/moby/moby/client"
 
@@ -38,8 +42,41 @@ type Opt func(*Options) error
 type Options struct {
 	Out io.Writer
 	client.ImageBuildOptions
+
+	// If not "", then DockerfileContent is injected as the Dockerfile into
+	// the build context, see also [WithDockerfileContent].
+	DockerfileContent string
+
+	// Secrets and SSH agents to expose to BuildKit builds, see also
+	// [WithSecret] and [WithSSHAgent].
+	Secrets   []Secret
+	SSHAgents []SSHAgent
+
+	// Progress events and display mode, see also [WithProgress] and
+	// [WithDisplayMode].
+	Progress    func(BuildEvent)
+	DisplayMode DisplayMode
+}
+
+// Secret is a build secret exposed to “RUN --mount=type=secret,id=ID”
+// instructions of BuildKit builds, with the secret's value read from the
+// Source file.
+type Secret struct {
+	ID     string
+	Source string
+}
+
+// SSHAgent is an SSH agent socket or a set of SSH keys exposed to “RUN
+// --mount=type=ssh,id=ID” instructions of BuildKit builds.
+type SSHAgent struct {
+	ID    string
+	Paths []string
 }
 
+// DefaultSSHAgentID is the ID of the SSH agent used by “RUN --mount=type=ssh”
+// instructions that don't specify an explicit ID.
+const DefaultSSHAgentID = "default"
+
 // WithTag specifies a name and optionally tag in “name:tag” format. This option
 // can be specified multiple times so that the built image will be tagged with
 // these multiple tags. If the “:tag” part is omitted, the default “:latest” is
@@ -85,6 +122,21 @@ func WithDockerfile(name string) Opt {
 	}
 }
 
+// WithDockerfileContent injects the specified Dockerfile contents into the
+// build context, so that no separate Dockerfile is needed in the build context
+// directory or file system. The injected Dockerfile uses the name specified by
+// [WithDockerfile], defaulting to “Dockerfile”, and it replaces any existing
+// file of the same name in the build context.
+func WithDockerfileContent(content string) Opt {
+	return func(o *Options) error {
+		if content == "" {
+			return errors.New("inline Dockerfile content must not be empty")
+		}
+		o.DockerfileContent = content
+		return nil
+	}
+}
+
 // WithLabel adds a key-value label to the built image.
 func WithLabel(label string) Opt {
 	return func(o *Options) error {
@@ -168,6 +220,120 @@ func WithBuilderV1() Opt {
 	}
 }
 
+// WithSecret exposes the contents of the source file as the build secret with
+// the specified ID to “RUN --mount=type=secret,id=ID” instructions. Build
+// secrets require [WithBuildKit]. WithSecret can be specified multiple times,
+// but only once per ID.
+func WithSecret(id string, source string) Opt {
+	return func(o *Options) error {
+		if id == "" {
+			return errors.New("secret ID must not be empty")
+		}
+		if source == "" {
+			return fmt.Errorf("source of secret %q must not be empty", id)
+		}
+		for _, secret := range o.Secrets {
+			if secret.ID == id {
+				return fmt.Errorf("duplicate secret %q", id)
+			}
+		}
+		o.Secrets = append(o.Secrets, Secret{ID: id, Source: source})
+		return nil
+	}
+}
+
+// WithSSHAgent forwards an SSH agent to “RUN --mount=type=ssh,id=ID”
+// instructions, where an empty ID denotes [DefaultSSHAgentID]. The paths
+// specify either a single SSH agent socket or a set of (unencrypted) SSH key
+// files. Without any paths, WithSSHAgent forwards the SSH agent specified by
+// the SSH_AUTH_SOCK environment variable. SSH agent forwarding requires
+// [WithBuildKit]. WithSSHAgent can be specified multiple times, but only once
+// per ID.
+func WithSSHAgent(id string, paths ...string) Opt {
+	return func(o *Options) error {
+		if id == "" {
+			id = DefaultSSHAgentID
+		}
+		for _, agent := range o.SSHAgents {
+			if agent.ID == id {
+				return fmt.Errorf("duplicate SSH agent %q", id)
+			}
+		}
+		o.SSHAgents = append(o.SSHAgents, SSHAgent{ID: id, Paths: paths})
+		return nil
+	}
+}
+
+// WithTarget specifies the build stage to build in a multi-stage Dockerfile.
+func WithTarget(stage string) Opt {
+	return func(o *Options) error {
+		if stage == "" {
+			return errors.New("target build stage must not be empty")
+		}
+		o.Target = stage
+		return nil
+	}
+}
+
+// WithPlatforms specifies the platforms to build the image for, in
+// “os[/arch[/variant]]” format, such as “linux/amd64” or “linux/arm64/v8”.
+// Building for multiple platforms requires a Docker daemon with multi-platform
+// build support. WithPlatforms can be specified multiple times.
+func WithPlatforms(plats ...string) Opt {
+	return func(o *Options) error {
+		for _, plat := range plats {
+			pltfrm, err := platforms.Parse(plat)
+			if err != nil {
+				return err
+			}
+			o.Platforms = append(o.Platforms, pltfrm)
+		}
+		return nil
+	}
+}
+
+// WithCacheFrom specifies images to use as cache sources, such as images
+// built and pushed earlier with an inline cache, see also [WithCacheTo].
+// WithCacheFrom can be specified multiple times.
+func WithCacheFrom(imagerefs ...string) Opt {
+	return func(o *Options) error {
+		for _, ref := range imagerefs {
+			if ref == "" {
+				return errors.New("cache source image reference must not be empty")
+			}
+			o.CacheFrom = append(o.CacheFrom, ref)
+		}
+		return nil
+	}
+}
+
+// WithCacheTo specifies the cache export. As the Docker engine API doesn't
+// support cache exporters other than the inline cache, the only supported
+// cache export is “type=inline” (or simply “inline”), which embeds the build
+// cache metadata into the built image, so it can be later used with
+// [WithCacheFrom]. Inline cache export requires [WithBuildKit].
+func WithCacheTo(cacheto string) Opt {
+	return func(o *Options) error {
+		if cacheto != "inline" && cacheto != "type=inline" {
+			return fmt.Errorf("unsupported cache export %q, only \"type=inline\" is supported", cacheto)
+		}
+		return WithBuildArg("BUILDKIT_INLINE_CACHE=1")(o)
+	}
+}
+
+// WithNetworkMode specifies the networking mode for RUN instructions, such as
+// “default”, “none”, or “host”. The classic builder additionally supports
+// the names of user-defined networks.
+func WithNetworkMode(mode string) Opt {
+	return func(o *Options) error {
+		if strings.TrimSpace(mode) == "" {
+			return errors.New("network mode must not be empty")
+		}
+		o.NetworkMode = mode
+		return nil
+	}
+}
+
 // WithOutput set the writer to which the output of the image build process is
 // sent to.
 func WithOutput(w io.Writer) Opt {
@@ -89,6 +89,70 @@ var _ = Describe("image build options", func() {
 		Expect(WithLabels("foo=", "")(&opts)).Error().To(HaveOccurred())
 	})
 
+	It("sets inline Dockerfile content and rejects empty content", func() {
+		var opts Options
+		Expect(WithDockerfileContent("")(&opts)).Error().To(HaveOccurred())
+		Expect(WithDockerfileContent("FROM scratch\n")(&opts)).Error().NotTo(HaveOccurred())
+		Expect(opts.DockerfileContent).To(Equal("FROM scratch\n"))
+	})
+
+	It("processes BuildKit-specific options", func() {
+		bios := Options{}
+		for _, opt := range []Opt{
+			WithSecret("token", "/run/secrets/token"),
+			WithSSHAgent(""),
+			WithSSHAgent("github", "/tmp/agent.sock"),
+			WithTarget("test"),
+			WithPlatforms("linux/amd64", "linux/arm64/v8"),
+			WithCacheFrom("foo:cache", "bar:cache"),
+			WithCacheTo("type=inline"),
+			WithNetworkMode("none"),
+		} {
+			Expect(opt(&bios)).NotTo(HaveOccurred())
+		}
+		Expect(bios.Secrets).To(ConsistOf(Secret{ID: "token", Source: "/run/secrets/token"}))
+		Expect(bios.SSHAgents).To(ConsistOf(
+			SSHAgent{ID: DefaultSSHAgentID},
+			SSHAgent{ID: "github", Paths: []string{"/tmp/agent.sock"}}))
+		Expect(bios.Target).To(Equal("test"))
+		Expect(bios.Platforms).To(ConsistOf(
+			And(HaveField("OS", "linux"), HaveField("Architecture", "amd64")),
+			And(HaveField("Architecture", "arm64"), HaveField("Variant", "v8"))))
+		Expect(bios.CacheFrom).To(ConsistOf("foo:cache", "bar:cache"))
+		Expect(bios.BuildArgs).To(HaveKeyWithValue("BUILDKIT_INLINE_CACHE", gs.PointTo(Equal("1"))))
+		Expect(bios.NetworkMode).To(Equal("none"))
+	})
+
+	It("rejects invalid BuildKit-specific options", func() {
+		var opts Options
+		Expect(WithSecret("", "foo")(&opts)).Error().To(HaveOccurred())
+		Expect(WithSecret("foo", "")(&opts)).Error().To(HaveOccurred())
+		Expect(WithSecret("foo", "bar")(&opts)).Error().NotTo(HaveOccurred())
+		Expect(WithSecret("foo", "baz")(&opts)).Error().To(MatchError(`duplicate secret "foo"`))
+		Expect(WithSSHAgent("")(&opts)).Error().NotTo(HaveOccurred())
+		Expect(WithSSHAgent(DefaultSSHAgentID)(&opts)).Error().To(MatchError(`duplicate SSH agent "default"`))
+		Expect(WithTarget("")(&opts)).Error().To(HaveOccurred())
+		Expect(WithPlatforms("linux/amd64", "&/")(&opts)).Error().To(HaveOccurred())
+		Expect(WithCacheFrom("")(&opts)).Error().To(HaveOccurred())
+		Expect(WithCacheTo("type=registry,ref=foo")(&opts)).Error().To(
+			MatchError(ContainSubstring("unsupported cache export")))
+		Expect(WithNetworkMode(" ")(&opts)).Error().To(HaveOccurred())
+	})
+
+	It("sets progress function and display mode", func() {
+		var opts Options
+		called := false
+		Expect(WithProgress(func(BuildEvent) { called = true })(&opts)).To(Succeed())
+		opts.Progress(BuildEvent{})
+		Expect(called).To(BeTrue())
+		Expect(WithDisplayMode(DisplayRawJSON)(&opts)).To(Succeed())
+		Expect(opts.DisplayMode).To(Equal(DisplayRawJSON))
+		Expect(WithDisplayMode("")(&opts)).Error().To(HaveOccurred())
+		Expect(WithDisplayMode("fancy")(&opts)).Error().To(MatchError(`unsupported display mode "fancy"`))
+		Expect(VertexCached.String()).To(Equal("vertex-cached"))
+		Expect(EventType(42).String()).To(Equal("EventType(42)"))
+	})
+
 	It("sets builder v1", func() {
 		var opts Options
 		Expect(WithBuilderV1()(&opts)).Error().NotTo(HaveOccurred())
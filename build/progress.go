@@ -0,0 +1,107 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventType identifies the type of a [BuildEvent].
+type EventType int
+
+// The types of build events, normalized across the classic builder and
+// BuildKit.
+const (
+	VertexStarted   EventType = iota // a build step has started.
+	VertexCompleted                  // a build step has completed, successfully or not.
+	VertexCached                     // a build step has been satisfied from the cache.
+	LogLine                          // a build step has logged a line of output.
+	Warning                          // the build raised a warning.
+	ImageID                          // the image has been built.
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case VertexStarted:
+		return "vertex-started"
+	case VertexCompleted:
+		return "vertex-completed"
+	case VertexCached:
+		return "vertex-cached"
+	case LogLine:
+		return "log-line"
+	case Warning:
+		return "warning"
+	case ImageID:
+		return "image-id"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// BuildEvent is a normalized progress event of an image build, regardless of
+// whether the image is built using the classic builder or BuildKit. Depending
+// on the event type, only some fields are set.
+type BuildEvent struct {
+	Type     EventType
+	Step     string        // name of the build step, if any.
+	Duration time.Duration // VertexCompleted and VertexCached only, if known.
+	Error    string        // VertexCompleted only, if the step failed.
+	Line     string        // LogLine only, without line terminator.
+	Warning  string        // Warning only.
+	ImageID  string        // ImageID only.
+}
+
+// DisplayMode specifies how the build progress is rendered to the build
+// output writer set using [WithOutput].
+type DisplayMode string
+
+// The supported display modes; they correspond with the “--progress” modes of
+// “docker build”.
+const (
+	DisplayAuto    DisplayMode = "auto"    // tty if the output is a terminal, otherwise plain.
+	DisplayPlain   DisplayMode = "plain"   // plain text, suitable for CI logs.
+	DisplayTTY     DisplayMode = "tty"     // interactive; requires the output to be a terminal.
+	DisplayQuiet   DisplayMode = "quiet"   // no progress output.
+	DisplayRawJSON DisplayMode = "rawjson" // raw JSON messages, one per line.
+)
+
+// WithProgress sets the function that gets called with the progress events of
+// the build, in addition to any build output rendered to the writer set using
+// [WithOutput]. The progress function is called sequentially from a single go
+// routine and thus must not block for longer periods.
+func WithProgress(fn func(BuildEvent)) Opt {
+	return func(o *Options) error {
+		o.Progress = fn
+		return nil
+	}
+}
+
+// WithDisplayMode sets how the build progress is rendered to the build output
+// writer; it defaults to [DisplayAuto].
+func WithDisplayMode(mode DisplayMode) Opt {
+	return func(o *Options) error {
+		switch mode {
+		case DisplayAuto, DisplayPlain, DisplayTTY, DisplayQuiet, DisplayRawJSON:
+			o.DisplayMode = mode
+			return nil
+		case "":
+			return errors.New("display mode must not be empty")
+		}
+		return fmt.Errorf("unsupported display mode %q", mode)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package caps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capability is a Linux kernel capability, identified by its number as defined
+// in the kernel's include/uapi/linux/capability.h header.
+type Capability uint
+
+// All is the pseudo capability name denoting all capabilities when adding or
+// dropping capabilities.
+const All = "ALL"
+
+// names maps capability numbers to their names without the “CAP_” prefix.
+var names = []string{
+	"CHOWN",
+	"DAC_OVERRIDE",
+	"DAC_READ_SEARCH",
+	"FOWNER",
+	"FSETID",
+	"KILL",
+	"SETGID",
+	"SETUID",
+	"SETPCAP",
+	"LINUX_IMMUTABLE",
+	"NET_BIND_SERVICE",
+	"NET_BROADCAST",
+	"NET_ADMIN",
+	"NET_RAW",
+	"IPC_LOCK",
+	"IPC_OWNER",
+	"SYS_MODULE",
+	"SYS_RAWIO",
+	"SYS_CHROOT",
+	"SYS_PTRACE",
+	"SYS_PACCT",
+	"SYS_ADMIN",
+	"SYS_BOOT",
+	"SYS_NICE",
+	"SYS_RESOURCE",
+	"SYS_TIME",
+	"SYS_TTY_CONFIG",
+	"MKNOD",
+	"LEASE",
+	"AUDIT_WRITE",
+	"AUDIT_CONTROL",
+	"SETFCAP",
+	"MAC_OVERRIDE",
+	"MAC_ADMIN",
+	"SYSLOG",
+	"WAKE_ALARM",
+	"BLOCK_SUSPEND",
+	"AUDIT_READ",
+	"PERFMON",
+	"BPF",
+	"CHECKPOINT_RESTORE",
+}
+
+// Last is the highest capability known to this package.
+const Last = Capability(40)
+
+// Parse returns the capability with the specified name, which is
+// case-insensitive and may or may not have the “CAP_” prefix, such as
+// “CAP_SYS_ADMIN”, “SYS_ADMIN”, or “sys_admin”.
+func Parse(name string) (Capability, error) {
+	upname := strings.TrimPrefix(strings.ToUpper(name), "CAP_")
+	for nr, capname := range names {
+		if capname == upname {
+			return Capability(nr), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown capability %q", name)
+}
+
+// Normalize returns the canonical “CAP_” prefixed name of the capability with
+// the specified name, see also [Parse]. Additionally, Normalize accepts the
+// pseudo capability name [All], in any case.
+func Normalize(name string) (string, error) {
+	if strings.EqualFold(name, All) {
+		return All, nil
+	}
+	c, err := Parse(name)
+	if err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+// String returns the canonical “CAP_” prefixed name of the capability, or
+// “CAP_<number>” for capabilities unknown to this package.
+func (c Capability) String() string {
+	if c > Last {
+		return fmt.Sprintf("CAP_%d", uint(c))
+	}
+	return "CAP_" + names[c]
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package caps
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("capabilities", func() {
+
+	It("knows all capabilities up to the last", func() {
+		Expect(names).To(HaveLen(int(Last) + 1))
+		Expect(Last.String()).To(Equal("CAP_CHECKPOINT_RESTORE"))
+		Expect(Capability(41).String()).To(Equal("CAP_41"))
+	})
+
+	DescribeTable("parsing and normalizing capability names",
+		func(name string, expectedCap Capability, expectedName string) {
+			Expect(Parse(name)).To(Equal(expectedCap))
+			Expect(Normalize(name)).To(Equal(expectedName))
+		},
+		Entry("canonical", "CAP_SYS_ADMIN", Capability(21), "CAP_SYS_ADMIN"),
+		Entry("without prefix", "NET_RAW", Capability(13), "CAP_NET_RAW"),
+		Entry("lower case", "cap_chown", Capability(0), "CAP_CHOWN"),
+		Entry("lower case without prefix", "bpf", Capability(39), "CAP_BPF"),
+	)
+
+	It("normalizes ALL", func() {
+		Expect(Normalize("all")).To(Equal(All))
+		Expect(Parse("ALL")).Error().To(HaveOccurred())
+	})
+
+	It("rejects unknown capabilities", func() {
+		Expect(Parse("CAP_SUCCESS")).Error().To(MatchError(`unknown capability "CAP_SUCCESS"`))
+		Expect(Normalize("")).Error().To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,8 @@
+/*
+Package caps provides validating and normalizing Linux kernel capability names,
+as used in the Docker API for adding and dropping capabilities of containers.
+
+Additionally, [Set] represents sets of capabilities in form of the kernel's bit
+masks, such as the effective or bounding capabilities of a container process.
+*/
+package caps
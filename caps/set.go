@@ -0,0 +1,107 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package caps
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Set is a set of capabilities in form of the bit mask used by the Linux
+// kernel, such as shown in the CapEff, CapPrm and CapBnd fields of
+// /proc/[PID]/status. As Set is a plain integer type, sets can be directly
+// compared, such as using Gomega's Equal matcher.
+type Set uint64
+
+// NewSet returns a set containing the specified capabilities.
+func NewSet(caps ...Capability) Set {
+	var s Set
+	for _, c := range caps {
+		s = s.Add(c)
+	}
+	return s
+}
+
+// ParseSet returns the set of capabilities from the hexadecimal bit mask, such
+// as “000001ffffffffff” from /proc/[PID]/status.
+func ParseSet(mask string) (Set, error) {
+	s, err := strconv.ParseUint(strings.TrimSpace(mask), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid capability set %q, reason: %w", mask, err)
+	}
+	return Set(s), nil
+}
+
+// SetOf returns the set of capabilities with the specified names, see also
+// [Parse].
+func SetOf(names ...string) (Set, error) {
+	var s Set
+	for _, name := range names {
+		c, err := Parse(name)
+		if err != nil {
+			return 0, err
+		}
+		s = s.Add(c)
+	}
+	return s, nil
+}
+
+// Has returns true if the set contains the specified capability.
+func (s Set) Has(c Capability) bool {
+	return c < 64 && s&(1<<c) != 0
+}
+
+// Add returns a new set with the specified capability added.
+func (s Set) Add(c Capability) Set {
+	if c >= 64 {
+		return s
+	}
+	return s | 1<<c
+}
+
+// Remove returns a new set with the specified capability removed.
+func (s Set) Remove(c Capability) Set {
+	if c >= 64 {
+		return s
+	}
+	return s &^ (1 << c)
+}
+
+// Capabilities returns the capabilities in this set, in ascending order.
+func (s Set) Capabilities() []Capability {
+	caps := make([]Capability, 0, bits.OnesCount64(uint64(s)))
+	for m := uint64(s); m != 0; m &= m - 1 {
+		caps = append(caps, Capability(bits.TrailingZeros64(m)))
+	}
+	return caps
+}
+
+// Names returns the canonical “CAP_” prefixed names of the capabilities in
+// this set, in ascending order of the capabilities.
+func (s Set) Names() []string {
+	caps := s.Capabilities()
+	names := make([]string, 0, len(caps))
+	for _, c := range caps {
+		names = append(names, c.String())
+	}
+	return names
+}
+
+// String returns the comma-separated names of the capabilities in this set.
+func (s Set) String() string {
+	return strings.Join(s.Names(), ",")
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package caps
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("capability sets", func() {
+
+	It("parses kernel bit masks", func() {
+		s := Successful(ParseSet("00000000a80425fb"))
+		Expect(s.Has(Capability(0))).To(BeTrue())
+		Expect(s.Has(Capability(1))).To(BeTrue())
+		Expect(s.Has(Capability(2))).To(BeFalse())
+		Expect(s.Names()).To(HaveExactElements(
+			"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_FOWNER", "CAP_FSETID",
+			"CAP_KILL", "CAP_SETGID", "CAP_SETUID", "CAP_SETPCAP",
+			"CAP_NET_BIND_SERVICE", "CAP_NET_RAW", "CAP_SYS_CHROOT",
+			"CAP_MKNOD", "CAP_AUDIT_WRITE", "CAP_SETFCAP"))
+		Expect(Successful(ParseSet("000001ffffffffff")).Capabilities()).To(HaveLen(int(Last) + 1))
+
+		Expect(ParseSet("nada")).Error().To(MatchError(ContainSubstring(`invalid capability set "nada"`)))
+	})
+
+	It("builds and compares sets", func() {
+		s := NewSet(Capability(21), Capability(13))
+		Expect(s).To(Equal(Successful(SetOf("sys_admin", "CAP_NET_RAW"))))
+		Expect(s.String()).To(Equal("CAP_NET_RAW,CAP_SYS_ADMIN"))
+		Expect(s.Remove(Capability(21))).To(Equal(NewSet(Capability(13))))
+		Expect(s.Add(Capability(64))).To(Equal(s))
+		Expect(s.Remove(Capability(64))).To(Equal(s))
+		Expect(s.Has(Capability(64))).To(BeFalse())
+		Expect(Set(0).Names()).To(BeEmpty())
+
+		Expect(SetOf("CAP_SUCCESS")).Error().To(HaveOccurred())
+	})
+
+})
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/thediveo/morbyd/moby (interfaces: Client)
+// Source: github.com/thediveo/morbyd/v2/moby (interfaces: Client)
 //
 // Generated by this command:
 //
-//	mockgen -write_package_comment=false -destination client_mock_test.go -package morbyd github.com/thediveo/morbyd/moby Client
+//	mockgen -write_package_comment=false -destination client_mock_test.go -package morbyd github.com/thediveo/morbyd/v2/moby Client
 //
 
 package morbyd
@@ -130,6 +130,21 @@ func (mr *MockClientMockRecorder) ContainerList(ctx, options any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerList", reflect.TypeOf((*MockClient)(nil).ContainerList), ctx, options)
 }
 
+// ContainerLogs mocks base method.
+func (m *MockClient) ContainerLogs(ctx context.Context, containerID string, options client.ContainerLogsOptions) (client.ContainerLogsResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerLogs", ctx, containerID, options)
+	ret0, _ := ret[0].(client.ContainerLogsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerLogs indicates an expected call of ContainerLogs.
+func (mr *MockClientMockRecorder) ContainerLogs(ctx, containerID, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerLogs", reflect.TypeOf((*MockClient)(nil).ContainerLogs), ctx, containerID, options)
+}
+
 // ContainerPause mocks base method.
 func (m *MockClient) ContainerPause(ctx context.Context, containerID string, options client.ContainerPauseOptions) (client.ContainerPauseResult, error) {
 	m.ctrl.T.Helper()
@@ -175,6 +190,21 @@ func (mr *MockClientMockRecorder) ContainerRename(ctx, containerID, options any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerRename", reflect.TypeOf((*MockClient)(nil).ContainerRename), ctx, containerID, options)
 }
 
+// ContainerResize mocks base method.
+func (m *MockClient) ContainerResize(ctx context.Context, containerID string, options client.ContainerResizeOptions) (client.ContainerResizeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerResize", ctx, containerID, options)
+	ret0, _ := ret[0].(client.ContainerResizeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerResize indicates an expected call of ContainerResize.
+func (mr *MockClientMockRecorder) ContainerResize(ctx, containerID, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerResize", reflect.TypeOf((*MockClient)(nil).ContainerResize), ctx, containerID, options)
+}
+
 // ContainerRestart mocks base method.
 func (m *MockClient) ContainerRestart(ctx context.Context, containerID string, options client.ContainerRestartOptions) (client.ContainerRestartResult, error) {
 	m.ctrl.T.Helper()
@@ -294,6 +324,21 @@ func (mr *MockClientMockRecorder) ExecInspect(ctx, execID, options any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecInspect", reflect.TypeOf((*MockClient)(nil).ExecInspect), ctx, execID, options)
 }
 
+// ExecResize mocks base method.
+func (m *MockClient) ExecResize(ctx context.Context, execID string, options client.ExecResizeOptions) (client.ExecResizeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecResize", ctx, execID, options)
+	ret0, _ := ret[0].(client.ExecResizeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecResize indicates an expected call of ExecResize.
+func (mr *MockClientMockRecorder) ExecResize(ctx, execID, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecResize", reflect.TypeOf((*MockClient)(nil).ExecResize), ctx, execID, options)
+}
+
 // ExecStart mocks base method.
 func (m *MockClient) ExecStart(ctx context.Context, execID string, options client.ExecStartOptions) (client.ExecStartResult, error) {
 	m.ctrl.T.Helper()
@@ -324,6 +369,26 @@ func (mr *MockClientMockRecorder) ImageBuild(ctx, buildContext, options any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageBuild", reflect.TypeOf((*MockClient)(nil).ImageBuild), ctx, buildContext, options)
 }
 
+// ImageHistory mocks base method.
+func (m *MockClient) ImageHistory(ctx context.Context, imageID string, historyOpts ...client.ImageHistoryOption) (client.ImageHistoryResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, imageID}
+	for _, a := range historyOpts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ImageHistory", varargs...)
+	ret0, _ := ret[0].(client.ImageHistoryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImageHistory indicates an expected call of ImageHistory.
+func (mr *MockClientMockRecorder) ImageHistory(ctx, imageID any, historyOpts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, imageID}, historyOpts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageHistory", reflect.TypeOf((*MockClient)(nil).ImageHistory), varargs...)
+}
+
 // ImageInspect mocks base method.
 func (m *MockClient) ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (client.ImageInspectResult, error) {
 	m.ctrl.T.Helper()
@@ -83,6 +83,12 @@ func newWrappedClient(ctrl *mock.Controller, wrapped moby.Client, withouts []str
 				return wrapped.ContainerList(ctx, options)
 			})
 	}
+	if !slices.Contains(withouts, "ContainerLogs") {
+		rec.ContainerLogs(Any, Any, Any).AnyTimes().
+			DoAndReturn(func(ctx context.Context, containerID string, options client.ContainerLogsOptions) (client.ContainerLogsResult, error) {
+				return wrapped.ContainerLogs(ctx, containerID, options)
+			})
+	}
 	if !slices.Contains(withouts, "ContainerPause") {
 		rec.ContainerPause(Any, Any, Any).AnyTimes().
 			DoAndReturn(func(ctx context.Context, containerID string, options client.ContainerPauseOptions) (client.ContainerPauseResult, error) {
@@ -101,6 +107,12 @@ func newWrappedClient(ctrl *mock.Controller, wrapped moby.Client, withouts []str
 				return wrapped.ContainerRename(ctx, containerID, options)
 			})
 	}
+	if !slices.Contains(withouts, "ContainerResize") {
+		rec.ContainerResize(Any, Any, Any).AnyTimes().
+			DoAndReturn(func(ctx context.Context, containerID string, options client.ContainerResizeOptions) (client.ContainerResizeResult, error) {
+				return wrapped.ContainerResize(ctx, containerID, options)
+			})
+	}
 	if !slices.Contains(withouts, "ContainerRestart") {
 		rec.ContainerRestart(Any, Any, Any).AnyTimes().
 			DoAndReturn(func(ctx context.Context, containerID string, options client.ContainerRestartOptions) (client.ContainerRestartResult, error) {
@@ -156,6 +168,12 @@ func newWrappedClient(ctrl *mock.Controller, wrapped moby.Client, withouts []str
 				return wrapped.ExecInspect(ctx, execID, options)
 			})
 	}
+	if !slices.Contains(withouts, "ExecResize") {
+		rec.ExecResize(Any, Any, Any).AnyTimes().
+			DoAndReturn(func(ctx context.Context, execID string, options client.ExecResizeOptions) (client.ExecResizeResult, error) {
+				return wrapped.ExecResize(ctx, execID, options)
+			})
+	}
 
 	if !slices.Contains(withouts, "ImageBuild") {
 		rec.ImageBuild(Any, Any, Any).AnyTimes().
@@ -17,6 +17,7 @@ package morbyd
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/netip"
 	"strconv"
 	"strings"
@@ -36,7 +37,15 @@ const AbbreviatedIDLength = 10
 //   - [Container.IP] returns an host-internal IP address where the container
 //     can be reached.
 //   - [Container.Exec] to execute a command inside the container.
+//   - [Container.Attach] to attach to the input and output of an already
+//     running container.
 //   - [Container.PID] to retrieve the PID of the container's initial process.
+//   - [Container.SecurityContext] to inspect the effective capabilities,
+//     seccomp mode and LSM label of the container's initial process.
+//   - [Container.Namespaces] and [Container.SharesNamespace] to check which
+//     Linux kernel namespaces the container is attached to.
+//   - [Container.Cgroup] to inspect the cgroup limits and usage of the
+//     container.
 //   - [Container.Stop] to stop the container by sending it the configured
 //     signal (defaults to SIGTERM).
 //   - [Container.Kill] to forcefully kill the container using SIGKILL.
@@ -151,7 +160,8 @@ func (c *Container) Unpause(ctx context.Context) error {
 // Stop the container by sending it a termination signal. Default is SIGTERM,
 // unless changed using [run.WithStopSignal].
 func (c *Container) Stop(ctx context.Context) {
-	_ = errOnly(c.Session.moby.ContainerStop(ctx, c.ID, client.ContainerStopOptions{}))
+	ctx, op := c.Session.begin(ctx, "container.stop", c.attrs()...)
+	op.end(errOnly(c.Session.moby.ContainerStop(ctx, c.ID, client.ContainerStopOptions{})))
 }
 
 // Wait for the container to finish, that is, become “not-running” in Docker API
@@ -174,10 +184,21 @@ func (c *Container) Wait(ctx context.Context) error {
 
 // Kill the container forcefully and also remove its volumes.
 func (c *Container) Kill(ctx context.Context) {
-	_, _ = c.Session.moby.ContainerRemove(ctx, c.ID, client.ContainerRemoveOptions{
+	ctx, op := c.Session.begin(ctx, "container.kill", c.attrs()...)
+	op.end(errOnly(c.Session.moby.ContainerRemove(ctx, c.ID, client.ContainerRemoveOptions{
 		RemoveVolumes: true,
 		Force:         true,
-	})
+	})))
+}
+
+// attrs returns the log attributes identifying this container, together with
+// the location of the caller of the Container method calling attrs.
+func (c *Container) attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String(AttrContainerID, c.ID),
+		slog.String(AttrContainerName, c.Name),
+		slog.String(AttrCaller, caller(2, 0)),
+	}
 }
 
 // AbbreviatedID returns an abbreviated container ID for use in error reporting
@@ -230,3 +251,17 @@ func (c *Container) Rename(ctx context.Context, newname string) error {
 	}
 	return c.Refresh(ctx)
 }
+
+// Resize the pseudo TTY of this container to the specified width and height;
+// please note the width-height order in contrast to the Docker API order.
+func (c *Container) Resize(ctx context.Context, width, height uint) error {
+	_, err := c.Session.moby.ContainerResize(ctx, c.ID, client.ContainerResizeOptions{
+		Width:  width,
+		Height: height,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot resize TTY of container %q/%s, reason: %w",
+			c.Name, c.AbbreviatedID(), err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/attach"
+)
+
+// AttachSession represents an attachment to the input and output streams of
+// a container.
+type AttachSession struct {
+	Container *Container // container attached to.
+
+	conn client.HijackedResponse
+	// closes after the output stream from (and optionally our input stream to)
+	// the container has been closed.
+	done chan struct{}
+}
+
+// Attach to the input and output streams of this already running (or created)
+// container, such as a container obtained using [Session.Container], or after
+// restarting a container. It returns an *AttachSession object if successful,
+// otherwise an error.
+//
+// Whether the container's output gets demultiplexed into separate stdout and
+// stderr streams depends on whether the container has been created with a
+// TTY. Use [attach.WithLogs] to additionally receive the container's output
+// produced so far.
+//
+// Note: when using [attach.WithInput] make sure to close the input reader in
+// order to not leak go routines handling the input and output streams in the
+// background.
+func (c *Container) Attach(ctx context.Context, opts ...attach.Opt) (as *AttachSession, err error) {
+	aopts := attach.Options{}
+	for _, opt := range opts {
+		if err := opt(&aopts); err != nil {
+			return nil, fmt.Errorf("cannot attach to container %q/%s, reason: %w",
+				c.Name, c.AbbreviatedID(), err)
+		}
+	}
+
+	ctx, op := c.Session.begin(ctx, "container.attach", c.attrs()...)
+	defer func() { op.end(err) }()
+
+	// We need to know whether the container uses a TTY in order to correctly
+	// handle its output stream(s).
+	details, err := c.Session.moby.ContainerInspect(ctx, c.ID, client.ContainerInspectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot attach to container %q/%s, reason: %w",
+			c.Name, c.AbbreviatedID(), err)
+	}
+	tty := details.Container.Config != nil && details.Container.Config.Tty
+
+	aopts.Conf.Stream = true
+	aopts.Conf.Stdout = true
+	aopts.Conf.Stderr = true
+	aopts.Conf.Stdin = aopts.In != nil
+	attachResp, err := c.Session.moby.ContainerAttach(ctx, c.ID, aopts.Conf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot attach to container %q/%s, reason: %w",
+			c.Name, c.AbbreviatedID(), err)
+	}
+
+	return &AttachSession{
+		Container: c,
+		conn:      attachResp.HijackedResponse,
+		done:      attachStreams(attachResp.HijackedResponse, tty, aopts.In, aopts.Out, aopts.Err),
+	}, nil
+}
+
+// Done returns a channel that gets closed when the attachment has ended,
+// either because the container terminated, the input has been detached using
+// the detach keys, or [AttachSession.Close] has been called.
+func (a *AttachSession) Done() chan struct{} {
+	return a.done
+}
+
+// Wait for the attachment to end, or the passed context to get cancelled.
+func (a *AttachSession) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-a.done:
+		return nil
+	}
+}
+
+// Close the attachment, detaching from the container without affecting the
+// container itself.
+//
+// Note: when using [attach.WithInput], the attachment only ends after the
+// input reader has been closed too.
+func (a *AttachSession) Close() {
+	a.conn.Close()
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/thediveo/morbyd/v2/attach"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/safe"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("attaching to containers", func() {
+
+	var sess *Session
+
+	BeforeEach(func(ctx context.Context) {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		engine := fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		engine.Handle("sh", func(ctx context.Context, proc *fakeengine.Process) int {
+			_, _ = io.WriteString(proc.Stdout, "ready\n")
+			_, _ = io.WriteString(proc.Stderr, "set\n")
+			return fakeengine.Cat(ctx, proc)
+		})
+		sess = Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+	})
+
+	// runEcho runs a container that has produced its initial output and then
+	// waits for input to echo.
+	runEcho := func(ctx context.Context) *Container {
+		GinkgoHelper()
+		var out safe.Buffer
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithStdinOpen(),
+			run.WithCombinedOutput(&out)))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+		Eventually(out.String).Should(Equal("ready\nset\n"))
+		return cntr
+	}
+
+	It("reports errors", func(ctx context.Context) {
+		cntr := &Container{Name: "nada", ID: "nada", Session: sess}
+		Expect(cntr.Attach(ctx, attach.WithDetachKeys("ctrl-"))).Error().To(
+			MatchError(ContainSubstring("invalid detach keys")))
+		Expect(cntr.Attach(ctx)).Error().To(
+			MatchError(ContainSubstring("cannot attach to container")))
+	})
+
+	It("replays the logs and demuxes the output", func(ctx context.Context) {
+		cntr := runEcho(ctx)
+
+		var stdout, stderr safe.Buffer
+		as := Successful(cntr.Attach(ctx,
+			attach.WithLogs(),
+			attach.WithDemuxedOutput(&stdout, &stderr)))
+		Eventually(stdout.String).Should(Equal("ready\n"))
+		Eventually(stderr.String).Should(Equal("set\n"))
+		as.Close()
+		Eventually(as.Done()).Should(BeClosed())
+	})
+
+	It("feeds input and detaches", func(ctx context.Context) {
+		cntr := runEcho(ctx)
+
+		var out safe.Buffer
+		inr, inw := io.Pipe()
+		DeferCleanup(func() { _ = inw.Close() })
+		as := Successful(cntr.Attach(ctx,
+			attach.WithInput(inr),
+			attach.WithCombinedOutput(&out),
+			attach.WithDetachKeys("ctrl-x")))
+		Expect(inw.Write([]byte("Hellorld!\n"))).Error().NotTo(HaveOccurred())
+		Eventually(out.String).Should(Equal("Hellorld!\n"))
+		Consistently(as.Done()).ShouldNot(BeClosed())
+
+		Expect(inw.Write([]byte("\x18"))).Error().NotTo(HaveOccurred())
+		_ = inw.Close()
+		Eventually(as.Done()).Should(BeClosed())
+		Expect(out.String()).To(Equal("Hellorld!\n"))
+		Expect(cntr.Refresh(ctx)).To(Succeed())
+		Expect(cntr.Details.Container.State.Running).To(BeTrue())
+	})
+
+})
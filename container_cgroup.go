@@ -0,0 +1,310 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CgroupUnlimited signals a cgroup limit of “max”, that is, no limit.
+const CgroupUnlimited = -1
+
+// cgroupV1MemoryUnlimited is the memory limit reported by cgroup v1 when there
+// is no memory limit, that is, the maximum int64 value aligned to the page
+// size.
+var cgroupV1MemoryUnlimited = int64(math.MaxInt64) &^ int64(os.Getpagesize()-1)
+
+// Cgroup describes the cgroup of a container's initial process, together with
+// its resource limits and current usage as read from the host's cgroupfs.
+// Limits and usage values not available, such as when the corresponding
+// controller isn't enabled, are left zero.
+type Cgroup struct {
+	// Path of the unified cgroup (v2) hierarchy, relative to the cgroup root;
+	// empty if the host uses cgroup v1 only.
+	Path string
+	// Controllers maps cgroup v1 controller names onto their cgroup paths,
+	// relative to the respective controller hierarchy root; nil on cgroup v2
+	// hosts.
+	Controllers map[string]string
+
+	MemoryMax int64        // memory limit in bytes, or [CgroupUnlimited].
+	CPUMax    CgroupCPUMax // CPU bandwidth limit.
+	PidsMax   int64        // maximum number of processes, or [CgroupUnlimited].
+	CPUSet    string       // CPUs the container is allowed to run on, such as “0-3”.
+	Mems      string       // memory nodes the container is allowed to use.
+
+	MemoryCurrent uint64        // current memory usage in bytes.
+	PidsCurrent   uint64        // current number of processes.
+	CPUUsage      time.Duration // total CPU time consumed.
+}
+
+// CgroupCPUMax is the CPU bandwidth limit of a cgroup, allowing the cgroup to
+// consume up to Quota CPU time within each Period.
+type CgroupCPUMax struct {
+	Quota  int64         // CPU time per period, or [CgroupUnlimited].
+	Period time.Duration // length of a period.
+}
+
+// Cgroup returns the cgroup of the container's initial process, together with
+// its resource limits and current usage, such as for checking the effect of
+// [run.WithCPUSet] and [run.WithMems]. Cgroup resolves the container's cgroup
+// via /proc/[PID]/cgroup and the cgroup mounts of the host, where the PID is
+// obtained using [Container.PID]. Cgroup supports both the unified cgroup v2
+// hierarchy as well as cgroup v1 controller hierarchies.
+//
+// On Docker Desktop, Cgroup returns an error wrapping
+// [ErrProcessesNotAccessible].
+func (c *Container) Cgroup(ctx context.Context) (*Cgroup, error) {
+	pid, err := c.hostPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cgroup, err := readCgroup(os.DirFS("/"), pid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine cgroup of container %s/%q, reason: %w",
+			c.Name, c.AbbreviatedID(), err)
+	}
+	return cgroup, nil
+}
+
+// cgroupMount is a cgroupfs mount, with the hierarchy root that is mounted
+// and the mount point.
+type cgroupMount struct {
+	root       string
+	mountpoint string
+}
+
+// dir returns the mount point directory of the specified cgroup path, without
+// a leading slash as to be suitable for an [fs.FS] of the root file system.
+func (m cgroupMount) dir(cgpath string) (string, bool) {
+	rel, ok := strings.CutPrefix(cgpath, m.root)
+	if !ok || (rel != "" && m.root != "/" && !strings.HasPrefix(rel, "/")) {
+		return "", false
+	}
+	return strings.TrimPrefix(path.Join(m.mountpoint, rel), "/"), true
+}
+
+// readCgroup reads the cgroup information of the process with the specified
+// PID, given the root file system.
+func readCgroup(rootfs fs.FS, pid int) (*Cgroup, error) {
+	procgroups, err := fs.ReadFile(rootfs, "proc/"+strconv.Itoa(pid)+"/cgroup")
+	if err != nil {
+		return nil, err
+	}
+	v2mount, v1mounts, err := readCgroupMounts(rootfs)
+	if err != nil {
+		return nil, err
+	}
+	cgroup := &Cgroup{}
+	for line := range strings.Lines(string(procgroups)) {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			cgroup.Path = fields[2]
+			continue
+		}
+		for ctrl := range strings.SplitSeq(fields[1], ",") {
+			if ctrl == "" {
+				continue
+			}
+			if cgroup.Controllers == nil {
+				cgroup.Controllers = map[string]string{}
+			}
+			cgroup.Controllers[ctrl] = fields[2]
+		}
+	}
+	if cgroup.Controllers == nil {
+		if cgroup.Path == "" {
+			return nil, errors.New("no cgroup found")
+		}
+		if v2mount == nil {
+			return nil, errors.New("no cgroup2 mount found")
+		}
+		dir, ok := v2mount.dir(cgroup.Path)
+		if !ok {
+			return nil, fmt.Errorf("cgroup %q not accessible", cgroup.Path)
+		}
+		cgroup.readV2(rootfs, dir)
+		return cgroup, nil
+	}
+	// Nota bene: in cgroup v1 “hybrid” mode the unified hierarchy doesn't have
+	// any controllers, so we only report its path.
+	cgroup.readV1(rootfs, func(ctrl string) (string, bool) {
+		mount, ok := v1mounts[ctrl]
+		if !ok {
+			return "", false
+		}
+		return mount.dir(cgroup.Controllers[ctrl])
+	})
+	return cgroup, nil
+}
+
+// readV2 reads the limits and usage of a unified cgroup v2 hierarchy cgroup
+// located in the specified directory.
+func (cg *Cgroup) readV2(rootfs fs.FS, dir string) {
+	cg.MemoryMax, _ = readCgroupLimit(rootfs, dir, "memory.max")
+	cg.PidsMax, _ = readCgroupLimit(rootfs, dir, "pids.max")
+	if cpumax, err := readCgroupValue(rootfs, dir, "cpu.max"); err == nil {
+		quota, period, _ := strings.Cut(cpumax, " ")
+		cg.CPUMax.Quota, _ = parseCgroupLimit(quota)
+		if periodus, err := strconv.ParseInt(period, 10, 64); err == nil {
+			cg.CPUMax.Period = time.Duration(periodus) * time.Microsecond
+		}
+	}
+	cg.CPUSet, _ = readCgroupValue(rootfs, dir, "cpuset.cpus")
+	cg.Mems, _ = readCgroupValue(rootfs, dir, "cpuset.mems")
+	cg.MemoryCurrent, _ = readCgroupUint(rootfs, dir, "memory.current")
+	cg.PidsCurrent, _ = readCgroupUint(rootfs, dir, "pids.current")
+	if cpustat, err := fs.ReadFile(rootfs, path.Join(dir, "cpu.stat")); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(cpustat))
+		for scanner.Scan() {
+			if usage, ok := strings.CutPrefix(scanner.Text(), "usage_usec "); ok {
+				usageus, _ := strconv.ParseInt(usage, 10, 64)
+				cg.CPUUsage = time.Duration(usageus) * time.Microsecond
+				break
+			}
+		}
+	}
+}
+
+// readV1 reads the limits and usage of cgroup v1 controller hierarchies, where
+// dirOf returns the directory of the cgroup for a particular controller.
+func (cg *Cgroup) readV1(rootfs fs.FS, dirOf func(ctrl string) (string, bool)) {
+	if dir, ok := dirOf("memory"); ok {
+		cg.MemoryMax, _ = readCgroupLimit(rootfs, dir, "memory.limit_in_bytes")
+		if cg.MemoryMax >= cgroupV1MemoryUnlimited {
+			cg.MemoryMax = CgroupUnlimited
+		}
+		cg.MemoryCurrent, _ = readCgroupUint(rootfs, dir, "memory.usage_in_bytes")
+	}
+	if dir, ok := dirOf("pids"); ok {
+		cg.PidsMax, _ = readCgroupLimit(rootfs, dir, "pids.max")
+		cg.PidsCurrent, _ = readCgroupUint(rootfs, dir, "pids.current")
+	}
+	if dir, ok := dirOf("cpu"); ok {
+		cg.CPUMax.Quota, _ = readCgroupLimit(rootfs, dir, "cpu.cfs_quota_us")
+		if periodus, err := readCgroupUint(rootfs, dir, "cpu.cfs_period_us"); err == nil {
+			cg.CPUMax.Period = time.Duration(periodus) * time.Microsecond
+		}
+	}
+	if dir, ok := dirOf("cpuacct"); ok {
+		if usagens, err := readCgroupUint(rootfs, dir, "cpuacct.usage"); err == nil {
+			cg.CPUUsage = time.Duration(usagens)
+		}
+	}
+	if dir, ok := dirOf("cpuset"); ok {
+		cg.CPUSet, _ = readCgroupValue(rootfs, dir, "cpuset.cpus")
+		cg.Mems, _ = readCgroupValue(rootfs, dir, "cpuset.mems")
+	}
+}
+
+// readCgroupMounts returns the cgroup2 mount, if any, as well as the cgroup v1
+// mounts indexed by controller name, as seen by this process.
+func readCgroupMounts(rootfs fs.FS) (*cgroupMount, map[string]cgroupMount, error) {
+	mountinfo, err := fs.ReadFile(rootfs, "proc/self/mountinfo")
+	if err != nil {
+		return nil, nil, err
+	}
+	var v2mount *cgroupMount
+	v1mounts := map[string]cgroupMount{}
+	for line := range strings.Lines(string(mountinfo)) {
+		// ID parent-ID major:minor root mount-point options [optional...] - fstype source super-options
+		fields := strings.Fields(line)
+		sep := -1
+		for idx, field := range fields {
+			if field == "-" {
+				sep = idx
+				break
+			}
+		}
+		if sep < 5 || len(fields) < sep+4 {
+			continue
+		}
+		mount := cgroupMount{root: fields[3], mountpoint: fields[4]}
+		switch fields[sep+1] {
+		case "cgroup2":
+			if v2mount == nil {
+				v2mount = &mount
+			}
+		case "cgroup":
+			for opt := range strings.SplitSeq(fields[sep+3], ",") {
+				if _, ok := v1mounts[opt]; !ok {
+					v1mounts[opt] = mount
+				}
+			}
+		}
+	}
+	return v2mount, v1mounts, nil
+}
+
+// readCgroupValue returns the (trimmed) contents of the specified cgroupfs
+// file.
+func readCgroupValue(rootfs fs.FS, dir string, name string) (string, error) {
+	value, err := fs.ReadFile(rootfs, path.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// readCgroupUint returns the unsigned integer value of the specified cgroupfs
+// file.
+func readCgroupUint(rootfs fs.FS, dir string, name string) (uint64, error) {
+	value, err := readCgroupValue(rootfs, dir, name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readCgroupLimit returns the limit value of the specified cgroupfs file,
+// where “max” and negative values are reported as [CgroupUnlimited].
+func readCgroupLimit(rootfs fs.FS, dir string, name string) (int64, error) {
+	value, err := readCgroupValue(rootfs, dir, name)
+	if err != nil {
+		return 0, err
+	}
+	return parseCgroupLimit(value)
+}
+
+// parseCgroupLimit parses a cgroup limit value, where “max” and negative
+// values are returned as [CgroupUnlimited].
+func parseCgroupLimit(value string) (int64, error) {
+	if value == "max" {
+		return CgroupUnlimited, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 0 {
+		return CgroupUnlimited, nil
+	}
+	return limit, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"testing/fstest"
+	"time"
+
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+const fakeMountinfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+35 22 0:30 / /sys/fs/cgroup rw,nosuid,nodev,noexec,relatime shared:9 - cgroup2 cgroup2 rw,nsdelegate
+`
+
+const fakeMountinfoV1 = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+35 22 0:30 / /sys/fs/cgroup/unified rw,nosuid shared:9 - cgroup2 cgroup2 rw
+36 22 0:31 / /sys/fs/cgroup/memory rw,nosuid shared:10 - cgroup cgroup rw,memory
+37 22 0:32 / /sys/fs/cgroup/cpu,cpuacct rw,nosuid shared:11 - cgroup cgroup rw,cpu,cpuacct
+38 22 0:33 / /sys/fs/cgroup/pids rw,nosuid shared:12 - cgroup cgroup rw,pids
+39 22 0:34 /docker /sys/fs/cgroup/cpuset rw,nosuid shared:13 - cgroup cgroup rw,cpuset
+malformed
+`
+
+var _ = Describe("container cgroups", func() {
+
+	It("reads cgroup v2 limits and usage", func() {
+		dir := "sys/fs/cgroup/system.slice/docker-42.scope/"
+		rootfs := fstest.MapFS{
+			"proc/42/cgroup":       {Data: []byte("0::/system.slice/docker-42.scope\n")},
+			"proc/self/mountinfo":  {Data: []byte(fakeMountinfo)},
+			dir + "memory.max":     {Data: []byte("67108864\n")},
+			dir + "memory.current": {Data: []byte("1048576\n")},
+			dir + "pids.max":       {Data: []byte("max\n")},
+			dir + "pids.current":   {Data: []byte("1\n")},
+			dir + "cpu.max":        {Data: []byte("50000 100000\n")},
+			dir + "cpu.stat":       {Data: []byte("usage_usec 1500\nuser_usec 1000\n")},
+			dir + "cpuset.cpus":    {Data: []byte("0-1\n")},
+			dir + "cpuset.mems":    {Data: []byte("0\n")},
+		}
+		cgroup := Successful(readCgroup(rootfs, 42))
+		Expect(*cgroup).To(Equal(Cgroup{
+			Path:          "/system.slice/docker-42.scope",
+			MemoryMax:     64 << 20,
+			CPUMax:        CgroupCPUMax{Quota: 50000, Period: 100 * time.Millisecond},
+			PidsMax:       CgroupUnlimited,
+			CPUSet:        "0-1",
+			Mems:          "0",
+			MemoryCurrent: 1 << 20,
+			PidsCurrent:   1,
+			CPUUsage:      1500 * time.Microsecond,
+		}))
+	})
+
+	It("reads cgroup v1 limits and usage", func() {
+		rootfs := fstest.MapFS{
+			"proc/42/cgroup": {Data: []byte(`12:pids:/docker/42
+11:cpu,cpuacct:/docker/42
+10:memory:/docker/42
+9:cpuset:/docker/42
+0::/
+`)},
+			"proc/self/mountinfo": {Data: []byte(fakeMountinfoV1)},
+			"sys/fs/cgroup/memory/docker/42/memory.limit_in_bytes":  {Data: []byte("9223372036854771712\n")},
+			"sys/fs/cgroup/memory/docker/42/memory.usage_in_bytes":  {Data: []byte("4096\n")},
+			"sys/fs/cgroup/pids/docker/42/pids.max":                 {Data: []byte("100\n")},
+			"sys/fs/cgroup/pids/docker/42/pids.current":             {Data: []byte("2\n")},
+			"sys/fs/cgroup/cpu,cpuacct/docker/42/cpu.cfs_quota_us":  {Data: []byte("-1\n")},
+			"sys/fs/cgroup/cpu,cpuacct/docker/42/cpu.cfs_period_us": {Data: []byte("100000\n")},
+			"sys/fs/cgroup/cpu,cpuacct/docker/42/cpuacct.usage":     {Data: []byte("42000\n")},
+			"sys/fs/cgroup/cpuset/42/cpuset.cpus":                   {Data: []byte("1,3\n")},
+			"sys/fs/cgroup/cpuset/42/cpuset.mems":                   {Data: []byte("0\n")},
+		}
+		cgroup := Successful(readCgroup(rootfs, 42))
+		Expect(cgroup.Path).To(Equal("/"))
+		Expect(cgroup.Controllers).To(And(
+			HaveLen(5),
+			HaveKeyWithValue("cpuacct", "/docker/42")))
+		Expect(cgroup.MemoryMax).To(Equal(int64(CgroupUnlimited)))
+		Expect(cgroup.MemoryCurrent).To(Equal(uint64(4096)))
+		Expect(cgroup.PidsMax).To(Equal(int64(100)))
+		Expect(cgroup.PidsCurrent).To(Equal(uint64(2)))
+		Expect(cgroup.CPUMax).To(Equal(CgroupCPUMax{Quota: CgroupUnlimited, Period: 100 * time.Millisecond}))
+		Expect(cgroup.CPUUsage).To(Equal(42 * time.Microsecond))
+		Expect(cgroup.CPUSet).To(Equal("1,3"))
+		Expect(cgroup.Mems).To(Equal("0"))
+	})
+
+	It("reads cgroup v1 memory limits", func() {
+		rootfs := fstest.MapFS{
+			"proc/42/cgroup":      {Data: []byte("10:memory:/docker/42\n")},
+			"proc/self/mountinfo": {Data: []byte(fakeMountinfoV1)},
+			"sys/fs/cgroup/memory/docker/42/memory.limit_in_bytes": {Data: []byte("67108864\n")},
+		}
+		Expect(Successful(readCgroup(rootfs, 42)).MemoryMax).To(Equal(int64(64 << 20)))
+	})
+
+	It("leaves unavailable values zero", func() {
+		cgroup := Successful(readCgroup(fstest.MapFS{
+			"proc/42/cgroup":      {Data: []byte("0::/foo\n")},
+			"proc/self/mountinfo": {Data: []byte(fakeMountinfo)},
+		}, 42))
+		Expect(*cgroup).To(Equal(Cgroup{Path: "/foo"}))
+	})
+
+	DescribeTable("rejecting unusable cgroups",
+		func(rootfs fstest.MapFS, expectedErr string) {
+			Expect(readCgroup(rootfs, 42)).Error().To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("missing process", fstest.MapFS{}, "file does not exist"),
+		Entry("missing mountinfo", fstest.MapFS{
+			"proc/42/cgroup": {Data: []byte("0::/foo\n")},
+		}, "file does not exist"),
+		Entry("no cgroup", fstest.MapFS{
+			"proc/42/cgroup":      {Data: []byte("\n")},
+			"proc/self/mountinfo": {Data: []byte(fakeMountinfo)},
+		}, "no cgroup found"),
+		Entry("no cgroup2 mount", fstest.MapFS{
+			"proc/42/cgroup":      {Data: []byte("0::/foo\n")},
+			"proc/self/mountinfo": {Data: []byte("")},
+		}, "no cgroup2 mount found"),
+		Entry("cgroup outside mount", fstest.MapFS{
+			"proc/42/cgroup": {Data: []byte("0::/foo\n")},
+			"proc/self/mountinfo": {Data: []byte(
+				"35 22 0:30 /bar /sys/fs/cgroup rw - cgroup2 cgroup2 rw\n")},
+		}, `cgroup "/foo" not accessible`),
+	)
+
+	It("parses cgroup limits", func() {
+		Expect(parseCgroupLimit("max")).To(Equal(int64(CgroupUnlimited)))
+		Expect(parseCgroupLimit("-1")).To(Equal(int64(CgroupUnlimited)))
+		Expect(parseCgroupLimit("42")).To(Equal(int64(42)))
+		Expect(parseCgroupLimit("nada")).Error().To(HaveOccurred())
+	})
+
+	It("returns the cgroup of a container", func(ctx context.Context) {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		sess := Successful(NewSession(ctx,
+			session.WithAutoCleaning("test.morbyd=container.cgroup")))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithCommand("/bin/sleep", "120s"),
+			run.WithAutoRemove(),
+			run.WithCPUSet("0"),
+			run.WithMems("0")))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+
+		cgroup := Successful(cntr.Cgroup(ctx))
+		Expect(cgroup.CPUSet).To(Equal("0"))
+		Expect(cgroup.Mems).To(Equal("0"))
+		Expect(cgroup.PidsCurrent).To(BeNumerically(">=", 1))
+	})
+
+})
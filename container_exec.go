@@ -19,8 +19,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"strings"
 
-	"github.com/moby/moby/api/pkg/stdcopy"
 	"github.com/moby/moby/client"
 
 	"github.com/thediveo/morbyd/v2/exec"
@@ -67,6 +68,13 @@ func (c *Container) Exec(ctx context.Context, cmd exec.Cmd, opts ...exec.Opt) (e
 		}
 	}
 
+	ctx, op := c.Session.begin(ctx, "container.exec",
+		slog.String(AttrContainerID, c.ID),
+		slog.String(AttrContainerName, c.Name),
+		slog.String(AttrCommand, strings.Join(cmd, " ")),
+		slog.String(AttrCaller, caller(1, 0)))
+	defer func() { op.end(err) }()
+
 	if exopts.Out == nil {
 		exopts.Out = io.Discard
 	}
@@ -92,6 +100,8 @@ func (c *Container) Exec(ctx context.Context, cmd exec.Cmd, opts ...exec.Opt) (e
 			c.Name, c.AbbreviatedID(), err)
 	}
 
+	op.set(slog.String(AttrExecID, execResp.ID))
+
 	// now start executing the command and at the same time attach to its input
 	// and output. Nota bene: the Docker Go client is confusing here, as there's
 	// also a ExecStart which also starts the exec but doesn't attach.
@@ -104,39 +114,7 @@ func (c *Container) Exec(ctx context.Context, cmd exec.Cmd, opts ...exec.Opt) (e
 			c.ID, c.AbbreviatedID(), err)
 	}
 
-	stdinDone := make(chan struct{})
-	allDone := make(chan struct{})
-	// Deal with the output stream...
-	go func() {
-		defer func() {
-			<-stdinDone
-			attachResp.Close()
-			close(allDone)
-		}()
-
-		if exopts.Conf.TTY {
-			// When using a TTY, only copy the single combined output stream
-			// into the output stream specified in the run options.
-			_, _ = io.Copy(exopts.Out, attachResp.Reader)
-			return
-		}
-		// When NOT using a TTY, use Docker's own helper to demux the two
-		// multiplexed streams into stdout and stderr writers.
-		stderr := exopts.Err
-		if stderr == nil {
-			stderr = io.Discard
-		}
-		_, _ = stdcopy.StdCopy(exopts.Out, stderr, attachResp.Reader)
-	}()
-	// Deal with the input stream, where necessary.
-	if exopts.In != nil {
-		go func() {
-			defer close(stdinDone)
-			_, _ = io.Copy(attachResp.Conn, exopts.In)
-		}()
-	} else {
-		close(stdinDone)
-	}
+	allDone := attachStreams(attachResp.HijackedResponse, exopts.Conf.TTY, exopts.In, exopts.Out, exopts.Err)
 
 	// At this point the command might not have actually been started in the
 	// container, as we've found out the hard time in the early days of our unit
@@ -184,6 +162,20 @@ func (e *ExecSession) PID(ctx context.Context) (int, error) {
 	}
 }
 
+// Resize the pseudo TTY of the executing command to the specified width and
+// height; please note the width-height order in contrast to the Docker API
+// order.
+func (e *ExecSession) Resize(ctx context.Context, width, height uint) error {
+	_, err := e.Container.Session.moby.ExecResize(ctx, e.ID, client.ExecResizeOptions{
+		Width:  width,
+		Height: height,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot resize TTY of executing command, reason: %w", err)
+	}
+	return nil
+}
+
 // Done returns a channel that gets closed when the command has finished
 // executing inside its container.
 func (e *ExecSession) Done() chan struct{} {
@@ -0,0 +1,137 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+)
+
+// ErrProcessesNotAccessible signals that the processes of a container cannot
+// be accessed from the host, such as with Docker Desktop where containers run
+// inside a separate VM.
+var ErrProcessesNotAccessible = errors.New("container processes not accessible from host")
+
+// NamespaceType is the type of a Linux kernel namespace, named as in
+// /proc/[PID]/ns/.
+type NamespaceType string
+
+// Linux kernel namespace types.
+const (
+	NamespaceCgroup          NamespaceType = "cgroup"
+	NamespaceIPC             NamespaceType = "ipc"
+	NamespaceMount           NamespaceType = "mnt"
+	NamespaceNet             NamespaceType = "net"
+	NamespacePID             NamespaceType = "pid"
+	NamespacePIDForChildren  NamespaceType = "pid_for_children"
+	NamespaceTime            NamespaceType = "time"
+	NamespaceTimeForChildren NamespaceType = "time_for_children"
+	NamespaceUser            NamespaceType = "user"
+	NamespaceUTS             NamespaceType = "uts"
+)
+
+// NamespaceID identifies a Linux kernel namespace by the device and inode
+// numbers of its nsfs inode, as shown by “ls -L -i /proc/[PID]/ns/”.
+type NamespaceID struct {
+	Dev uint64
+	Ino uint64
+}
+
+// String returns the namespace ID in the textual form of the kernel, such as
+// “4026531840”, as the inode number is unique within the nsfs device.
+func (id NamespaceID) String() string {
+	return strconv.FormatUint(id.Ino, 10)
+}
+
+// Namespaces returns the IDs of all namespaces the initial process of this
+// container is attached to, indexed by namespace type. The namespace types
+// available depend on the Linux kernel.
+//
+// On Docker Desktop, Namespaces returns an error wrapping
+// [ErrProcessesNotAccessible].
+func (c *Container) Namespaces(ctx context.Context) (map[NamespaceType]NamespaceID, error) {
+	pid, err := c.hostPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := readNamespaces(os.DirFS("/proc"), pid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine namespaces of container %s/%q, reason: %w",
+			c.Name, c.AbbreviatedID(), err)
+	}
+	return namespaces, nil
+}
+
+// SharesNamespace returns true if this container and the other container are
+// attached to the same namespace of the specified type, such as after running
+// the other container using [run.WithNetworkMode]("container:...").
+func (c *Container) SharesNamespace(ctx context.Context, other *Container, typ NamespaceType) (bool, error) {
+	namespaces, err := c.Namespaces(ctx)
+	if err != nil {
+		return false, err
+	}
+	otherNamespaces, err := other.Namespaces(ctx)
+	if err != nil {
+		return false, err
+	}
+	id, ok := namespaces[typ]
+	if !ok {
+		return false, fmt.Errorf("unsupported namespace type %q", typ)
+	}
+	otherID, ok := otherNamespaces[typ]
+	if !ok {
+		return false, fmt.Errorf("unsupported namespace type %q", typ)
+	}
+	return id == otherID, nil
+}
+
+// hostPID returns the PID of the container's initial process for accessing it
+// in the host's procfs, or an error wrapping [ErrProcessesNotAccessible] in
+// case of Docker Desktop.
+func (c *Container) hostPID(ctx context.Context) (int, error) {
+	if c.Session.IsDockerDesktop(ctx) {
+		return 0, fmt.Errorf("cannot access processes of container %s/%q, reason: %w",
+			c.Name, c.AbbreviatedID(), ErrProcessesNotAccessible)
+	}
+	return c.PID(ctx)
+}
+
+// readNamespaces reads the namespace IDs of the process with the specified
+// PID from the procfs.
+func readNamespaces(procfs fs.FS, pid int) (map[NamespaceType]NamespaceID, error) {
+	nsdir := strconv.Itoa(pid) + "/ns"
+	entries, err := fs.ReadDir(procfs, nsdir)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make(map[NamespaceType]NamespaceID, len(entries))
+	for _, entry := range entries {
+		// Make sure to follow the namespace symlink to its nsfs inode.
+		info, err := fs.Stat(procfs, nsdir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		id, err := namespaceID(info)
+		if err != nil {
+			return nil, fmt.Errorf("cannot stat namespace %q, reason: %w", entry.Name(), err)
+		}
+		namespaces[NamespaceType(entry.Name())] = id
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package morbyd
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+)
+
+// namespaceID returns the ID of the namespace with the specified nsfs inode
+// information.
+func namespaceID(info fs.FileInfo) (NamespaceID, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return NamespaceID{}, errors.New("missing inode information")
+	}
+	return NamespaceID{
+		Dev: uint64(stat.Dev), //nolint:unconvert // not uint64 on all platforms
+		Ino: uint64(stat.Ino), //nolint:unconvert // not uint64 on all platforms
+	}, nil
+}
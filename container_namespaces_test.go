@@ -0,0 +1,102 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package morbyd
+
+import (
+	"context"
+	"syscall"
+	"testing/fstest"
+	"time"
+
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("container namespaces", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("reads namespace IDs from procfs", func() {
+		procfs := fstest.MapFS{
+			"42/ns/net": {Sys: &syscall.Stat_t{Dev: 4, Ino: 4026531840}},
+			"42/ns/pid": {Sys: &syscall.Stat_t{Dev: 4, Ino: 4026531836}},
+		}
+		namespaces := Successful(readNamespaces(procfs, 42))
+		Expect(namespaces).To(HaveLen(2))
+		Expect(namespaces).To(HaveKeyWithValue(NamespaceNet, NamespaceID{Dev: 4, Ino: 4026531840}))
+		Expect(namespaces[NamespacePID].String()).To(Equal("4026531836"))
+
+		Expect(readNamespaces(fstest.MapFS{}, 42)).Error().To(HaveOccurred())
+		Expect(readNamespaces(fstest.MapFS{
+			"42/ns/net": {},
+		}, 42)).Error().To(MatchError(ContainSubstring(`cannot stat namespace "net"`)))
+	})
+
+	It("reports inaccessible processes on Docker Desktop", func(ctx context.Context) {
+		engine := fakeengine.New()
+		engine.SetPlatformName("Docker Desktop 4.2.0 (fake)")
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+		Expect(cntr.Namespaces(ctx)).Error().To(MatchError(ErrProcessesNotAccessible))
+		Expect(cntr.SharesNamespace(ctx, cntr, NamespaceNet)).Error().To(MatchError(ErrProcessesNotAccessible))
+		Expect(cntr.SecurityContext(ctx)).Error().To(MatchError(ErrProcessesNotAccessible))
+	})
+
+	It("checks for shared namespaces", func(ctx context.Context) {
+		sess := Successful(NewSession(ctx,
+			session.WithAutoCleaning("test.morbyd=container.namespaces")))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithCommand("/bin/sleep", "120s"),
+			run.WithAutoRemove()))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+		sidecar := Successful(sess.Run(ctx, "busybox",
+			run.WithCommand("/bin/sleep", "120s"),
+			run.WithAutoRemove(),
+			run.WithNetworkMode("container:"+cntr.ID),
+			run.WithIPCMode("private")))
+		DeferCleanup(func(ctx context.Context) { sidecar.Kill(ctx) })
+
+		namespaces := Successful(cntr.Namespaces(ctx))
+		Expect(namespaces).To(HaveKey(NamespaceNet))
+		Expect(namespaces).To(HaveKey(NamespaceMount))
+
+		Expect(cntr.SharesNamespace(ctx, sidecar, NamespaceNet)).To(BeTrue())
+		Expect(cntr.SharesNamespace(ctx, sidecar, NamespacePID)).To(BeFalse())
+		Expect(cntr.SharesNamespace(ctx, sidecar, NamespaceIPC)).To(BeFalse())
+		Expect(cntr.SharesNamespace(ctx, sidecar, "nada")).Error().To(
+			MatchError(ContainSubstring(`unsupported namespace type "nada"`)))
+	})
+
+})
@@ -0,0 +1,230 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/thediveo/morbyd/v2/run"
+)
+
+// Labels of reusable containers, see also [run.WithReuse].
+const (
+	// ContainerReuseKeyLabelName defines the name of the container label
+	// storing the reuse key of a reusable container.
+	ContainerReuseKeyLabelName = MorbydLabelNamespace + "reuse.key"
+	// ContainerReuseHashLabelName defines the name of the container label
+	// storing the hash of the effective options of a reusable container.
+	ContainerReuseHashLabelName = MorbydLabelNamespace + "reuse.hash"
+	// ContainerReuseExpiryLabelName defines the name of the container label
+	// storing the expiry time of a reusable container in RFC3339 format.
+	ContainerReuseExpiryLabelName = MorbydLabelNamespace + "reuse.expires"
+)
+
+// reuseHash returns the hash of the effective options of a reusable container
+// created from the image with the specified ID. The hash covers the reuse key,
+// image ID, container name, configuration, host configuration, networking
+// configuration, and platform, but neither the morbyd-specific labels nor the
+// labels inherited from the session that vary between otherwise identical
+// containers, such as auto-cleaning labels specific to individual tests.
+func reuseHash(imageID string, copts *run.Options, sessionLabels map[string]string) (string, error) {
+	config := container.Config{}
+	if copts.Opts.Config != nil {
+		config = *copts.Opts.Config
+		config.Labels = maps.Clone(config.Labels)
+		for key := range sessionLabels {
+			delete(config.Labels, key)
+		}
+		delete(config.Labels, ContainerRunnerLabelName)
+		delete(config.Labels, ContainerDockerCommandLabelName)
+		delete(config.Labels, ContainerReuseKeyLabelName)
+		delete(config.Labels, ContainerReuseHashLabelName)
+		delete(config.Labels, ContainerReuseExpiryLabelName)
+	}
+	effective := struct {
+		Key              string
+		ImageID          string
+		Name             string
+		Config           container.Config
+		HostConfig       *container.HostConfig
+		NetworkingConfig *network.NetworkingConfig
+		Platform         *ocispec.Platform
+	}{
+		Key:              copts.ReuseKey,
+		ImageID:          imageID,
+		Name:             copts.Opts.Name,
+		Config:           config,
+		HostConfig:       copts.Opts.HostConfig,
+		NetworkingConfig: copts.Opts.NetworkingConfig,
+		Platform:         copts.Opts.Platform,
+	}
+	b, err := json.Marshal(effective)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash container options, reason: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// prepareReuse labels the container to be created as reusable, returning the
+// hash of its effective options.
+func (s *Session) prepareReuse(ctx context.Context, imageref string, copts *run.Options) (string, error) {
+	img, err := s.moby.ImageInspect(ctx, imageref)
+	if err != nil {
+		return "", fmt.Errorf("cannot inspect image %s, reason: %w", imageref, err)
+	}
+	hash, err := reuseHash(img.ID, copts, s.opts.Labels)
+	if err != nil {
+		return "", err
+	}
+	labels := copts.Opts.Config.Labels
+	labels[ContainerReuseKeyLabelName] = copts.ReuseKey
+	labels[ContainerReuseHashLabelName] = hash
+	if copts.ReuseTTL > 0 {
+		labels[ContainerReuseExpiryLabelName] = time.Now().Add(copts.ReuseTTL).UTC().Format(time.RFC3339)
+	}
+	return hash, nil
+}
+
+// reusable returns a running, unexpired container with the specified reuse
+// hash, or nil if there is no such container. It removes any stale containers
+// with the same hash, that is, containers that aren't running anymore or that
+// have expired.
+func (s *Session) reusable(ctx context.Context, hash string) (*Container, error) {
+	cntrs, err := s.moby.ContainerList(ctx, client.ContainerListOptions{
+		All:     true,
+		Filters: make(client.Filters).Add("label", ContainerReuseHashLabelName+"="+hash),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list reusable containers, reason: %w", err)
+	}
+	for _, cntr := range cntrs.Items {
+		if cntr.State != container.StateRunning || reuseExpired(cntr.Labels) {
+			_ = s.removeContainer(ctx, cntr.ID)
+			continue
+		}
+		reused, err := s.Container(ctx, cntr.ID)
+		if err != nil {
+			continue
+		}
+		return reused, nil
+	}
+	return nil, nil
+}
+
+// createOverReusable handles the specified error when creating a reusable
+// container: if the error is a name conflict with a reusable container with
+// the same key, then either this container gets reused, or it is stale and
+// gets removed, so that creating the reusable container is tried once more.
+// Otherwise, createOverReusable returns the specified error.
+func (s *Session) createOverReusable(
+	ctx context.Context, copts *run.Options, hash string, createErr error,
+) (*Container, client.ContainerCreateResult, error) {
+	if !errdefs.IsConflict(createErr) || copts.Opts.Name == "" {
+		return nil, client.ContainerCreateResult{}, createErr
+	}
+	squatter, err := s.moby.ContainerInspect(ctx, copts.Opts.Name, client.ContainerInspectOptions{})
+	if err != nil {
+		return nil, client.ContainerCreateResult{}, createErr
+	}
+	reused, retry := s.reuseSquatter(ctx, squatter, copts.ReuseKey, hash)
+	if reused != nil {
+		return reused, client.ContainerCreateResult{}, nil
+	}
+	if !retry {
+		return nil, client.ContainerCreateResult{}, createErr
+	}
+	resp, err := s.moby.ContainerCreate(ctx, copts.Opts)
+	return nil, resp, err
+}
+
+// reuseSquatter checks if the container squatting on the name of a reusable
+// container to be created is a reusable container that can be returned
+// instead, or a stale one that has been removed so that creating the reusable
+// container can be retried. It returns nil and false if the squatter is
+// neither.
+func (s *Session) reuseSquatter(ctx context.Context, squatter client.ContainerInspectResult, key, hash string) (cntr *Container, retry bool) {
+	details := squatter.Container
+	if details.Config == nil || details.Config.Labels[ContainerReuseKeyLabelName] != key {
+		return nil, false
+	}
+	if details.Config.Labels[ContainerReuseHashLabelName] == hash &&
+		details.State != nil && details.State.Running &&
+		!reuseExpired(details.Config.Labels) {
+		return &Container{
+			Name:    strings.TrimPrefix(details.Name, "/"),
+			ID:      details.ID,
+			Session: s,
+			Details: squatter,
+		}, false
+	}
+	// The squatter is a stale reusable container with the same key, but with
+	// outdated options or not running anymore.
+	return nil, s.removeContainer(ctx, details.ID) == nil
+}
+
+// reuseExpired returns true if the specified labels indicate a reusable
+// container that has expired.
+func reuseExpired(labels map[string]string) bool {
+	expiry, ok := labels[ContainerReuseExpiryLabelName]
+	if !ok {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, expiry)
+	return err != nil || time.Now().After(expires)
+}
+
+// isReused returns true if the specified labels indicate a reusable container
+// that must not be auto-cleaned, as it has not yet expired.
+func isReused(labels map[string]string) bool {
+	_, ok := labels[ContainerReuseKeyLabelName]
+	return ok && !reuseExpired(labels)
+}
+
+// ExpireReusable forcefully removes all reusable containers with the
+// specified reuse key, regardless of their time-to-live. It doesn't stop at
+// the first container it cannot remove, but instead returns an aggregated
+// error listing all the containers that could not be removed. See also
+// [run.WithReuse].
+func (s *Session) ExpireReusable(ctx context.Context, key string) error {
+	cntrs, err := s.moby.ContainerList(ctx, client.ContainerListOptions{
+		All:     true,
+		Filters: make(client.Filters).Add("label", ContainerReuseKeyLabelName+"="+key),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot list reusable containers, reason: %w", err)
+	}
+	var errs []error
+	for _, cntr := range cntrs.Items {
+		if err := s.removeContainer(ctx, cntr.ID); err != nil {
+			errs = append(errs, fmt.Errorf("cannot remove reusable container %s, reason: %w", cntr.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
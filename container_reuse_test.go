@@ -0,0 +1,142 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("reusing containers", func() {
+
+	var engine *fakeengine.Engine
+
+	BeforeEach(func() {
+		engine = fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+	})
+
+	newSession := func(ctx context.Context, opts ...session.Opt) *Session {
+		GinkgoHelper()
+		sess := Successful(NewSession(ctx, append([]session.Opt{
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=reuse")}, opts...)...))
+		DeferCleanup(func(ctx context.Context) {
+			sess.Close(ctx)
+		})
+		return sess
+	}
+
+	It("reuses a running container across sessions", func(ctx context.Context) {
+		sess := newSession(ctx)
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithReuse("db"),
+			run.WithEnvVars("FOO=bar")))
+		Expect(cntr.Details.Container.Config.Labels).To(And(
+			HaveKeyWithValue(ContainerReuseKeyLabelName, "db"),
+			HaveKeyWithValue(ContainerReuseHashLabelName, MatchRegexp(`^[[:xdigit:]]{64}$`)),
+			Not(HaveKey(ContainerReuseExpiryLabelName))))
+		sess.Close(ctx)
+		Expect(cntr.Refresh(ctx)).To(Succeed())
+
+		sess = newSession(ctx)
+		reused := Successful(sess.Run(ctx, "busybox",
+			run.WithReuse("db"),
+			run.WithEnvVars("FOO=bar")))
+		Expect(reused.ID).To(Equal(cntr.ID))
+
+		By("reusing a container across sessions with different labels")
+		othersess := newSession(ctx,
+			session.WithAutoCleaning("test.morbyd=reuse-other"),
+			session.WithLabel("morbyd.test=other"))
+		reused = Successful(othersess.Run(ctx, "busybox",
+			run.WithReuse("db"),
+			run.WithEnvVars("FOO=bar")))
+		Expect(reused.ID).To(Equal(cntr.ID))
+
+		By("not reusing a container with different options")
+		other := Successful(sess.Run(ctx, "busybox",
+			run.WithReuse("db"),
+			run.WithEnvVars("FOO=baz")))
+		Expect(other.ID).NotTo(Equal(cntr.ID))
+
+		By("expiring the other reusable containers despite failing to remove one")
+		engine.FailNext("ContainerRemove", errors.New("error IJK305I"))
+		Expect(sess.ExpireReusable(ctx, "db")).To(MatchError(ContainSubstring("error IJK305I")))
+		Expect([]error{cntr.Refresh(ctx), other.Refresh(ctx)}).To(ContainElement(HaventFoundContainer()))
+
+		By("expiring reusable containers")
+		Expect(sess.ExpireReusable(ctx, "db")).To(Succeed())
+		Expect(cntr.Refresh(ctx)).To(HaventFoundContainer())
+		Expect(other.Refresh(ctx)).To(HaventFoundContainer())
+	})
+
+	It("replaces a stale reusable container", func(ctx context.Context) {
+		sess := newSession(ctx)
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithName("morbyd-reuse"),
+			run.WithReuse("db")))
+		cntr.Stop(ctx)
+		Expect(cntr.Wait(ctx)).To(Succeed())
+
+		fresh := Successful(sess.Run(ctx, "busybox",
+			run.WithName("morbyd-reuse"),
+			run.WithReuse("db")))
+		Expect(fresh.ID).NotTo(Equal(cntr.ID))
+		Expect(cntr.Refresh(ctx)).To(HaventFoundContainer())
+
+		By("replacing a reusable container with outdated options squatting on the name")
+		updated := Successful(sess.Run(ctx, "busybox",
+			run.WithName("morbyd-reuse"),
+			run.WithReuse("db"),
+			run.WithEnvVars("FOO=bar")))
+		Expect(updated.ID).NotTo(Equal(fresh.ID))
+		Expect(fresh.Refresh(ctx)).To(HaventFoundContainer())
+
+		By("refusing to replace a non-reusable squatter")
+		Expect(sess.Run(ctx, "busybox", run.WithName("morbyd-squatter"))).Error().NotTo(HaveOccurred())
+		Expect(sess.Run(ctx, "busybox",
+			run.WithName("morbyd-squatter"),
+			run.WithReuse("db"))).Error().To(MatchError(ContainSubstring("name already taken by")))
+	})
+
+	It("auto-cleans only expired reusable containers", func(ctx context.Context) {
+		sess := newSession(ctx)
+		kept := Successful(sess.Run(ctx, "busybox",
+			run.WithReuse("db"),
+			run.WithReuseTTL(time.Hour)))
+		Expect(kept.Details.Container.Config.Labels).To(
+			HaveKey(ContainerReuseExpiryLabelName))
+		expiring := Successful(sess.Run(ctx, "busybox",
+			run.WithReuse("cache"),
+			run.WithReuseTTL(time.Millisecond)))
+		time.Sleep(1100 * time.Millisecond) // expiry has a resolution of seconds.
+
+		Expect(sess.AutoCleanErr(ctx)).To(Succeed())
+		Expect(kept.Refresh(ctx)).To(Succeed())
+		Expect(expiring.Refresh(ctx)).To(HaventFoundContainer())
+		Expect(sess.ExpireReusable(ctx, "db")).To(Succeed())
+	})
+
+})
@@ -18,13 +18,15 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"maps"
+	"strings"
 
 	"github.com/containerd/errdefs"
-	"github.com/moby/moby/api/pkg/stdcopy"
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/client"
 
+	"github.com/thediveo/morbyd/v2/internal/ensure"
 	"github.com/thediveo/morbyd/v2/run"
 )
 
@@ -33,6 +35,11 @@ import (
 // function.
 const ContainerRunnerLabelName = MorbydLabelNamespace + "container.runner"
 
+// ContainerDockerCommandLabelName defines the name of a container label
+// describing the equivalent “docker run” command line to manually recreate
+// the container, see also [session.WithDockerCommandLabel].
+const ContainerDockerCommandLabelName = MorbydLabelNamespace + "container.command"
+
 // Run (create and start) a new container, using the referenced image and
 // optional configuration information, returning a *Container object if
 // successful. Otherwise, it returns an error without leaving behind any
@@ -42,10 +49,27 @@ const ContainerRunnerLabelName = MorbydLabelNamespace + "container.runner"
 // can be accessed using [run.WithInput], and either [run.WithCombinedOutput] or
 // [run.WithDemuxedOutput].
 //
+// Run pulls the referenced image only if it isn't available locally, unless
+// specified otherwise using [run.WithPullPolicy]. Use [run.WithPullOptions] to
+// pass options to the pull, such as a platform or registry credentials.
+//
 // If the session has configured with labels, the new container inherits them.
 // Use [run.ClearLabels] before [run.WithLabel] or [run.WithLabels] in order to
-// remove any inherited labels first.
-func (s *Session) Run(ctx context.Context, imageref string, opts ...run.Opt) (cntr *Container, err error) {
+// remove any inherited labels first. If the session has been configured using
+// [session.WithDockerCommandLabel], Run labels the new container with the
+// equivalent “docker run” command line.
+//
+// When reusing an already running container as specified using
+// [run.WithReuse], Run ignores any input and output streams specified using
+// [run.WithInput], [run.WithCombinedOutput], or [run.WithDemuxedOutput]. Use
+// [Container.Attach] instead to attach to a reused container.
+func (s *Session) Run(ctx context.Context, imageref string, opts ...run.Opt) (*Container, error) {
+	return s.run(ctx, imageref, caller(1, 0), opts...)
+}
+
+// run creates and starts a new container, attributing it to the specified
+// runner.
+func (s *Session) run(ctx context.Context, imageref string, runner string, opts ...run.Opt) (cntr *Container, err error) {
 	// Our interpretation of a "container run" command differs in some aspect
 	// from Docker's CLI "run" command implementation, see also here:
 	// https://github.com/docker/cli/blob/f18a476b6d240bafbaefb65e51f837eab9e02b94/cli/command/container/run.go#L121
@@ -59,30 +83,83 @@ func (s *Session) Run(ctx context.Context, imageref string, opts ...run.Opt) (cn
 		},
 	}
 	maps.Copy(copts.Opts.Config.Labels, s.opts.Labels) // inherit labels from session.
-	copts.Opts.Config.Labels[ContainerRunnerLabelName] = caller(1, 0)
+	copts.Opts.Config.Labels[ContainerRunnerLabelName] = runner
 	for _, opt := range opts {
 		if err := opt(&copts); err != nil {
 			return nil, err
 		}
 	}
+	if err := copts.ApplyMACAddress(); err != nil {
+		return nil, err
+	}
+	if s.opts.DockerCommandLabel {
+		ensure.Value(&copts.Opts.Config)
+		ensure.Map(&copts.Opts.Config.Labels)
+		copts.Opts.Config.Labels[ContainerDockerCommandLabelName] = dockerCommand(imageref, copts)
+	}
+
+	ctx, op := s.begin(ctx, "container.run",
+		slog.String(AttrImage, imageref),
+		slog.String(AttrContainerName, copts.Opts.Name),
+		slog.String(AttrCaller, runner))
+	defer func() { op.end(err) }()
 
 	if copts.Out == nil {
 		copts.Out = io.Discard
 	}
 
-	// Pull the referenced image if it isn't already available locally.
-	hasimg, err := s.HasImage(ctx, imageref)
-	if err != nil {
-		return nil, fmt.Errorf("cannot run image %s, reason: %w", imageref, err)
+	// Pull the referenced image according to the pull policy, by default only
+	// if it isn't already available locally.
+	pullimg := copts.PullPolicy == run.PullAlways
+	if !pullimg {
+		hasimg, err := s.HasImage(ctx, imageref)
+		if err != nil {
+			return nil, fmt.Errorf("cannot run image %s, reason: %w", imageref, err)
+		}
+		if !hasimg && copts.PullPolicy == run.PullNever {
+			return nil, fmt.Errorf("cannot run image %s, reason: %w", imageref,
+				errdefs.ErrNotFound.WithMessage("image not available locally and pull policy is never"))
+		}
+		pullimg = !hasimg
 	}
-	if !hasimg {
-		if err := s.PullImage(ctx, imageref); err != nil {
-			return nil, fmt.Errorf("cannot pull image, reason: %w", err)
+	if pullimg {
+		if _, err := s.pullImage(ctx, imageref, runner, copts.PullOpts...); err != nil {
+			return nil, fmt.Errorf("cannot run image %s, reason: %w", imageref, err)
+		}
+	}
+
+	// For a reusable container, return an already running container created
+	// with the same effective options. As reused containers outlive test
+	// sessions, we don't attach any input and output streams to them.
+	var reusehash string
+	if copts.ReuseKey != "" {
+		reusehash, err = s.prepareReuse(ctx, imageref, &copts)
+		if err != nil {
+			return nil, err
+		}
+		reused, err := s.reusable(ctx, reusehash)
+		if err != nil {
+			return nil, err
+		}
+		if reused != nil {
+			op.set(slog.String(AttrContainerID, reused.ID), slog.Bool(AttrReused, true))
+			return reused, nil
 		}
 	}
 
 	// Create the container; this doesn't start it yet.
 	createResp, err := s.moby.ContainerCreate(ctx, copts.Opts)
+	if err != nil && copts.ReuseKey != "" {
+		// A reusable container might squat on the name of the reusable
+		// container we're about to create: either it can be reused, or it is
+		// stale and has been removed, so we try again.
+		var reused *Container
+		reused, createResp, err = s.createOverReusable(ctx, &copts, reusehash, err)
+		if reused != nil {
+			op.set(slog.String(AttrContainerID, reused.ID), slog.Bool(AttrReused, true))
+			return reused, nil
+		}
+	}
 	if err != nil {
 		creationerr := err
 		if !errdefs.IsConflict(creationerr) || copts.Opts.Name == "" {
@@ -99,6 +176,7 @@ func (s *Session) Run(ctx context.Context, imageref string, opts ...run.Opt) (cn
 		return nil, fmt.Errorf("cannot create container: name already taken by %s", squatter)
 	}
 	cntrID := createResp.ID
+	op.set(slog.String(AttrContainerID, cntrID))
 
 	// Whatever happens next, whenever on our way out of this method we see that
 	// there's an error returned, then take the newly created container down
@@ -129,37 +207,9 @@ func (s *Session) Run(ctx context.Context, imageref string, opts ...run.Opt) (cn
 		return nil, fmt.Errorf("cannot attach to container, reason: %w", err)
 	}
 
-	done := make(chan struct{})
-	// Deal with the output stream...
-	go func() {
-		defer func() {
-			<-done
-			attachResp.Close()
-		}()
-
-		if copts.Opts.Config.Tty {
-			// When using a TTY, only copy the single combined output stream
-			// into the output stream specified in the run options.
-			_, _ = io.Copy(copts.Out, attachResp.Reader)
-			return
-		}
-		// When NOT using a TTY, use Docker's own helper to demux the two
-		// multiplexed streams into stdout and stderr writers.
-		stderr := copts.Err
-		if stderr == nil {
-			stderr = io.Discard
-		}
-		_, _ = stdcopy.StdCopy(copts.Out, stderr, attachResp.Reader)
-	}()
-	// Deal with the input stream, where necessary.
-	if copts.In != nil {
-		go func() {
-			defer close(done)
-			_, _ = io.Copy(attachResp.Conn, copts.In)
-		}()
-	} else {
-		close(done)
-	}
+	// Deal with the input and output streams in the background; the container
+	// keeps them until it terminates, so we don't track their completion.
+	_ = attachStreams(attachResp.HijackedResponse, copts.Opts.Config.Tty, copts.In, copts.Out, copts.Err)
 
 	// Finally, we can try to start the container.
 	if _, err := s.moby.ContainerStart(ctx, cntrID, client.ContainerStartOptions{}); err != nil {
@@ -177,3 +227,15 @@ func (s *Session) Run(ctx context.Context, imageref string, opts ...run.Opt) (cn
 	}
 	return cntr, nil
 }
+
+// dockerCommand returns the “docker run” command line equivalent to the
+// specified options, but without any morbyd-specific labels.
+func dockerCommand(imageref string, copts run.Options) string {
+	config := *copts.Opts.Config
+	config.Labels = maps.Clone(config.Labels)
+	maps.DeleteFunc(config.Labels, func(key, _ string) bool {
+		return strings.HasPrefix(key, MorbydLabelNamespace)
+	})
+	copts.Opts.Config = &config
+	return copts.DockerCommand(imageref)
+}
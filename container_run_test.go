@@ -26,6 +26,7 @@ import (
 	"github.com/thediveo/safe"
 	mock "go.uber.org/mock/gomock"
 
+	"github.com/thediveo/morbyd/v2/fakeengine"
 	"github.com/thediveo/morbyd/v2/internal/ensure"
 	"github.com/thediveo/morbyd/v2/run"
 	"github.com/thediveo/morbyd/v2/session"
@@ -82,7 +83,8 @@ var _ = Describe("run container", Ordered, func() {
 			rec.ImageInspect(Any, Any).Return(client.ImageInspectResult{}, errdefs.ErrNotFound)
 			rec.ImagePull(Any, Any, Any).Return(nil, errors.New("error IJK305I"))
 
-			Expect(sess.Run(ctx, "busybox")).Error().To(MatchError(ContainSubstring("cannot pull image")))
+			Expect(sess.Run(ctx, "busybox")).Error().To(MatchError(
+				"cannot run image busybox, reason: image pull failed, reason: error IJK305I"))
 		})
 
 		It("reports creation failure", func(ctx context.Context) {
@@ -295,3 +297,36 @@ var _ = Describe("run container", Ordered, func() {
 	})
 
 })
+
+var _ = Describe("docker run command labels", func() {
+
+	It("labels containers with their docker run command line", func(ctx context.Context) {
+		engine := fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=container.run.command"),
+			session.WithLabel("foo=bar"),
+			session.WithDockerCommandLabel()))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithName("commandeer"),
+			run.WithEnvVars("GREETING=hello world"),
+			run.WithCommand("/bin/sh", "-c", "echo $GREETING")))
+		Expect(cntr.Details.Container.Config.Labels).To(And(
+			HaveKeyWithValue(ContainerRunnerLabelName, ContainSubstring("container_run_test.go")),
+			HaveKeyWithValue(ContainerDockerCommandLabelName,
+				"docker run --name=commandeer '--env=GREETING=hello world' "+
+					"--label=foo=bar --label=test.morbyd=container.run.command "+
+					"busybox /bin/sh -c 'echo $GREETING'")))
+
+		By("not labelling by default")
+		sess = Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+		cntr = Successful(sess.Run(ctx, "busybox"))
+		Expect(cntr.Details.Container.Config.Labels).NotTo(
+			HaveKey(ContainerDockerCommandLabelName))
+	})
+
+})
@@ -0,0 +1,189 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/thediveo/morbyd/v2/caps"
+)
+
+// SecurityContext describes the effective security context of a container's
+// initial process, as seen by the Linux kernel. In contrast to the container
+// configuration, it tells what actually took effect, such as after dropping
+// capabilities or applying a seccomp profile.
+type SecurityContext struct {
+	Effective   caps.Set    // effective capabilities (CapEff).
+	Permitted   caps.Set    // permitted capabilities (CapPrm).
+	Inheritable caps.Set    // inheritable capabilities (CapInh).
+	Bounding    caps.Set    // capability bounding set (CapBnd).
+	Ambient     caps.Set    // ambient capabilities (CapAmb).
+	NoNewPrivs  bool        // no_new_privs bit set.
+	Seccomp     SeccompMode // seccomp mode.
+	LSMLabel    string      // AppArmor profile or SELinux context, if any.
+	UIDMap      []IDMapping // user ID mappings of the process' user namespace.
+	GIDMap      []IDMapping // group ID mappings of the process' user namespace.
+}
+
+// SeccompMode is the seccomp mode of a process.
+type SeccompMode int
+
+// Seccomp modes as reported in the Seccomp field of /proc/[PID]/status.
+const (
+	SeccompDisabled SeccompMode = iota // no seccomp, such as when unconfined.
+	SeccompStrict                      // strict mode.
+	SeccompFilter                      // filter mode, such as with a profile.
+)
+
+// String returns the name of the seccomp mode.
+func (m SeccompMode) String() string {
+	switch m {
+	case SeccompDisabled:
+		return "disabled"
+	case SeccompStrict:
+		return "strict"
+	case SeccompFilter:
+		return "filter"
+	}
+	return "SeccompMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// IDMapping maps a range of user or group IDs inside a user namespace onto IDs
+// in the parent user namespace.
+type IDMapping struct {
+	ContainerID uint32 // first ID inside the user namespace.
+	HostID      uint32 // first ID in the parent user namespace.
+	Size        uint32 // number of IDs mapped.
+}
+
+// SecurityContext returns the effective security context of the container's
+// initial process, reading /proc/[PID]/status, /proc/[PID]/attr/current, as
+// well as the UID and GID maps, where the PID is obtained using
+// [Container.PID].
+//
+// On Docker Desktop, SecurityContext returns an error wrapping
+// [ErrProcessesNotAccessible], as the container processes are not visible to
+// the host.
+func (c *Container) SecurityContext(ctx context.Context) (*SecurityContext, error) {
+	pid, err := c.hostPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secctx, err := readSecurityContext(os.DirFS("/proc"), pid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine security context of container %s/%q, reason: %w",
+			c.Name, c.AbbreviatedID(), err)
+	}
+	return secctx, nil
+}
+
+// readSecurityContext reads the security context of the process with the
+// specified PID from the procfs.
+func readSecurityContext(procfs fs.FS, pid int) (*SecurityContext, error) {
+	procdir := strconv.Itoa(pid)
+	status, err := fs.ReadFile(procfs, procdir+"/status")
+	if err != nil {
+		return nil, err
+	}
+	secctx := &SecurityContext{}
+	capsets := map[string]*caps.Set{
+		"CapInh": &secctx.Inheritable,
+		"CapPrm": &secctx.Permitted,
+		"CapEff": &secctx.Effective,
+		"CapBnd": &secctx.Bounding,
+		"CapAmb": &secctx.Ambient,
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		field, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch field {
+		case "CapInh", "CapPrm", "CapEff", "CapBnd", "CapAmb":
+			set, err := caps.ParseSet(value)
+			if err != nil {
+				return nil, fmt.Errorf("malformed %s, reason: %w", field, err)
+			}
+			*capsets[field] = set
+		case "NoNewPrivs":
+			secctx.NoNewPrivs = value == "1"
+		case "Seccomp":
+			mode, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("malformed Seccomp mode %q", value)
+			}
+			secctx.Seccomp = SeccompMode(mode)
+		}
+	}
+	// Without any LSM active, the attr/current pseudo file either doesn't
+	// exist or cannot be read, so we then simply report no label.
+	label, err := fs.ReadFile(procfs, procdir+"/attr/current")
+	switch {
+	case err == nil:
+		secctx.LSMLabel = strings.TrimRight(string(label), "\x00\n")
+	case !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, syscall.EINVAL):
+		return nil, err
+	}
+	if secctx.UIDMap, err = readIDMap(procfs, procdir+"/uid_map"); err != nil {
+		return nil, err
+	}
+	if secctx.GIDMap, err = readIDMap(procfs, procdir+"/gid_map"); err != nil {
+		return nil, err
+	}
+	return secctx, nil
+}
+
+// readIDMap reads a UID or GID map from the procfs.
+func readIDMap(procfs fs.FS, name string) ([]IDMapping, error) {
+	idmap, err := fs.ReadFile(procfs, name)
+	if err != nil {
+		return nil, err
+	}
+	var mappings []IDMapping
+	for line := range strings.Lines(string(idmap)) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed ID mapping %q", strings.TrimSpace(line))
+		}
+		var ids [3]uint32
+		for idx, field := range fields {
+			id, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("malformed ID mapping %q", strings.TrimSpace(line))
+			}
+			ids[idx] = uint32(id)
+		}
+		mappings = append(mappings, IDMapping{
+			ContainerID: ids[0],
+			HostID:      ids[1],
+			Size:        ids[2],
+		})
+	}
+	return mappings, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"testing/fstest"
+	"time"
+
+	"github.com/thediveo/morbyd/v2/caps"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+const fakeStatus = `Name:	sleep
+Umask:	0022
+State:	S (sleeping)
+Pid:	42
+CapInh:	0000000000000000
+CapPrm:	0000000000200000
+CapEff:	0000000000200000
+CapBnd:	0000000000202000
+CapAmb:	0000000000000000
+NoNewPrivs:	1
+Seccomp:	2
+Seccomp_filters:	1
+`
+
+var _ = Describe("container security contexts", func() {
+
+	It("reads the security context from procfs", func() {
+		procfs := fstest.MapFS{
+			"42/status":       {Data: []byte(fakeStatus)},
+			"42/attr/current": {Data: []byte("docker-default (enforce)\n")},
+			"42/uid_map":      {Data: []byte("         0     100000      65536\n")},
+			"42/gid_map":      {Data: []byte("         0     100000      65536\n      65536 1000 1\n")},
+		}
+		secctx := Successful(readSecurityContext(procfs, 42))
+		Expect(secctx.Effective).To(Equal(Successful(caps.SetOf("CAP_SYS_ADMIN"))))
+		Expect(secctx.Permitted).To(Equal(secctx.Effective))
+		Expect(secctx.Bounding.Names()).To(ConsistOf("CAP_NET_RAW", "CAP_SYS_ADMIN"))
+		Expect(secctx.Inheritable).To(BeZero())
+		Expect(secctx.Ambient).To(BeZero())
+		Expect(secctx.NoNewPrivs).To(BeTrue())
+		Expect(secctx.Seccomp).To(Equal(SeccompFilter))
+		Expect(secctx.LSMLabel).To(Equal("docker-default (enforce)"))
+		Expect(secctx.UIDMap).To(HaveExactElements(
+			IDMapping{ContainerID: 0, HostID: 100000, Size: 65536}))
+		Expect(secctx.GIDMap).To(HaveExactElements(
+			IDMapping{ContainerID: 0, HostID: 100000, Size: 65536},
+			IDMapping{ContainerID: 65536, HostID: 1000, Size: 1}))
+	})
+
+	It("handles a missing LSM label", func() {
+		procfs := fstest.MapFS{
+			"42/status":  {Data: []byte(fakeStatus)},
+			"42/uid_map": {Data: []byte("0 0 4294967295\n")},
+			"42/gid_map": {Data: []byte("0 0 4294967295\n")},
+		}
+		secctx := Successful(readSecurityContext(procfs, 42))
+		Expect(secctx.LSMLabel).To(BeEmpty())
+		Expect(secctx.UIDMap).To(HaveExactElements(
+			IDMapping{ContainerID: 0, HostID: 0, Size: 4294967295}))
+	})
+
+	DescribeTable("rejecting malformed procfs contents",
+		func(procfs fstest.MapFS, expectedErr string) {
+			Expect(readSecurityContext(procfs, 42)).Error().To(
+				MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("missing process", fstest.MapFS{}, "file does not exist"),
+		Entry("malformed capability set", fstest.MapFS{
+			"42/status": {Data: []byte("CapEff:\tnada\n")},
+		}, "malformed CapEff"),
+		Entry("malformed seccomp mode", fstest.MapFS{
+			"42/status": {Data: []byte("Seccomp:\tfilter\n")},
+		}, `malformed Seccomp mode "filter"`),
+		Entry("missing UID map", fstest.MapFS{
+			"42/status": {Data: []byte(fakeStatus)},
+		}, "file does not exist"),
+		Entry("malformed ID mapping", fstest.MapFS{
+			"42/status":  {Data: []byte(fakeStatus)},
+			"42/uid_map": {Data: []byte("0 0\n")},
+		}, `malformed ID mapping "0 0"`),
+		Entry("malformed ID", fstest.MapFS{
+			"42/status":  {Data: []byte(fakeStatus)},
+			"42/uid_map": {Data: []byte("0 0 -1\n")},
+		}, `malformed ID mapping "0 0 -1"`),
+	)
+
+	It("names seccomp modes", func() {
+		Expect(SeccompDisabled.String()).To(Equal("disabled"))
+		Expect(SeccompStrict.String()).To(Equal("strict"))
+		Expect(SeccompFilter.String()).To(Equal("filter"))
+		Expect(SeccompMode(42).String()).To(Equal("SeccompMode(42)"))
+	})
+
+	It("returns the effective security context of a container", func(ctx context.Context) {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		sess := Successful(NewSession(ctx,
+			session.WithAutoCleaning("test.morbyd=container.security")))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithCommand("/bin/sleep", "120s"),
+			run.WithAutoRemove(),
+			run.WithCapDropAll(),
+			run.WithCapAdd("CAP_NET_RAW"),
+			run.WithNoNewPrivileges()))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+
+		secctx := Successful(cntr.SecurityContext(ctx))
+		Expect(secctx.Effective).To(Equal(Successful(caps.SetOf("NET_RAW"))))
+		Expect(secctx.Bounding).To(Equal(secctx.Effective))
+		Expect(secctx.NoNewPrivs).To(BeTrue())
+		Expect(secctx.Seccomp).To(Equal(SeccompFilter))
+		Expect(secctx.UIDMap).NotTo(BeEmpty())
+	})
+
+})
@@ -29,6 +29,9 @@ At a glance:
   - uses [context.Context] throughout the whole module, especially integrating
     well with testing frameworks (such as [Ginkgo]) that support automatic
     unit test context creation.
+  - structured logging of session operations using [session.WithLogger], and
+    OpenTelemetry spans per session operation using
+    [session.WithTracerProvider].
   - extensive unit tests with large coverage.
 
 # Trivia
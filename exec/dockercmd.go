@@ -0,0 +1,36 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"github.com/thediveo/morbyd/v2/internal/dockercmd"
+)
+
+// DockerCommand returns a copy-pasteable “docker exec” command line that
+// executes the command with the same effective configuration as these options
+// inside the specified container, such as for manually reproducing a failed
+// test.
+func (o *Options) DockerCommand(cntr string) string {
+	cmd := dockercmd.New("docker", "exec")
+	cmd.Bool("interactive", o.In != nil)
+	cmd.Bool("tty", o.Conf.TTY)
+	cmd.Bool("privileged", o.Conf.Privileged)
+	cmd.Flag("user", o.Conf.User)
+	cmd.Flag("workdir", o.Conf.WorkingDir)
+	cmd.Flags("env", o.Conf.Env)
+	cmd.Add(cntr)
+	cmd.Add(o.Conf.Cmd...)
+	return cmd.String()
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("rendering docker exec command lines", func() {
+
+	It("renders the effective options", func() {
+		o := opts(
+			WithInput(strings.NewReader("")),
+			WithTTY(),
+			WithUser("1000"),
+			WithGroup(1000),
+			WithWorkingDir("/tmp"),
+			WithEnvVars("GREETING=hello world"),
+		)
+		o.Conf.Cmd = Command("/bin/sh", "-c", "echo $GREETING")
+		Expect(o.DockerCommand("foo")).To(Equal(
+			"docker exec --interactive --tty --user=1000:1000 --workdir=/tmp " +
+				"'--env=GREETING=hello world' foo /bin/sh -c 'echo $GREETING'"))
+	})
+
+})
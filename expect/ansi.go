@@ -0,0 +1,95 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expect
+
+// StripANSI returns the specified text with all ANSI escape sequences as well
+// as carriage returns removed, such as the color and cursor positioning
+// sequences emitted by interactive programs.
+func StripANSI(text string) string {
+	clean, rest := stripANSI([]byte(text))
+	return string(append(clean, rest...))
+}
+
+const esc = 0x1b
+
+// stripANSI returns the specified bytes with all complete ANSI escape
+// sequences and carriage returns removed, as well as a trailing incomplete
+// escape sequence that needs more input in order to be stripped.
+func stripANSI(b []byte) (clean []byte, rest []byte) {
+	clean = make([]byte, 0, len(b))
+	for idx := 0; idx < len(b); {
+		switch b[idx] {
+		case '\r':
+			idx++
+			continue
+		case esc:
+			// fall through to the escape sequence handling below.
+		default:
+			clean = append(clean, b[idx])
+			idx++
+			continue
+		}
+		end := escapeSequenceEnd(b[idx:])
+		if end < 0 {
+			return clean, b[idx:]
+		}
+		idx += end
+	}
+	return clean, nil
+}
+
+// escapeSequenceEnd returns the length of the escape sequence at the
+// beginning of the specified bytes, or -1 if the escape sequence is still
+// incomplete.
+func escapeSequenceEnd(b []byte) int {
+	if len(b) < 2 {
+		return -1
+	}
+	switch b[1] {
+	case '[':
+		// CSI: parameter bytes 0x30–0x3f, intermediate bytes 0x20–0x2f, then
+		// a final byte 0x40–0x7e.
+		for idx := 2; idx < len(b); idx++ {
+			if b[idx] >= 0x40 && b[idx] <= 0x7e {
+				return idx + 1
+			}
+		}
+		return -1
+	case ']', 'P', '_', '^', 'X':
+		// OSC and other strings: terminated by either BEL or ST (ESC \).
+		for idx := 2; idx < len(b); idx++ {
+			switch {
+			case b[idx] == 0x07:
+				return idx + 1
+			case b[idx] == esc:
+				if idx+1 == len(b) {
+					return -1
+				}
+				if b[idx+1] == '\\' {
+					return idx + 2
+				}
+			}
+		}
+		return -1
+	}
+	// Any other escape sequence: intermediate bytes 0x20–0x2f followed by a
+	// final byte 0x30–0x7e, such as “ESC ( B” or “ESC =”.
+	for idx := 1; idx < len(b); idx++ {
+		if b[idx] < 0x20 || b[idx] > 0x2f {
+			return idx + 1
+		}
+	}
+	return -1
+}
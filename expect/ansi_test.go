@@ -0,0 +1,47 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expect
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ANSI escape sequences", func() {
+
+	DescribeTable("stripping",
+		func(text string, expected string) {
+			Expect(StripANSI(text)).To(Equal(expected))
+		},
+		Entry("plain", "hellorld", "hellorld"),
+		Entry("carriage returns", "foo\r\nbar\r\n", "foo\nbar\n"),
+		Entry("SGR colors", "\x1b[1;32mgreen\x1b[0m", "green"),
+		Entry("cursor positioning", "\x1b[2J\x1b[H\x1b[?25lfoo", "foo"),
+		Entry("OSC window title with BEL", "\x1b]0;title\x07foo", "foo"),
+		Entry("OSC window title with ST", "\x1b]0;title\x1b\\foo", "foo"),
+		Entry("charset designation", "\x1b(Bfoo", "foo"),
+		Entry("keypad mode", "\x1b=foo\x1b>", "foo"),
+		Entry("incomplete sequence", "foo\x1b[1;3", "foo\x1b[1;3"),
+	)
+
+	It("keeps incomplete escape sequences for later", func() {
+		for _, incomplete := range []string{"\x1b", "\x1b[1;3", "\x1b]0;ti", "\x1b]0;t\x1b", "\x1b("} {
+			clean, rest := stripANSI([]byte("foo" + incomplete))
+			Expect(string(clean)).To(Equal("foo"))
+			Expect(string(rest)).To(Equal(incomplete))
+		}
+	})
+
+})
@@ -0,0 +1,331 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thediveo/morbyd/v2"
+	"github.com/thediveo/morbyd/v2/exec"
+	"github.com/thediveo/morbyd/v2/run"
+)
+
+// ErrTimeout signals that the expected output didn't appear in time.
+var ErrTimeout = errors.New("timeout")
+
+// ErrNotAttached signals that a console hasn't been attached yet to a
+// container or command.
+var ErrNotAttached = errors.New("console not attached")
+
+// maxUnmatchedReport is the maximum length of unmatched output included in
+// error messages.
+const maxUnmatchedReport = 256
+
+// Console drives an interactive program inside a container through its pseudo
+// TTY. Create new consoles using [New] and then attach them exactly once
+// using either [Console.Run] or [Console.Exec].
+type Console struct {
+	opts Options
+	inr  *io.PipeReader
+	inw  *io.PipeWriter
+
+	ended   chan struct{} // closed when the output has ended.
+	endOnce sync.Once
+
+	mu         sync.Mutex
+	attached   bool
+	resize     func(ctx context.Context, width, height uint) error
+	done       <-chan struct{} // closed when the command has finished, if known.
+	changed    chan struct{}   // closed and replaced whenever new output arrives.
+	pending    []byte          // incomplete escape sequence to be stripped.
+	unmatched  []byte          // stripped output not yet consumed by Expect.
+	transcript strings.Builder // all stripped output.
+}
+
+// New returns a new console, configured using the specified options.
+func New(opts ...Opt) (*Console, error) {
+	c := &Console{
+		ended:   make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(&c.opts); err != nil {
+			return nil, fmt.Errorf("cannot create console, reason: %w", err)
+		}
+	}
+	c.inr, c.inw = io.Pipe()
+	return c, nil
+}
+
+// Run a new container with a pseudo TTY attached to this console, using the
+// referenced image and the optional run configuration, see also
+// [morbyd.Session.Run]. Run automatically adds the [run.WithTTY],
+// [run.WithInput] and [run.WithCombinedOutput] options.
+func (c *Console) Run(ctx context.Context, sess *morbyd.Session, imageref string, opts ...run.Opt) (*morbyd.Container, error) {
+	if err := c.attach(); err != nil {
+		return nil, err
+	}
+	opts = append(opts, run.WithTTY(), run.WithInput(c.inr), run.WithCombinedOutput(c))
+	if c.opts.Width != 0 && c.opts.Height != 0 {
+		opts = append(opts, run.WithConsoleSize(c.opts.Width, c.opts.Height))
+	}
+	cntr, err := sess.Run(ctx, imageref, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.resize = cntr.Resize
+	c.mu.Unlock()
+	return cntr, nil
+}
+
+// Exec a command inside the specified container with a pseudo TTY attached to
+// this console, see also [morbyd.Container.Exec]. Exec automatically adds the
+// [exec.WithTTY], [exec.WithInput] and [exec.WithCombinedOutput] options.
+func (c *Console) Exec(ctx context.Context, cntr *morbyd.Container, cmd exec.Cmd, opts ...exec.Opt) (*morbyd.ExecSession, error) {
+	if err := c.attach(); err != nil {
+		return nil, err
+	}
+	opts = append(opts, exec.WithTTY(), exec.WithInput(c.inr), exec.WithCombinedOutput(c))
+	if c.opts.Width != 0 && c.opts.Height != 0 {
+		opts = append(opts, exec.WithConsoleSize(c.opts.Width, c.opts.Height))
+	}
+	es, err := cntr.Exec(ctx, cmd, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.resize = es.Resize
+	c.done = es.Done()
+	c.mu.Unlock()
+	return es, nil
+}
+
+// attach marks this console as attached, or returns an error if it already
+// has been attached before.
+func (c *Console) attach() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.attached {
+		return errors.New("console already attached")
+	}
+	c.attached = true
+	return nil
+}
+
+// ReadFrom reads the output of the attached program from r until EOF or an
+// error, see also [io.ReaderFrom]. Afterwards, the console considers the
+// output to have ended: [Console.Expect] then fails with [io.EOF] instead of
+// waiting for its timeout, and [Console.Send] fails instead of blocking.
+func (c *Console) ReadFrom(r io.Reader) (int64, error) {
+	defer c.end()
+	buf := make([]byte, 32*1024)
+	var total int64
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			_, _ = c.Write(buf[:n])
+			total += int64(n)
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// end marks the output of the attached program as ended, and stops feeding
+// further input to it.
+func (c *Console) end() {
+	c.endOnce.Do(func() {
+		close(c.ended)
+		_ = c.inr.CloseWithError(io.EOF)
+	})
+}
+
+// Write the output of the attached program to this console, stripping any
+// ANSI escape sequences.
+func (c *Console) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clean, rest := stripANSI(append(c.pending, p...))
+	c.pending = rest
+	if len(clean) == 0 {
+		return len(p), nil
+	}
+	c.unmatched = append(c.unmatched, clean...)
+	c.transcript.Write(clean)
+	if c.opts.Transcript != nil {
+		_, _ = c.opts.Transcript.Write(clean)
+	}
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return len(p), nil
+}
+
+// Expect waits for output matching the specified regular expression to appear
+// within the specified timeout, returning the match followed by any
+// submatches. Expect matches the output with ANSI escape sequences and
+// carriage returns removed, and consumes the output up to the end of the
+// match, so that subsequent calls only see later output.
+//
+// If the expected output doesn't appear in time, Expect returns an error
+// wrapping [ErrTimeout]; if the output of the attached program has ended
+// instead, the error wraps [io.EOF].
+func (c *Console) Expect(pattern string, timeout time.Duration) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected pattern, reason: %w", err)
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ended := false
+	for {
+		c.mu.Lock()
+		if loc := re.FindSubmatchIndex(c.unmatched); loc != nil {
+			matches := make([]string, len(loc)/2)
+			for idx := range matches {
+				if loc[2*idx] >= 0 {
+					matches[idx] = string(c.unmatched[loc[2*idx]:loc[2*idx+1]])
+				}
+			}
+			c.unmatched = append([]byte(nil), c.unmatched[loc[1]:]...)
+			c.mu.Unlock()
+			return matches, nil
+		}
+		unmatched := c.unmatchedReport()
+		changed := c.changed
+		done := c.done
+		c.mu.Unlock()
+		if ended {
+			return nil, fmt.Errorf("expecting %q failed, unmatched output: %q, reason: %w",
+				pattern, unmatched, io.EOF)
+		}
+		select {
+		case <-changed:
+		case <-c.ended:
+			ended = true
+		case <-done:
+			// check one last time, as the final output might have arrived
+			// just before the output ended.
+			ended = true
+		case <-timer.C:
+			return nil, fmt.Errorf("expecting %q failed after %s, unmatched output: %q, reason: %w",
+				pattern, timeout, unmatched, ErrTimeout)
+		}
+	}
+}
+
+// unmatchedReport returns the tail end of the unmatched output for reporting.
+// unmatchedReport must be called with the console lock held.
+func (c *Console) unmatchedReport() string {
+	if len(c.unmatched) <= maxUnmatchedReport {
+		return string(c.unmatched)
+	}
+	return "…" + string(c.unmatched[len(c.unmatched)-maxUnmatchedReport:])
+}
+
+// Send the specified text as input to the attached program. If the input
+// cannot be sent before the passed context is done, Send gives up and stops
+// feeding any further input to the attached program, as it then doesn't accept
+// input anymore. Send fails with an error wrapping [ErrNotAttached] if the
+// console hasn't been attached yet, and with an error wrapping [io.EOF] after
+// the output of the attached program has ended.
+func (c *Console) Send(ctx context.Context, text string) error {
+	c.mu.Lock()
+	attached := c.resize != nil
+	c.mu.Unlock()
+	if !attached {
+		return fmt.Errorf("cannot send to console, reason: %w", ErrNotAttached)
+	}
+	sent := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(c.inw, text)
+		sent <- err
+	}()
+	var err error
+	select {
+	case err = <-sent:
+	case <-ctx.Done():
+		_ = c.inr.CloseWithError(ctx.Err())
+		<-sent
+		err = ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("cannot send to console, reason: %w", err)
+	}
+	return nil
+}
+
+// SendLine sends the specified text followed by a carriage return, as if the
+// text was typed followed by pressing the Enter key.
+func (c *Console) SendLine(ctx context.Context, text string) error {
+	return c.Send(ctx, text+"\r")
+}
+
+// SendControl sends the control character for the specified key, as if it
+// was pressed together with the Ctrl key, such as 'c' for interrupting, or 'd'
+// for end of file. The key is case-insensitive and must be either a letter or
+// one of “@[\]^_?”.
+func (c *Console) SendControl(ctx context.Context, key rune) error {
+	var ctrl byte
+	switch {
+	case key >= 'a' && key <= 'z':
+		ctrl = byte(key-'a') + 1
+	case key >= '@' && key <= '_':
+		ctrl = byte(key - '@')
+	case key == '?':
+		ctrl = 0x7f
+	default:
+		return fmt.Errorf("invalid control key %q", key)
+	}
+	return c.Send(ctx, string([]byte{ctrl}))
+}
+
+// Resize the pseudo TTY of the attached container or command to the specified
+// width and height.
+func (c *Console) Resize(ctx context.Context, width, height uint) error {
+	c.mu.Lock()
+	resize := c.resize
+	c.mu.Unlock()
+	if resize == nil {
+		return fmt.Errorf("cannot resize console, reason: %w", ErrNotAttached)
+	}
+	return resize(ctx, width, height)
+}
+
+// Transcript returns the complete output of the attached program so far, with
+// any ANSI escape sequences and carriage returns removed.
+func (c *Console) Transcript() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.transcript.String()
+}
+
+// Close the input of the attached program. In case of a container, this
+// closes the container's stdin; in case of an executed command, this also
+// finishes the exec session when the command terminates.
+func (c *Console) Close() error {
+	return c.inw.Close()
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thediveo/morbyd/v2"
+	"github.com/thediveo/morbyd/v2/exec"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+	"github.com/thediveo/safe"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+const prompt = "\x1b[1;32m>\x1b[0m "
+
+// repl simulates an interactive program with a colored prompt, that reports
+// its console size, echoes input lines, and handles ^C and ^D.
+func repl(ctx context.Context, proc *fakeengine.Process) int {
+	exitcode := make(chan int, 1)
+	go func() {
+		_, _ = io.WriteString(proc.Stdout, prompt)
+		var line bytes.Buffer
+		buf := make([]byte, 1)
+		for {
+			if _, err := proc.Stdin.Read(buf); err != nil {
+				exitcode <- 1
+				return
+			}
+			switch buf[0] {
+			case 0x03:
+				line.Reset()
+				_, _ = io.WriteString(proc.Stdout, "^C\r\n"+prompt)
+				continue
+			case 0x04:
+				exitcode <- 0
+				return
+			case '\r', '\n':
+			default:
+				line.WriteByte(buf[0])
+				continue
+			}
+			switch cmd := line.String(); cmd {
+			case "size":
+				width, height := proc.ConsoleSize()
+				_, _ = fmt.Fprintf(proc.Stdout, "%dx%d\r\n", width, height)
+			case "exit":
+				exitcode <- 42
+				return
+			default:
+				_, _ = fmt.Fprintf(proc.Stdout, "you said: \x1b[1m%s\x1b[0m\r\n", cmd)
+			}
+			line.Reset()
+			_, _ = io.WriteString(proc.Stdout, prompt)
+		}
+	}()
+	select {
+	case code := <-exitcode:
+		return code
+	case <-ctx.Done():
+		return 128 + 15
+	}
+}
+
+var _ = Describe("expect consoles", func() {
+
+	var engine *fakeengine.Engine
+	var sess *morbyd.Session
+
+	BeforeEach(func(ctx context.Context) {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		engine = fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		engine.Handle("repl", repl)
+		sess = Successful(morbyd.NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=expect")))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+	})
+
+	It("drives an interactive container", func(ctx context.Context) {
+		var transcript safe.Buffer
+		con := Successful(New(WithTranscript(&transcript), WithConsoleSize(80, 24)))
+		DeferCleanup(con.Close)
+		Expect(con.Resize(ctx, 1, 1)).To(MatchError(ErrNotAttached))
+		Expect(con.SendLine(ctx, "too early")).To(MatchError(ErrNotAttached))
+
+		cntr := Successful(con.Run(ctx, sess, "busybox", run.WithCommand("repl")))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+		Expect(con.Run(ctx, sess, "busybox")).Error().To(MatchError("console already attached"))
+
+		Expect(con.Expect(`> $`, 2*time.Second)).To(HaveExactElements("> "))
+		Expect(con.SendLine(ctx, "hellorld")).To(Succeed())
+		Expect(con.Expect(`you said: (\w+)`, 2*time.Second)).To(
+			HaveExactElements("you said: hellorld", "hellorld"))
+
+		Expect(con.SendLine(ctx, "size")).To(Succeed())
+		Expect(con.Expect(`(\d+)x(\d+)`, 2*time.Second)).To(HaveExactElements("80x24", "80", "24"))
+		Expect(con.Resize(ctx, 132, 43)).To(Succeed())
+		Expect(con.SendLine(ctx, "size")).To(Succeed())
+		Expect(con.Expect(`132x43`, 2*time.Second)).Error().NotTo(HaveOccurred())
+
+		Expect(con.Send(ctx, "oops")).To(Succeed())
+		Expect(con.SendControl(ctx, 'c')).To(Succeed())
+		Expect(con.Expect(`\^C`, 2*time.Second)).Error().NotTo(HaveOccurred())
+
+		Expect(con.Expect(`nada`, 100*time.Millisecond)).Error().To(And(
+			MatchError(ErrTimeout),
+			MatchError(ContainSubstring(`unmatched output: "\n> "`))))
+
+		Expect(con.Transcript()).To(Equal(
+			"> you said: hellorld\n> 80x24\n> 132x43\n> ^C\n> "))
+		Expect(transcript.String()).To(Equal(con.Transcript()))
+	})
+
+	It("drives an interactive command", func(ctx context.Context) {
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+
+		con := Successful(New(WithConsoleSize(40, 12)))
+		es := Successful(con.Exec(ctx, cntr, exec.Command("repl")))
+		Expect(con.Exec(ctx, cntr, exec.Command("repl"))).Error().To(
+			MatchError("console already attached"))
+
+		Expect(con.Expect(`> $`, 2*time.Second)).Error().NotTo(HaveOccurred())
+		Expect(con.SendLine(ctx, "size")).To(Succeed())
+		Expect(con.Expect(`40x12`, 2*time.Second)).Error().NotTo(HaveOccurred())
+		Expect(con.Resize(ctx, 42, 13)).To(Succeed())
+		Expect(con.SendLine(ctx, "size")).To(Succeed())
+		Expect(con.Expect(`42x13`, 2*time.Second)).Error().NotTo(HaveOccurred())
+
+		Expect(con.SendLine(ctx, "exit")).To(Succeed())
+		Expect(con.Close()).To(Succeed())
+		Expect(con.Expect(`nada`, 2*time.Second)).Error().To(MatchError(io.EOF))
+		Expect(es.Wait(ctx)).To(Equal(42))
+		Expect(con.Send(ctx, "foo")).To(MatchError(ContainSubstring("cannot send to console")))
+	})
+
+	It("fails sending after the output has ended", func(ctx context.Context) {
+		con := Successful(New())
+		DeferCleanup(con.Close)
+		cntr := Successful(con.Run(ctx, sess, "busybox", run.WithCommand("repl")))
+		DeferCleanup(func(ctx context.Context) { cntr.Kill(ctx) })
+
+		Expect(con.Expect(`> $`, 2*time.Second)).Error().NotTo(HaveOccurred())
+		Expect(con.SendLine(ctx, "exit")).To(Succeed())
+		Expect(con.Expect(`nada`, 2*time.Second)).Error().To(MatchError(io.EOF))
+		Expect(con.SendLine(ctx, "hellorld")).To(MatchError(io.EOF))
+	})
+
+	It("gives up sending when the context is done", func(ctx context.Context) {
+		con := Successful(New())
+		con.resize = func(context.Context, uint, uint) error { return nil }
+		sendctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		Expect(con.Send(sendctx, "nobody reads")).To(MatchError(context.DeadlineExceeded))
+		Expect(con.Send(ctx, "still nobody reads")).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("rejects invalid input", func(ctx context.Context) {
+		con := Successful(New())
+		Expect(con.Expect(`(`, time.Second)).Error().To(
+			MatchError(ContainSubstring("invalid expected pattern")))
+		Expect(con.SendControl(ctx, '1')).To(MatchError(`invalid control key '1'`))
+	})
+
+	It("sends control characters", func(ctx context.Context) {
+		con := Successful(New())
+		con.resize = func(context.Context, uint, uint) error { return nil }
+		var got safe.Buffer
+		go func() { _, _ = io.Copy(&got, con.inr) }()
+		for _, key := range "cCd@[?" {
+			Expect(con.SendControl(ctx, key)).To(Succeed())
+		}
+		Expect(con.Close()).To(Succeed())
+		Eventually(got.String).Should(Equal("\x03\x03\x04\x00\x1b\x7f"))
+	})
+
+	It("strips escape sequences split across writes", func() {
+		con := Successful(New())
+		for _, chunk := range []string{"foo\x1b", "[1;3", "2mbar\x1b[0", "m\r", "\n"} {
+			_ = Successful(con.Write([]byte(chunk)))
+		}
+		Expect(con.Transcript()).To(Equal("foobar\n"))
+		Expect(strings.Count(con.Transcript(), "\x1b")).To(BeZero())
+	})
+
+})
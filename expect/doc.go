@@ -0,0 +1,20 @@
+/*
+Package expect provides expect-style scripting of interactive programs running
+inside containers, such as REPLs or installers prompting for input.
+
+A [Console] attaches to the pseudo TTY of either a new container using
+[Console.Run] or a command executed inside a container using [Console.Exec].
+Tests then wait for expected output using [Console.Expect] and reply using
+[Console.Send], [Console.SendLine] and [Console.SendControl]. The output is
+matched with any ANSI escape sequences stripped, and gets recorded in a
+transcript for post-mortem analysis of failing tests.
+
+	con, _ := expect.New(expect.WithTranscript(GinkgoWriter))
+	defer con.Close()
+	cntr, _ := con.Run(ctx, sess, "busybox", run.WithCommand("/bin/sh"))
+	defer cntr.Kill(ctx)
+	_, _ = con.Expect(`# $`, 5*time.Second)
+	_ = con.SendLine(ctx, "echo $((6*7))")
+	_, _ = con.Expect(`42`, 5*time.Second)
+*/
+package expect
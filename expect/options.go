@@ -0,0 +1,47 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expect
+
+import "io"
+
+// Opt is a configuration option for a new [Console] created using [New].
+type Opt func(*Options) error
+
+// Options represents the configuration options of a [Console].
+type Options struct {
+	Transcript io.Writer // optional writer receiving the transcript.
+	Width      uint      // initial console width, if non-zero.
+	Height     uint      // initial console height, if non-zero.
+}
+
+// WithTranscript additionally writes the transcript of the console output,
+// with any ANSI escape sequences stripped, to the specified io.Writer as it
+// happens, such as GinkgoWriter.
+func WithTranscript(w io.Writer) Opt {
+	return func(o *Options) error {
+		o.Transcript = w
+		return nil
+	}
+}
+
+// WithConsoleSize sets the initial width and height of the console's pseudo
+// TTY; see also [Console.Resize].
+func WithConsoleSize(width, height uint) Opt {
+	return func(o *Options) error {
+		o.Width = width
+		o.Height = height
+		return nil
+	}
+}
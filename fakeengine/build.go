@@ -0,0 +1,212 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"strings"
+
+	"github.com/moby/moby/api/types/jsonstream"
+	"github.com/moby/moby/client"
+)
+
+// ImageBuild “builds” an image from the Dockerfile found in the tar'ed build
+// context. As the fake engine cannot run any build steps, it only interprets
+// the FROM, CMD, ENTRYPOINT, ENV, EXPOSE, LABEL, USER, and WORKDIR
+// instructions in order to derive the configuration of the built image; all
+// other instructions are simply skipped. The base image must be either locally
+// available, pullable, or “scratch”.
+//
+// The build output is a classic (v1) builder JSON message stream, with the
+// image ID reported in an aux message.
+func (e *Engine) ImageBuild(ctx context.Context, buildContext io.Reader, options client.ImageBuildOptions) (client.ImageBuildResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImageBuild"); err != nil {
+		return client.ImageBuildResult{}, err
+	}
+	dockerfileName := options.Dockerfile
+	if dockerfileName == "" {
+		dockerfileName = "Dockerfile"
+	}
+	dockerfile, err := readFromTar(buildContext, dockerfileName)
+	if buildContext != nil {
+		// As with a real Docker API client, the whole build context gets
+		// consumed.
+		_, _ = io.Copy(io.Discard, buildContext)
+	}
+	if err != nil {
+		return client.ImageBuildResult{}, err
+	}
+	var lines []string
+	for line := range strings.SplitSeq(dockerfile, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	msgs := []jsonstream.Message{}
+	img := Image{Labels: map[string]string{}}
+	stages := map[string]Image{}
+	stageName := ""
+	for idx, line := range lines {
+		msgs = append(msgs, jsonstream.Message{
+			Stream: fmt.Sprintf("Step %d/%d : %s\n", idx+1, len(lines), line)})
+		instr, args, _ := strings.Cut(line, " ")
+		args = strings.TrimSpace(args)
+		switch strings.ToUpper(instr) {
+		case "FROM":
+			if stageName != "" {
+				stages[stageName] = img
+			}
+			stageName = ""
+			fields := strings.Fields(args)
+			base := Image{Labels: map[string]string{}}
+			if len(fields) > 0 && fields[0] != "scratch" {
+				if stage, ok := stages[fields[0]]; ok {
+					base = stage
+				} else if parent, err := e.baseImage(fields[0]); err == nil {
+					base = parent.config
+				} else {
+					return buildFailed(msgs, err.Error()), nil
+				}
+			}
+			img = base
+			img.Ref = ""
+			img.Labels = maps.Clone(base.Labels)
+			if img.Labels == nil {
+				img.Labels = map[string]string{}
+			}
+			if len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+				stageName = fields[2]
+			}
+		case "CMD":
+			img.Cmd = commandArgs(args)
+		case "ENTRYPOINT":
+			img.Entrypoint = commandArgs(args)
+		case "ENV":
+			key, value, ok := strings.Cut(args, "=")
+			if !ok {
+				key, value, _ = strings.Cut(args, " ")
+			}
+			img.Env = append(img.Env, key+"="+strings.Trim(strings.TrimSpace(value), `"`))
+		case "EXPOSE":
+			for _, port := range strings.Fields(args) {
+				if !strings.Contains(port, "/") {
+					port += "/tcp"
+				}
+				img.ExposedPorts = append(img.ExposedPorts, port)
+			}
+		case "LABEL":
+			key, value, _ := strings.Cut(args, "=")
+			img.Labels[strings.Trim(key, `"`)] = strings.Trim(value, `"`)
+		case "USER":
+			img.User = args
+		case "WORKDIR":
+			img.WorkingDir = args
+		}
+	}
+	maps.Copy(img.Labels, options.Labels)
+	built := e.addImage(img, lines)
+	for _, tag := range options.Tags {
+		ref, err := normalizeRef(tag)
+		if err != nil {
+			return buildFailed(msgs, err.Error()), nil
+		}
+		e.untag(ref)
+		built.tags = append(built.tags, ref)
+	}
+	aux := json.RawMessage(fmt.Sprintf(`{"ID":%q}`, built.id))
+	msgs = append(msgs,
+		jsonstream.Message{Aux: &aux},
+		jsonstream.Message{Stream: fmt.Sprintf("Successfully built %s\n", built.id[len("sha256:"):len("sha256:")+12])})
+	for _, tag := range built.tags {
+		msgs = append(msgs, jsonstream.Message{Stream: fmt.Sprintf("Successfully tagged %s\n", tag)})
+	}
+	return client.ImageBuildResult{Body: messageStream(msgs)}, nil
+}
+
+// baseImage returns the locally available base image, pulling it first from
+// the fake registry if necessary. baseImage must be called with the engine lock
+// held.
+func (e *Engine) baseImage(imgref string) (*fakeImage, error) {
+	if img := e.image(imgref); img != nil {
+		return img, nil
+	}
+	ref, err := normalizeRef(imgref)
+	if err != nil {
+		return nil, err
+	}
+	remote, ok := e.remotes[ref]
+	if !ok {
+		return nil, fmt.Errorf("pull access denied for %s, repository does not exist or may require 'docker login'",
+			imgref)
+	}
+	img := e.addImage(remote, nil)
+	img.tags = []string{ref}
+	return img, nil
+}
+
+// buildFailed returns a build result with the specified messages, followed by
+// an error message.
+func buildFailed(msgs []jsonstream.Message, errmsg string) client.ImageBuildResult {
+	msgs = append(msgs, jsonstream.Message{Error: &jsonstream.Error{Message: errmsg}})
+	return client.ImageBuildResult{Body: messageStream(msgs)}
+}
+
+// commandArgs returns the arguments of a CMD or ENTRYPOINT instruction, in
+// either exec or shell form.
+func commandArgs(args string) []string {
+	var execForm []string
+	if err := json.Unmarshal([]byte(args), &execForm); err == nil {
+		return execForm
+	}
+	return []string{"/bin/sh", "-c", args}
+}
+
+// readFromTar returns the contents of the named file from the tar stream.
+func readFromTar(r io.Reader, name string) (string, error) {
+	if r == nil {
+		return "", invalid("missing build context")
+	}
+	name = path.Clean(name)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", invalid("Cannot locate specified Dockerfile: %s", name)
+			}
+			return "", invalid("invalid build context: %s", err.Error())
+		}
+		if path.Clean(hdr.Name) != name {
+			continue
+		}
+		contents, err := io.ReadAll(tr)
+		if err != nil {
+			return "", invalid("invalid build context: %s", err.Error())
+		}
+		return string(contents), nil
+	}
+}
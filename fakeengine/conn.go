@@ -0,0 +1,200 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+)
+
+// pipeConn is one end of an in-memory, full-duplex connection that in contrast
+// to [net.Pipe] supports half-closing via CloseWrite, as hijacked Docker API
+// connections do.
+type pipeConn struct {
+	r     *io.PipeReader
+	w     *io.PipeWriter
+	close func() error
+}
+
+var (
+	_ net.Conn           = (*pipeConn)(nil)
+	_ client.CloseWriter = (*pipeConn)(nil)
+)
+
+// newPipeConns returns the client and server ends of a new in-memory
+// connection.
+func newPipeConns() (cln *pipeConn, srv *pipeConn) {
+	c2sR, c2sW := io.Pipe()
+	s2cR, s2cW := io.Pipe()
+	cln = &pipeConn{r: s2cR, w: c2sW}
+	srv = &pipeConn{r: c2sR, w: s2cW}
+	cln.close = sync.OnceValue(func() error {
+		_ = cln.r.Close()
+		return cln.w.Close()
+	})
+	srv.close = sync.OnceValue(func() error {
+		_ = srv.r.Close()
+		return srv.w.Close()
+	})
+	return
+}
+
+func (c *pipeConn) Read(b []byte) (int, error)  { return c.r.Read(b) }
+func (c *pipeConn) Write(b []byte) (int, error) { return c.w.Write(b) }
+func (c *pipeConn) Close() error                { return c.close() }
+func (c *pipeConn) CloseWrite() error           { return c.w.Close() }
+
+func (c *pipeConn) LocalAddr() net.Addr                { return fakeAddr{} }
+func (c *pipeConn) RemoteAddr() net.Addr               { return fakeAddr{} }
+func (c *pipeConn) SetDeadline(t time.Time) error      { return nil }
+func (c *pipeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *pipeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type fakeAddr struct{}
+
+func (fakeAddr) Network() string { return "fake" }
+func (fakeAddr) String() string  { return "fake-engine" }
+
+// streams fans out the output of a simulated process to all attached
+// connections, either multiplexed as Docker does when not using a TTY, or raw
+// when using a TTY.
+type streams struct {
+	mu    sync.Mutex
+	tty   bool
+	conns []*attachment
+	log   []logEntry // all output so far, for serving logs.
+}
+
+// logEntry is a single chunk of output, ready for sending to a client.
+type logEntry struct {
+	stream stdcopy.StdType
+	frame  []byte
+}
+
+// attachment is a connection attached to the process of a container or an
+// executed command.
+type attachment struct {
+	conn   *pipeConn // server end.
+	stdout bool
+	stderr bool
+}
+
+// attach a new connection, returning the client end of the connection.
+func (s *streams) attach(stdout, stderr bool) (cln *pipeConn, srv *pipeConn) {
+	cln, srv = newPipeConns()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conns = append(s.conns, &attachment{conn: srv, stdout: stdout, stderr: stderr})
+	return cln, srv
+}
+
+// write the data to all attached connections that requested the specified
+// stream, dropping any connections that fail.
+func (s *streams) write(stream stdcopy.StdType, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var frame []byte
+	if s.tty {
+		frame = data
+	} else {
+		frame = make([]byte, 8+len(data))
+		frame[0] = byte(stream)
+		binary.BigEndian.PutUint32(frame[4:], uint32(len(data)))
+		copy(frame[8:], data)
+	}
+	s.log = append(s.log, logEntry{stream: stream, frame: frame})
+	conns := s.conns[:0]
+	for _, att := range s.conns {
+		if (stream == stdcopy.Stdout && !att.stdout) || (stream == stdcopy.Stderr && !att.stderr) {
+			conns = append(conns, att)
+			continue
+		}
+		if _, err := att.conn.Write(frame); err != nil {
+			_ = att.conn.Close()
+			continue
+		}
+		conns = append(conns, att)
+	}
+	s.conns = conns
+}
+
+// attachReplaying attaches a new connection similar to [streams.attach], but
+// first replays the output so far. If stream is false, the connection gets
+// closed after replaying instead of receiving any further output.
+func (s *streams) attachReplaying(stdout, stderr bool, stream bool) (cln *pipeConn, srv *pipeConn) {
+	cln, srv = newPipeConns()
+	go func() {
+		// Holding the lock while replaying ensures that we neither lose nor
+		// duplicate any output happening in the meantime.
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, err := srv.Write(s.logsLocked(stdout, stderr)); err != nil || !stream {
+			_ = srv.Close()
+			return
+		}
+		s.conns = append(s.conns, &attachment{conn: srv, stdout: stdout, stderr: stderr})
+	}()
+	return cln, srv
+}
+
+// logs returns the output so far of the specified streams.
+func (s *streams) logs(stdout, stderr bool) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logsLocked(stdout, stderr)
+}
+
+// logsLocked returns the output so far of the specified streams.
+// logsLocked must be called with the streams lock held.
+func (s *streams) logsLocked(stdout, stderr bool) []byte {
+	var logs []byte
+	for _, entry := range s.log {
+		if (entry.stream == stdcopy.Stdout && !stdout) || (entry.stream == stdcopy.Stderr && !stderr) {
+			continue
+		}
+		logs = append(logs, entry.frame...)
+	}
+	return logs
+}
+
+// closeAll closes all attached connections, signalling EOF to the clients.
+func (s *streams) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, att := range s.conns {
+		_ = att.conn.Close()
+	}
+	s.conns = nil
+}
+
+// streamWriter writes to a particular output stream of a process.
+type streamWriter struct {
+	streams *streams
+	stream  stdcopy.StdType
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.streams.write(w.stream, append([]byte(nil), p...))
+	return len(p), nil
+}
@@ -0,0 +1,856 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/client"
+	"github.com/moby/term"
+)
+
+// DefaultStopTimeout is the default time a fake engine waits for a stopped
+// container's program to terminate.
+const DefaultStopTimeout = 10 * time.Second
+
+// validContainerName matches Docker's notion of valid container names.
+var validContainerName = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// fakeContainer is a container known to the fake engine.
+type fakeContainer struct {
+	id         string
+	name       string
+	imageID    string
+	config     *container.Config
+	hostConfig *container.HostConfig
+	args       []string
+	created    time.Time
+
+	state      container.ContainerState
+	pid        int
+	exitCode   int
+	startedAt  time.Time
+	finishedAt time.Time
+	restarts   int
+	removing   bool
+	killed     bool
+
+	networks map[string]*network.EndpointSettings // by network name.
+	ports    network.PortMap
+	execIDs  []string
+
+	consoleSize client.ConsoleSize
+
+	streams *streams
+	stdinR  *io.PipeReader
+	stdinW  *io.PipeWriter
+	ctx     context.Context // of the current incarnation.
+	cancel  context.CancelFunc
+	exited  chan struct{} // closed when the current (or next) incarnation exits.
+	removed chan struct{} // closed when the container has been removed.
+}
+
+// container returns the container with the specified name, ID, or unique ID
+// prefix, or nil. container must be called with the engine lock held.
+func (e *Engine) container(nameID string) *fakeContainer {
+	cntr, ok := resolve(e.containers, strings.TrimPrefix(nameID, "/"),
+		func(c *fakeContainer) []string { return []string{c.name} })
+	if !ok {
+		return nil
+	}
+	return cntr
+}
+
+func noSuchContainer(nameID string) error {
+	return notFound("No such container: %s", nameID)
+}
+
+// ContainerCreate creates a new container from a locally available image.
+func (e *Engine) ContainerCreate(ctx context.Context, options client.ContainerCreateOptions) (client.ContainerCreateResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerCreate"); err != nil {
+		return client.ContainerCreateResult{}, err
+	}
+	config := &container.Config{}
+	if options.Config != nil {
+		config = copyConfig(options.Config)
+	}
+	if options.Image != "" {
+		config.Image = options.Image
+	}
+	if config.Image == "" {
+		return client.ContainerCreateResult{}, invalid("no image specified")
+	}
+	img := e.image(config.Image)
+	if img == nil {
+		return client.ContainerCreateResult{}, notFound("No such image: %s", config.Image)
+	}
+
+	name := strings.TrimPrefix(options.Name, "/")
+	if name != "" {
+		if !validContainerName.MatchString(name) {
+			return client.ContainerCreateResult{}, invalid(
+				"Invalid container name (%s), only [a-zA-Z0-9][a-zA-Z0-9_.-] are allowed", name)
+		}
+		for _, other := range e.containers {
+			if other.name == name {
+				return client.ContainerCreateResult{}, conflict(
+					"Conflict. The container name \"/%s\" is already in use by container \"%s\". "+
+						"You have to remove (or rename) that container to be able to reuse that name.",
+					name, other.id)
+			}
+		}
+	}
+
+	// Merge the image defaults into the container configuration, as Docker
+	// does.
+	if len(config.Entrypoint) == 0 {
+		config.Entrypoint = slices.Clone(img.config.Entrypoint)
+		if len(config.Cmd) == 0 {
+			config.Cmd = slices.Clone(img.config.Cmd)
+		}
+	}
+	args := append(slices.Clone(config.Entrypoint), config.Cmd...)
+	if len(args) == 0 {
+		return client.ContainerCreateResult{}, invalid("no command specified")
+	}
+	config.Env = append(slices.Clone(img.config.Env), config.Env...)
+	labels := maps.Clone(img.config.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, config.Labels)
+	config.Labels = labels
+	if config.User == "" {
+		config.User = img.config.User
+	}
+	if config.WorkingDir == "" {
+		config.WorkingDir = img.config.WorkingDir
+	}
+
+	hostConfig := &container.HostConfig{}
+	if options.HostConfig != nil {
+		hc := *options.HostConfig
+		hostConfig = &hc
+	}
+
+	id := newID()
+	if name == "" {
+		name = "fake_" + id[:12]
+	}
+	cntr := &fakeContainer{
+		id:         id,
+		name:       name,
+		imageID:    img.id,
+		config:     config,
+		hostConfig: hostConfig,
+		args:       args,
+		created:    time.Now(),
+		state:      container.StateCreated,
+		networks:   map[string]*network.EndpointSettings{},
+		ports:      network.PortMap{},
+		streams:    &streams{tty: config.Tty},
+		exited:     make(chan struct{}),
+		removed:    make(chan struct{}),
+	}
+	cntr.stdinR, cntr.stdinW = io.Pipe()
+
+	if err := e.connectContainer(cntr, options.NetworkingConfig); err != nil {
+		return client.ContainerCreateResult{}, err
+	}
+	for port, bindings := range hostConfig.PortBindings {
+		for _, binding := range bindings {
+			if binding.HostPort == "" || binding.HostPort == "0" {
+				binding.HostPort = strconv.FormatUint(uint64(e.nextPort), 10)
+				e.nextPort++
+			}
+			if !binding.HostIP.IsValid() {
+				binding.HostIP = netip.IPv4Unspecified()
+			}
+			cntr.ports[port] = append(cntr.ports[port], binding)
+		}
+	}
+
+	e.containers[id] = cntr
+	return client.ContainerCreateResult{ID: id}, nil
+}
+
+// connectContainer connects a newly created container to the networks
+// specified by its network mode and endpoint configurations.
+func (e *Engine) connectContainer(cntr *fakeContainer, netconfig *network.NetworkingConfig) error {
+	var endpoints map[string]*network.EndpointSettings
+	if netconfig != nil {
+		endpoints = netconfig.EndpointsConfig
+	}
+	mode := string(cntr.hostConfig.NetworkMode)
+	switch {
+	case strings.HasPrefix(mode, "container:"):
+		return nil
+	case mode == "" || mode == "default":
+		if len(endpoints) == 0 {
+			mode = "bridge"
+		} else {
+			mode = ""
+		}
+	}
+	if mode != "" {
+		netw := e.network(mode)
+		if netw == nil {
+			return notFound("network %s not found", mode)
+		}
+		e.connect(cntr, netw, endpoints[mode])
+	}
+	for nameID, ep := range endpoints {
+		if nameID == mode {
+			continue
+		}
+		netw := e.network(nameID)
+		if netw == nil {
+			return notFound("network %s not found", nameID)
+		}
+		e.connect(cntr, netw, ep)
+	}
+	return nil
+}
+
+// copyConfig returns a copy of the specified container configuration that
+// doesn't share any slices and maps with the original.
+func copyConfig(config *container.Config) *container.Config {
+	c := *config
+	c.Cmd = slices.Clone(config.Cmd)
+	c.Entrypoint = slices.Clone(config.Entrypoint)
+	c.Env = slices.Clone(config.Env)
+	c.Labels = maps.Clone(config.Labels)
+	c.ExposedPorts = maps.Clone(config.ExposedPorts)
+	c.Volumes = maps.Clone(config.Volumes)
+	return &c
+}
+
+// ContainerStart starts a created or stopped container by running the
+// simulating program for the container's command; see also [Engine.Handle].
+func (e *Engine) ContainerStart(ctx context.Context, containerID string, options client.ContainerStartOptions) (client.ContainerStartResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerStart"); err != nil {
+		return client.ContainerStartResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerStartResult{}, noSuchContainer(containerID)
+	}
+	switch cntr.state {
+	case container.StateRunning:
+		return client.ContainerStartResult{}, nil // "not modified"
+	case container.StatePaused:
+		return client.ContainerStartResult{}, conflict(
+			"cannot start a paused container, try unpause instead")
+	}
+	if cntr.removing {
+		return client.ContainerStartResult{}, conflict(
+			"cannot start container %s: removal in progress", cntr.id)
+	}
+	e.start(cntr)
+	return client.ContainerStartResult{}, nil
+}
+
+// start a new incarnation of the specified container. start must be called
+// with the engine lock held.
+func (e *Engine) start(cntr *fakeContainer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cntr.ctx = ctx
+	cntr.cancel = cancel
+	cntr.killed = false
+	cntr.state = container.StateRunning
+	cntr.pid = e.nextPID
+	e.nextPID++
+	cntr.exitCode = 0
+	cntr.startedAt = time.Now()
+	cntr.consoleSize = client.ConsoleSize{
+		Height: cntr.hostConfig.ConsoleSize[0],
+		Width:  cntr.hostConfig.ConsoleSize[1],
+	}
+
+	var stdin io.Reader = eofReader{}
+	if cntr.config.OpenStdin {
+		stdin = cntr.stdinR
+	}
+	stdout := streamWriter{streams: cntr.streams, stream: stdcopy.Stdout}
+	stderr := io.Writer(streamWriter{streams: cntr.streams, stream: stdcopy.Stderr})
+	if cntr.config.Tty {
+		stderr = stdout
+	}
+	proc := &Process{
+		ContainerID: cntr.id,
+		Args:        slices.Clone(cntr.args),
+		Env:         slices.Clone(cntr.config.Env),
+		User:        cntr.config.User,
+		WorkingDir:  cntr.config.WorkingDir,
+		TTY:         cntr.config.Tty,
+		PID:         cntr.pid,
+		Stdin:       stdin,
+		Stdout:      stdout,
+		Stderr:      stderr,
+		ConsoleSize: func() (uint, uint) {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			return cntr.consoleSize.Width, cntr.consoleSize.Height
+		},
+	}
+	prog := e.program(cntr.args, Idle)
+	exited := cntr.exited
+	go func() {
+		code := prog(ctx, proc)
+		cancel()
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if cntr.killed {
+			code = 128 + 9
+		}
+		cntr.state = container.StateExited
+		cntr.exitCode = code
+		cntr.pid = 0
+		cntr.finishedAt = time.Now()
+		cntr.streams.closeAll()
+		_ = cntr.stdinR.Close()
+		cntr.stdinR, cntr.stdinW = io.Pipe()
+		cntr.exited = make(chan struct{})
+		close(exited)
+		if cntr.hostConfig.AutoRemove && !cntr.removing && e.containers[cntr.id] == cntr {
+			e.removeContainer(cntr)
+		}
+	}()
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) { return 0, io.EOF }
+
+// stop the specified container, waiting for its program to terminate, unless
+// the context gets cancelled first. stop must be called with the engine lock
+// NOT held.
+func (e *Engine) stop(ctx context.Context, cntr *fakeContainer, kill bool) error {
+	e.mu.Lock()
+	if cntr.state != container.StateRunning && cntr.state != container.StatePaused {
+		e.mu.Unlock()
+		return nil
+	}
+	if kill {
+		cntr.killed = true
+	}
+	exited := cntr.exited
+	cntr.cancel()
+	e.mu.Unlock()
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ContainerStop stops a running container, waiting for its simulating
+// program to terminate.
+func (e *Engine) ContainerStop(ctx context.Context, containerID string, options client.ContainerStopOptions) (client.ContainerStopResult, error) {
+	e.mu.Lock()
+	if err := e.injected("ContainerStop"); err != nil {
+		e.mu.Unlock()
+		return client.ContainerStopResult{}, err
+	}
+	cntr := e.container(containerID)
+	e.mu.Unlock()
+	if cntr == nil {
+		return client.ContainerStopResult{}, noSuchContainer(containerID)
+	}
+	return client.ContainerStopResult{}, e.stop(ctx, cntr, false)
+}
+
+// ContainerKill kills a running container, without waiting for its
+// simulating program to terminate. A killed container always has exit code
+// 137.
+func (e *Engine) ContainerKill(ctx context.Context, containerID string, options client.ContainerKillOptions) (client.ContainerKillResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerKill"); err != nil {
+		return client.ContainerKillResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerKillResult{}, noSuchContainer(containerID)
+	}
+	if cntr.state != container.StateRunning && cntr.state != container.StatePaused {
+		return client.ContainerKillResult{}, conflict("container %s is not running", cntr.id)
+	}
+	cntr.killed = true
+	cntr.cancel()
+	return client.ContainerKillResult{}, nil
+}
+
+// ContainerRestart stops a container if it is running and then starts it
+// again.
+func (e *Engine) ContainerRestart(ctx context.Context, containerID string, options client.ContainerRestartOptions) (client.ContainerRestartResult, error) {
+	e.mu.Lock()
+	if err := e.injected("ContainerRestart"); err != nil {
+		e.mu.Unlock()
+		return client.ContainerRestartResult{}, err
+	}
+	cntr := e.container(containerID)
+	e.mu.Unlock()
+	if cntr == nil {
+		return client.ContainerRestartResult{}, noSuchContainer(containerID)
+	}
+	if err := e.stop(ctx, cntr, false); err != nil {
+		return client.ContainerRestartResult{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.containers[cntr.id] != cntr {
+		return client.ContainerRestartResult{}, noSuchContainer(containerID)
+	}
+	cntr.restarts++
+	e.start(cntr)
+	return client.ContainerRestartResult{}, nil
+}
+
+// ContainerPause pauses a running container. Please note that the simulating
+// program continues to run.
+func (e *Engine) ContainerPause(ctx context.Context, containerID string, options client.ContainerPauseOptions) (client.ContainerPauseResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerPause"); err != nil {
+		return client.ContainerPauseResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerPauseResult{}, noSuchContainer(containerID)
+	}
+	switch cntr.state {
+	case container.StatePaused:
+		return client.ContainerPauseResult{}, conflict("container %s is already paused", cntr.id)
+	case container.StateRunning:
+		cntr.state = container.StatePaused
+		return client.ContainerPauseResult{}, nil
+	}
+	return client.ContainerPauseResult{}, conflict("container %s is not running", cntr.id)
+}
+
+// ContainerUnpause unpauses a paused container.
+func (e *Engine) ContainerUnpause(ctx context.Context, containerID string, options client.ContainerUnpauseOptions) (client.ContainerUnpauseResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerUnpause"); err != nil {
+		return client.ContainerUnpauseResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerUnpauseResult{}, noSuchContainer(containerID)
+	}
+	if cntr.state != container.StatePaused {
+		return client.ContainerUnpauseResult{}, conflict("container %s is not paused", cntr.id)
+	}
+	cntr.state = container.StateRunning
+	return client.ContainerUnpauseResult{}, nil
+}
+
+// ContainerRemove removes a container; a running container is only removed
+// when forced.
+func (e *Engine) ContainerRemove(ctx context.Context, containerID string, options client.ContainerRemoveOptions) (client.ContainerRemoveResult, error) {
+	e.mu.Lock()
+	if err := e.injected("ContainerRemove"); err != nil {
+		e.mu.Unlock()
+		return client.ContainerRemoveResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		e.mu.Unlock()
+		return client.ContainerRemoveResult{}, noSuchContainer(containerID)
+	}
+	if cntr.removing {
+		e.mu.Unlock()
+		return client.ContainerRemoveResult{}, conflict(
+			"removal of container %s is already in progress", cntr.id)
+	}
+	running := cntr.state == container.StateRunning || cntr.state == container.StatePaused
+	if running && !options.Force {
+		e.mu.Unlock()
+		return client.ContainerRemoveResult{}, conflict(
+			"cannot remove container \"/%s\": container is running: stop the container before removing or force remove",
+			cntr.name)
+	}
+	cntr.removing = true
+	e.mu.Unlock()
+
+	err := e.stop(ctx, cntr, true)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cntr.removing = false
+	if err != nil {
+		return client.ContainerRemoveResult{}, err
+	}
+	if e.containers[cntr.id] == cntr {
+		e.removeContainer(cntr)
+	}
+	return client.ContainerRemoveResult{}, nil
+}
+
+// removeContainer removes the (stopped) container, as well as its command
+// executions. removeContainer must be called with the engine lock held.
+func (e *Engine) removeContainer(cntr *fakeContainer) {
+	delete(e.containers, cntr.id)
+	for _, execID := range cntr.execIDs {
+		delete(e.execs, execID)
+	}
+	cntr.streams.closeAll()
+	_ = cntr.stdinR.Close()
+	close(cntr.removed)
+}
+
+// ContainerRename renames a container, unless the new name is already taken.
+func (e *Engine) ContainerRename(ctx context.Context, containerID string, options client.ContainerRenameOptions) (client.ContainerRenameResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerRename"); err != nil {
+		return client.ContainerRenameResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerRenameResult{}, noSuchContainer(containerID)
+	}
+	newname := strings.TrimPrefix(options.NewName, "/")
+	if !validContainerName.MatchString(newname) {
+		return client.ContainerRenameResult{}, invalid(
+			"Invalid container name (%s), only [a-zA-Z0-9][a-zA-Z0-9_.-] are allowed", newname)
+	}
+	for _, other := range e.containers {
+		if other.name == newname && other != cntr {
+			return client.ContainerRenameResult{}, conflict(
+				"Conflict. The container name \"/%s\" is already in use by container \"%s\". "+
+					"You have to remove (or rename) that container to be able to reuse that name.",
+				newname, other.id)
+		}
+	}
+	cntr.name = newname
+	return client.ContainerRenameResult{}, nil
+}
+
+// ContainerResize changes the size of the pseudo TTY of a running container.
+func (e *Engine) ContainerResize(ctx context.Context, containerID string, options client.ContainerResizeOptions) (client.ContainerResizeResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerResize"); err != nil {
+		return client.ContainerResizeResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerResizeResult{}, noSuchContainer(containerID)
+	}
+	if cntr.state != container.StateRunning {
+		return client.ContainerResizeResult{}, conflict("container %s is not running", cntr.id)
+	}
+	cntr.consoleSize = client.ConsoleSize{Height: options.Height, Width: options.Width}
+	return client.ContainerResizeResult{}, nil
+}
+
+// ContainerWait waits for a container to reach the specified condition.
+func (e *Engine) ContainerWait(ctx context.Context, containerID string, options client.ContainerWaitOptions) client.ContainerWaitResult {
+	resultCh := make(chan container.WaitResponse, 1)
+	errCh := make(chan error, 1)
+	result := client.ContainerWaitResult{Result: resultCh, Error: errCh}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerWait"); err != nil {
+		errCh <- err
+		return result
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		errCh <- noSuchContainer(containerID)
+		return result
+	}
+	var until <-chan struct{}
+	switch options.Condition {
+	case container.WaitConditionRemoved:
+		until = cntr.removed
+	case container.WaitConditionNextExit:
+		until = cntr.exited
+	default:
+		if cntr.state != container.StateRunning && cntr.state != container.StatePaused {
+			resultCh <- container.WaitResponse{StatusCode: int64(cntr.exitCode)}
+			return result
+		}
+		until = cntr.exited
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			errCh <- ctx.Err()
+		case <-until:
+			e.mu.Lock()
+			code := cntr.exitCode
+			e.mu.Unlock()
+			resultCh <- container.WaitResponse{StatusCode: int64(code)}
+		}
+	}()
+	return result
+}
+
+// DefaultDetachKeys is the key sequence for detaching from a container's input
+// when not overridden in the attach options, as with Docker.
+const DefaultDetachKeys = "ctrl-p,ctrl-q"
+
+// copyDetachable copies from src to dst until either src reaches EOF or the
+// detach key sequence has been found in src, returning true in the latter
+// case. A partially matching detach key sequence is held back until it either
+// completes, mismatches, or src reaches EOF.
+func copyDetachable(dst io.Writer, src io.Reader, detachSeq []byte) (detached bool) {
+	buf := make([]byte, 4096)
+	matched := 0
+	for {
+		n, err := src.Read(buf)
+		var out []byte
+		for _, b := range buf[:n] {
+			if len(detachSeq) > 0 && b == detachSeq[matched] {
+				matched++
+				if matched == len(detachSeq) {
+					if len(out) > 0 {
+						_, _ = dst.Write(out)
+					}
+					return true
+				}
+				continue
+			}
+			if matched > 0 {
+				out = append(out, detachSeq[:matched]...)
+				matched = 0
+				if len(detachSeq) > 0 && b == detachSeq[0] {
+					matched = 1
+					continue
+				}
+			}
+			out = append(out, b)
+		}
+		if err != nil {
+			// Don't swallow a partial detach key sequence at the end.
+			out = append(out, detachSeq[:matched]...)
+		}
+		if len(out) > 0 {
+			if _, werr := dst.Write(out); werr != nil {
+				return false
+			}
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// ContainerAttach attaches to the input and/or output streams of a
+// container. Attaching to the container's input requires the container to
+// have been created with its stdin opened.
+func (e *Engine) ContainerAttach(ctx context.Context, containerID string, options client.ContainerAttachOptions) (client.ContainerAttachResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerAttach"); err != nil {
+		return client.ContainerAttachResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerAttachResult{}, noSuchContainer(containerID)
+	}
+	detachKeys := options.DetachKeys
+	if detachKeys == "" {
+		detachKeys = DefaultDetachKeys
+	}
+	detachSeq, err := term.ToBytes(detachKeys)
+	if err != nil {
+		return client.ContainerAttachResult{}, invalid("Invalid detach keys (%s) provided", detachKeys)
+	}
+	var cln, srv *pipeConn
+	if options.Logs {
+		cln, srv = cntr.streams.attachReplaying(options.Stdout, options.Stderr, options.Stream)
+	} else {
+		cln, srv = cntr.streams.attach(options.Stdout, options.Stderr)
+	}
+	if options.Stdin && cntr.config.OpenStdin {
+		stdinW := cntr.stdinW
+		once := cntr.config.StdinOnce
+		go func() {
+			if copyDetachable(stdinW, srv, detachSeq) {
+				// Detaching ends the attachment, but leaves the container's
+				// stdin open.
+				_ = srv.Close()
+				return
+			}
+			if once {
+				_ = stdinW.Close()
+			}
+		}()
+	}
+	mediaType := "application/vnd.docker.multiplexed-stream"
+	if cntr.config.Tty {
+		mediaType = "application/vnd.docker.raw-stream"
+	}
+	return client.ContainerAttachResult{
+		HijackedResponse: client.NewHijackedResponse(cln, mediaType),
+	}, nil
+}
+
+// ContainerInspect returns the details of a container.
+func (e *Engine) ContainerInspect(ctx context.Context, containerID string, options client.ContainerInspectOptions) (client.ContainerInspectResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerInspect"); err != nil {
+		return client.ContainerInspectResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ContainerInspectResult{}, noSuchContainer(containerID)
+	}
+	hostConfig := *cntr.hostConfig
+	details := container.InspectResponse{
+		ID:      cntr.id,
+		Created: cntr.created.Format(time.RFC3339Nano),
+		Path:    cntr.args[0],
+		Args:    slices.Clone(cntr.args[1:]),
+		State: &container.State{
+			Status:     cntr.state,
+			Running:    cntr.state == container.StateRunning || cntr.state == container.StatePaused,
+			Paused:     cntr.state == container.StatePaused,
+			Pid:        cntr.pid,
+			ExitCode:   cntr.exitCode,
+			StartedAt:  formatTime(cntr.startedAt),
+			FinishedAt: formatTime(cntr.finishedAt),
+		},
+		Image:        cntr.imageID,
+		Name:         "/" + cntr.name,
+		RestartCount: cntr.restarts,
+		Driver:       "overlay2",
+		Platform:     "linux",
+		ExecIDs:      slices.Clone(cntr.execIDs),
+		HostConfig:   &hostConfig,
+		Config:       copyConfig(cntr.config),
+		NetworkSettings: &container.NetworkSettings{
+			Ports:    maps.Clone(cntr.ports),
+			Networks: cntr.endpoints(),
+		},
+	}
+	return client.ContainerInspectResult{Container: details}, nil
+}
+
+func (c *fakeContainer) endpoints() map[string]*network.EndpointSettings {
+	eps := map[string]*network.EndpointSettings{}
+	for name, ep := range c.networks {
+		eps[name] = ep.Copy()
+	}
+	return eps
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "0001-01-01T00:00:00Z"
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// ContainerLogs returns the output of a container so far, multiplexed as
+// Docker does when the container does not use a TTY. Following the logs as
+// well as the since, until, tail, and timestamp options are not supported.
+func (e *Engine) ContainerLogs(ctx context.Context, containerID string, options client.ContainerLogsOptions) (client.ContainerLogsResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerLogs"); err != nil {
+		return nil, err
+	}
+	if options.Follow {
+		return nil, errdefs.ErrNotImplemented.WithMessage("following container logs is not supported")
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return nil, noSuchContainer(containerID)
+	}
+	return io.NopCloser(bytes.NewReader(
+		cntr.streams.logs(options.ShowStdout, options.ShowStderr))), nil
+}
+
+// ContainerList lists the running containers, or all containers, optionally
+// filtered by “label”, “name”, “id”, and “status”.
+func (e *Engine) ContainerList(ctx context.Context, options client.ContainerListOptions) (client.ContainerListResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ContainerList"); err != nil {
+		return client.ContainerListResult{}, err
+	}
+	items := []container.Summary{}
+	for _, cntr := range e.containers {
+		if !options.All && len(options.Filters["status"]) == 0 &&
+			cntr.state != container.StateRunning && cntr.state != container.StatePaused {
+			continue
+		}
+		if !matchesLabelFilters(options.Filters, cntr.config.Labels) ||
+			!matchesAny(options.Filters, "name", func(name string) bool {
+				return strings.Contains(cntr.name, strings.TrimPrefix(name, "/"))
+			}) ||
+			!matchesAny(options.Filters, "id", func(id string) bool { return strings.HasPrefix(cntr.id, id) }) ||
+			!matchesAny(options.Filters, "status", func(status string) bool { return string(cntr.state) == status }) {
+			continue
+		}
+		summary := container.Summary{
+			ID:              cntr.id,
+			Names:           []string{"/" + cntr.name},
+			Image:           cntr.config.Image,
+			ImageID:         cntr.imageID,
+			Command:         strings.Join(cntr.args, " "),
+			Created:         cntr.created.Unix(),
+			Labels:          maps.Clone(cntr.config.Labels),
+			State:           cntr.state,
+			Status:          cntr.status(),
+			NetworkSettings: &container.NetworkSettingsSummary{Networks: cntr.endpoints()},
+		}
+		summary.HostConfig.NetworkMode = string(cntr.hostConfig.NetworkMode)
+		items = append(items, summary)
+	}
+	slices.SortFunc(items, func(a, b container.Summary) int { return int(b.Created - a.Created) })
+	return client.ContainerListResult{Items: items}, nil
+}
+
+// status returns a human-readable status description.
+func (c *fakeContainer) status() string {
+	switch c.state {
+	case container.StateRunning:
+		return "Up " + time.Since(c.startedAt).Round(time.Second).String()
+	case container.StatePaused:
+		return "Up " + time.Since(c.startedAt).Round(time.Second).String() + " (Paused)"
+	case container.StateExited:
+		return fmt.Sprintf("Exited (%d) %s ago", c.exitCode, time.Since(c.finishedAt).Round(time.Second))
+	}
+	return "Created"
+}
@@ -0,0 +1,25 @@
+/*
+Package fakeengine provides an in-process, in-memory fake Docker engine that
+implements [github.com/thediveo/morbyd/v2/moby.Client], so that code built on
+top of [github.com/thediveo/morbyd/v2.Session] can be unit tested offline
+without any Docker daemon.
+
+The fake engine keeps track of containers, images, networks, and command
+executions. It reports errors with the same [errdefs] semantics as the real
+Docker engine, such as “not found” and “conflict”.
+
+As there is no real container runtime, the processes inside containers and
+executed commands are simulated by [Program] functions that are registered with
+an [Engine] using [Engine.Handle]. Programs get access to the attached input and
+output streams, so their output can be scripted.
+
+Plug a fake engine into a new test session using [WithEngine]:
+
+	engine := fakeengine.New()
+	engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+	engine.Handle("sh", fakeengine.Echo("Hellorld!\n", 0))
+	sess, err := morbyd.NewSession(ctx, fakeengine.WithEngine(engine))
+
+[errdefs]: https://pkg.go.dev/github.com/containerd/errdefs
+*/
+package fakeengine
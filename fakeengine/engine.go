@@ -0,0 +1,255 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/registry"
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/moby"
+	"github.com/thediveo/morbyd/v2/session"
+)
+
+// DefaultPlatformName is the platform name reported by a fake engine, unless
+// changed using [Engine.SetPlatformName].
+const DefaultPlatformName = "morbyd Fake Engine"
+
+// Engine is an in-memory fake Docker engine implementing [moby.Client]. Use
+// [New] to create a new fake engine.
+type Engine struct {
+	mu sync.Mutex
+
+	platformName string
+
+	containers map[string]*fakeContainer      // by ID
+	execs      map[string]*fakeExec           // by ID
+	images     map[string]*fakeImage          // by ID
+	remotes    map[string]Image               // pullable images, by normalized reference
+	auths      map[string]registry.AuthConfig // required registry credentials, by host
+	networks   map[string]*fakeNetwork        // by ID
+	programs   map[string]Program             // by command name
+
+	failures map[string][]error // injected failures, by API method name
+
+	nextPID    int
+	nextSubnet int
+	nextPort   uint16
+}
+
+var _ moby.Client = (*Engine)(nil)
+
+// New returns a new fake engine that initially knows only about the
+// predefined “bridge”, “host”, and “none” networks, but without any images
+// and containers.
+func New() *Engine {
+	e := &Engine{
+		platformName: DefaultPlatformName,
+		containers:   map[string]*fakeContainer{},
+		execs:        map[string]*fakeExec{},
+		images:       map[string]*fakeImage{},
+		remotes:      map[string]Image{},
+		auths:        map[string]registry.AuthConfig{},
+		networks:     map[string]*fakeNetwork{},
+		programs:     map[string]Program{},
+		failures:     map[string][]error{},
+		nextPID:      4242,
+		nextSubnet:   18,
+		nextPort:     32768,
+	}
+	e.addPredefinedNetworks()
+	return e
+}
+
+// WithEngine returns a session option that replaces the session's Docker
+// client with the specified fake engine.
+func WithEngine(e *Engine) session.Opt {
+	return func(o *session.Options) error {
+		o.Wrapper = e.Wrapper
+		return nil
+	}
+}
+
+// Wrapper ignores the passed Docker client and instead returns the fake
+// engine. It is intended for use with the session.Options.Wrapper hook.
+func (e *Engine) Wrapper(moby.Client) moby.Client { return e }
+
+// SetPlatformName sets the platform name to report as part of the server
+// version information, such as “Docker Desktop”.
+func (e *Engine) SetPlatformName(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.platformName = name
+}
+
+// Handle registers the specified program to simulate container processes and
+// executed commands with the specified command name. The command name is
+// matched against the first command argument with any directory part removed,
+// so “sh” matches both “sh” as well as “/bin/sh”.
+//
+// Container processes without any matching program default to [Idle], while
+// executed commands without any matching program default to [Exit](0).
+func (e *Engine) Handle(name string, prog Program) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.programs[name] = prog
+}
+
+// program returns the program to simulate the specified command, or the
+// passed default program if there is no program registered for the command.
+func (e *Engine) program(args []string, defprog Program) Program {
+	if len(args) == 0 {
+		return defprog
+	}
+	if prog, ok := e.programs[filepath.Base(args[0])]; ok {
+		return prog
+	}
+	return defprog
+}
+
+// FailNext injects the specified error to be returned by the next call to the
+// API method with the specified name, such as “ContainerCreate”. FailNext can
+// be called multiple times for the same API method in order to queue up
+// multiple errors for consecutive calls.
+func (e *Engine) FailNext(method string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures[method] = append(e.failures[method], err)
+}
+
+// injected returns the next injected failure for the specified API method, or
+// nil. injected must be called with the engine lock held.
+func (e *Engine) injected(method string) error {
+	errs := e.failures[method]
+	if len(errs) == 0 {
+		return nil
+	}
+	e.failures[method] = errs[1:]
+	return errs[0]
+}
+
+// Close the fake engine client; this is a no-op, as there are no connections
+// to close.
+func (e *Engine) Close() error { return nil }
+
+// ServerVersion returns fake server version information.
+func (e *Engine) ServerVersion(ctx context.Context, _ client.ServerVersionOptions) (client.ServerVersionResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ServerVersion"); err != nil {
+		return client.ServerVersionResult{}, err
+	}
+	return client.ServerVersionResult{
+		Platform:      client.PlatformInfo{Name: e.platformName},
+		Version:       "0.0.0-fake",
+		APIVersion:    "1.52",
+		MinAPIVersion: "1.24",
+		Os:            "linux",
+		Arch:          "amd64",
+	}, nil
+}
+
+// DialHijack always fails, as the fake engine doesn't support any hijacked
+// connections, such as BuildKit sessions.
+func (e *Engine) DialHijack(ctx context.Context, url, proto string, meta map[string][]string) (net.Conn, error) {
+	return nil, errdefs.ErrNotImplemented.WithMessage(
+		"fake engine does not support hijacked connections")
+}
+
+var _ client.HijackDialer = (*Engine)(nil)
+
+// newID returns a new random 256 bit ID in hex format.
+func newID() string {
+	var id [32]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// resolve looks up an object by its ID, unique ID prefix, or name, returning
+// nil if not found. The passed name function returns the name(s) of an object.
+func resolve[T any](objs map[string]T, nameID string, names func(T) []string) (T, bool) {
+	if obj, ok := objs[nameID]; ok {
+		return obj, true
+	}
+	for _, obj := range objs {
+		for _, name := range names(obj) {
+			if name == nameID {
+				return obj, true
+			}
+		}
+	}
+	var found T
+	matches := 0
+	for id, obj := range objs {
+		if strings.HasPrefix(id, nameID) {
+			found = obj
+			matches++
+		}
+	}
+	return found, matches == 1 && nameID != ""
+}
+
+// matchesLabelFilters returns true if all label filter terms match the
+// specified labels, where the terms are either in “key” or “key=value”
+// format.
+func matchesLabelFilters(f client.Filters, labels map[string]string) bool {
+	for term := range f["label"] {
+		key, value, hasValue := strings.Cut(term, "=")
+		v, ok := labels[key]
+		if !ok || (hasValue && v != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny returns true if there is no filter for the specified term, or if
+// at least one of the filter values of the specified term matches.
+func matchesAny(f client.Filters, term string, match func(value string) bool) bool {
+	values, ok := f[term]
+	if !ok || len(values) == 0 {
+		return true
+	}
+	for value := range values {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// notFound returns a “not found” error with the specified message.
+func notFound(format string, args ...any) error {
+	return errdefs.ErrNotFound.WithMessage(fmt.Sprintf(format, args...))
+}
+
+// conflict returns a “conflict” error with the specified message.
+func conflict(format string, args ...any) error {
+	return errdefs.ErrConflict.WithMessage(fmt.Sprintf(format, args...))
+}
+
+// invalid returns an “invalid argument” error with the specified message.
+func invalid(format string, args ...any) error {
+	return errdefs.ErrInvalidArgument.WithMessage(fmt.Sprintf(format, args...))
+}
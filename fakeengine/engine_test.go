@@ -0,0 +1,361 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+	"github.com/thediveo/safe"
+
+	"github.com/thediveo/morbyd/v2"
+	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/exec"
+	"github.com/thediveo/morbyd/v2/net"
+	"github.com/thediveo/morbyd/v2/pull"
+	"github.com/thediveo/morbyd/v2/push"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+	"github.com/thediveo/morbyd/v2/timestamper"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("fake engine", func() {
+
+	var engine *Engine
+	var sess *morbyd.Session
+
+	BeforeEach(func(ctx context.Context) {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		engine = New()
+		engine.AddImage(Image{Ref: "busybox", Cmd: []string{"sh"}})
+		sess = Successful(morbyd.NewSession(ctx,
+			WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=fakeengine")))
+		DeferCleanup(func(ctx context.Context) {
+			sess.Close(ctx)
+		})
+	})
+
+	It("reports its platform", func(ctx context.Context) {
+		Expect(sess.Client().ServerVersion(ctx, client.ServerVersionOptions{})).To(
+			HaveField("Platform.Name", DefaultPlatformName))
+		engine.SetPlatformName("Docker Desktop 4.2.0 (fake)")
+		Expect(sess.Client().ServerVersion(ctx, client.ServerVersionOptions{})).To(
+			HaveField("Platform.Name", ContainSubstring("Docker Desktop")))
+	})
+
+	It("runs a container and collects its output", func(ctx context.Context) {
+		engine.Handle("greet", Echo("Hellorld!\n", 42))
+		var out safe.Buffer
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithCommand("/bin/greet"),
+			run.WithCombinedOutput(&out)))
+		Expect(cntr.Wait(ctx)).To(Succeed())
+		Expect(cntr.Refresh(ctx)).To(Succeed())
+		Expect(cntr.Details.Container.State.ExitCode).To(Equal(42))
+		Eventually(out.String).Should(Equal("Hellorld!\n"))
+		Expect(cntr.Details.Container.Config.Labels).To(
+			HaveKeyWithValue(morbyd.ContainerRunnerLabelName, ContainSubstring("engine_test.go")))
+	})
+
+	It("demuxes stdout and stderr", func(ctx context.Context) {
+		engine.Handle("sh", func(ctx context.Context, proc *Process) int {
+			_, _ = proc.Stdout.Write([]byte("out\n"))
+			_, _ = proc.Stderr.Write([]byte("err\n"))
+			return 0
+		})
+		var stdout, stderr safe.Buffer
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithDemuxedOutput(&stdout, &stderr)))
+		Expect(cntr.Wait(ctx)).To(Succeed())
+		Eventually(stdout.String).Should(Equal("out\n"))
+		Eventually(stderr.String).Should(Equal("err\n"))
+	})
+
+	It("serves container logs", func(ctx context.Context) {
+		engine.Handle("sh", func(ctx context.Context, proc *Process) int {
+			_, _ = proc.Stdout.Write([]byte("out\n"))
+			_, _ = proc.Stderr.Write([]byte("err\n"))
+			return 0
+		})
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		Expect(cntr.Wait(ctx)).To(Succeed())
+		logs := Successful(sess.Client().ContainerLogs(ctx, cntr.ID, client.ContainerLogsOptions{
+			ShowStderr: true,
+		}))
+		defer logs.Close()
+		var stdout, stderr bytes.Buffer
+		Expect(stdcopy.StdCopy(&stdout, &stderr, logs)).Error().NotTo(HaveOccurred())
+		Expect(stdout.String()).To(BeEmpty())
+		Expect(stderr.String()).To(Equal("err\n"))
+
+		Expect(sess.Client().ContainerLogs(ctx, cntr.ID, client.ContainerLogsOptions{
+			Follow: true,
+		})).Error().To(HaveOccurred())
+	})
+
+	It("feeds input to a container", func(ctx context.Context) {
+		engine.Handle("sh", Cat)
+		var out safe.Buffer
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithInput(strings.NewReader("Hellorld!")),
+			run.WithCombinedOutput(&out)))
+		Eventually(out.String).Should(Equal("Hellorld!"))
+		cntr.Stop(ctx)
+	})
+
+	It("replays logs when attaching", func(ctx context.Context) {
+		engine.Handle("sh", Echo("Hellorld!\n", 0))
+		var out safe.Buffer
+		cntr := Successful(sess.Run(ctx, "busybox", run.WithCombinedOutput(&out)))
+		Eventually(out.String).Should(Equal("Hellorld!\n"))
+
+		resp := Successful(sess.Client().ContainerAttach(ctx, cntr.ID, client.ContainerAttachOptions{
+			Logs:   true,
+			Stdout: true,
+		}))
+		defer resp.HijackedResponse.Close()
+		var logs bytes.Buffer
+		Expect(stdcopy.StdCopy(&logs, io.Discard, resp.HijackedResponse.Reader)).Error().NotTo(HaveOccurred())
+		Expect(logs.String()).To(Equal("Hellorld!\n"))
+
+		Expect(sess.Client().ContainerAttach(ctx, cntr.ID, client.ContainerAttachOptions{
+			DetachKeys: "ctrl-",
+		})).Error().To(MatchError(errdefs.IsInvalidArgument, "IsInvalidArgument"))
+	})
+
+	DescribeTable("detaching from input",
+		func(input string, expectedDetached bool, expectedOut string) {
+			var out bytes.Buffer
+			Expect(copyDetachable(&out, strings.NewReader(input), []byte("\x10\x11"))).To(Equal(expectedDetached))
+			Expect(out.String()).To(Equal(expectedOut))
+		},
+		Entry("without detach keys", "foobar", false, "foobar"),
+		Entry("with detach keys", "foo\x10\x11bar", true, "foo"),
+		Entry("with partial detach keys", "foo\x10bar", false, "foo\x10bar"),
+		Entry("with repeated detach key prefix", "foo\x10\x10\x11bar", true, "foo\x10"),
+		Entry("with incomplete detach keys at EOF", "foo\x10", false, "foo\x10"),
+	)
+
+	It("resizes TTYs", func(ctx context.Context) {
+		engine.Handle("sh", func(ctx context.Context, proc *Process) int {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				buf := make([]byte, 1)
+				for {
+					if _, err := proc.Stdin.Read(buf); err != nil {
+						return
+					}
+					width, height := proc.ConsoleSize()
+					_, _ = fmt.Fprintf(proc.Stdout, "%dx%d\n", width, height)
+				}
+			}()
+			select {
+			case <-done:
+				return 0
+			case <-ctx.Done():
+				return 128 + 15
+			}
+		})
+		inr, inw := io.Pipe()
+		defer inw.Close() //nolint:errcheck // any error is irrelevant at this point
+		var out safe.Buffer
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithTTY(),
+			run.WithConsoleSize(80, 24),
+			run.WithInput(inr),
+			run.WithCombinedOutput(&out)))
+		_ = Successful(inw.Write([]byte{'\n'}))
+		Eventually(out.String).Should(Equal("80x24\n"))
+		Expect(cntr.Resize(ctx, 132, 43)).To(Succeed())
+		_ = Successful(inw.Write([]byte{'\n'}))
+		Eventually(out.String).Should(Equal("80x24\n132x43\n"))
+
+		var eout safe.Buffer
+		einr, einw := io.Pipe()
+		defer einw.Close() //nolint:errcheck // any error is irrelevant at this point
+		es := Successful(cntr.Exec(ctx, exec.Command("sh"),
+			exec.WithTTY(),
+			exec.WithConsoleSize(40, 12),
+			exec.WithInput(einr),
+			exec.WithCombinedOutput(&eout)))
+		_ = Successful(einw.Write([]byte{'\n'}))
+		Eventually(eout.String).Should(Equal("40x12\n"))
+		Expect(es.Resize(ctx, 42, 13)).To(Succeed())
+		_ = Successful(einw.Write([]byte{'\n'}))
+		Eventually(eout.String).Should(Equal("40x12\n42x13\n"))
+
+		es = Successful(cntr.Exec(ctx, exec.Command("true")))
+		Expect(es.Wait(ctx)).To(Equal(0))
+		Expect(es.Resize(ctx, 1, 1)).To(MatchError(ContainSubstring("is not running")))
+
+		cntr.Kill(ctx)
+		Expect(cntr.Resize(ctx, 1, 1)).To(MatchError(ContainSubstring("No such container")))
+		Expect(sess.Client().ExecResize(ctx, "nada", client.ExecResizeOptions{})).Error().To(
+			MatchError(errdefs.IsNotFound, "IsNotFound"))
+	})
+
+	It("stops, kills, and auto-removes containers", func(ctx context.Context) {
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		Expect(cntr.Details.Container.State.Running).To(BeTrue())
+		Expect(cntr.PID(ctx)).To(BeNumerically(">", 0))
+		cntr.Stop(ctx)
+		Expect(cntr.Refresh(ctx)).To(Succeed())
+		Expect(cntr.Details.Container.State.ExitCode).To(Equal(143))
+
+		cntr = Successful(sess.Run(ctx, "busybox", run.WithAutoRemove()))
+		Expect(sess.Client().ContainerKill(ctx, cntr.ID, client.ContainerKillOptions{})).Error().NotTo(HaveOccurred())
+		Eventually(func() error {
+			_, err := sess.Container(ctx, cntr.ID)
+			return err
+		}).Should(MatchError(errdefs.IsNotFound, "IsNotFound"))
+	})
+
+	It("reports container name conflicts", func(ctx context.Context) {
+		_ = Successful(sess.Run(ctx, "busybox", run.WithName("squatter")))
+		Expect(sess.Run(ctx, "busybox", run.WithName("squatter"))).Error().To(
+			MatchError(ContainSubstring("name already taken by")))
+	})
+
+	It("refuses to remove running containers unless forced", func(ctx context.Context) {
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		Expect(sess.Client().ContainerRemove(ctx, cntr.ID, client.ContainerRemoveOptions{})).Error().To(
+			MatchError(errdefs.IsConflict, "IsConflict"))
+		cntr.Kill(ctx)
+		Expect(sess.Container(ctx, cntr.ID)).Error().To(MatchError(errdefs.IsNotFound, "IsNotFound"))
+	})
+
+	It("executes commands", func(ctx context.Context) {
+		engine.Handle("find", Echo("/bin/busybox\n", 1))
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithCombinedOutput(timestamper.New(GinkgoWriter))))
+
+		var out safe.Buffer
+		es := Successful(cntr.Exec(ctx, exec.Command("find", "/"),
+			exec.WithCombinedOutput(&out)))
+		Expect(es.Wait(ctx)).To(Equal(1))
+		Expect(out.String()).To(Equal("/bin/busybox\n"))
+
+		Expect(sess.Client().ExecAttach(ctx, es.ID, client.ExecAttachOptions{})).Error().To(
+			MatchError(errdefs.IsConflict, "IsConflict"))
+		cntr.Kill(ctx)
+		Expect(sess.Client().ExecInspect(ctx, es.ID, client.ExecInspectOptions{})).Error().To(
+			MatchError(errdefs.IsNotFound, "IsNotFound"))
+	})
+
+	It("creates, connects, and removes networks", func(ctx context.Context) {
+		netw := Successful(sess.CreateNetwork(ctx, "fakenet", net.WithInternal()))
+		cntr := Successful(sess.Run(ctx, "busybox", run.WithNetwork(netw.ID)))
+		Expect(cntr.Details.Container.NetworkSettings.Networks).To(HaveKey("fakenet"))
+		Expect(cntr.IP(ctx).IsValid()).To(BeTrue())
+
+		Expect(netw.Remove(ctx)).To(MatchError(errdefs.IsConflict, "IsConflict"))
+		cntr.Kill(ctx)
+		Expect(netw.Remove(ctx)).To(Succeed())
+		Expect(sess.Network(ctx, "fakenet")).Error().To(MatchError(errdefs.IsNotFound, "IsNotFound"))
+	})
+
+	It("auto-cleans containers and networks", func(ctx context.Context) {
+		_ = Successful(sess.CreateNetwork(ctx, "fakenet"))
+		_ = Successful(sess.Run(ctx, "busybox"))
+		sess.AutoClean(ctx)
+		Expect(sess.Client().ContainerList(ctx, client.ContainerListOptions{All: true})).To(
+			HaveField("Items", BeEmpty()))
+		Expect(sess.Network(ctx, "fakenet")).Error().To(HaveOccurred())
+	})
+
+	It("pulls only remotely available images", func(ctx context.Context) {
+		Expect(sess.HasImage(ctx, "alpine")).To(BeFalse())
+		Expect(sess.PullImage(ctx, "alpine")).Error().To(HaveOccurred())
+		engine.AddRemoteImage(Image{Ref: "alpine:latest", Cmd: []string{"/bin/sh"}})
+		Expect(sess.PullImage(ctx, "alpine")).Error().NotTo(HaveOccurred())
+		Expect(sess.HasImage(ctx, "docker.io/library/alpine:latest")).To(BeTrue())
+	})
+
+	It("builds images", func(ctx context.Context) {
+		tmpdir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(tmpdir, "Dockerfile"), []byte(`FROM busybox
+LABEL foo=bar
+RUN echo "this won't run"
+CMD ["/bin/hello"]
+`), 0o644)).To(Succeed())
+		id := Successful(sess.BuildImage(ctx, tmpdir,
+			build.WithTag("fakebuild"),
+			build.WithOutput(timestamper.New(GinkgoWriter))))
+		Expect(id).To(HavePrefix("sha256:"))
+
+		img := Successful(sess.Client().ImageInspect(ctx, "fakebuild"))
+		Expect(img.ID).To(Equal(id))
+		Expect(img.Config.Labels).To(HaveKeyWithValue("foo", "bar"))
+		Expect(img.Config.Cmd).To(ConsistOf("/bin/hello"))
+
+		history := Successful(sess.Client().ImageHistory(ctx, "fakebuild"))
+		Expect(history.Items).To(HaveExactElements(
+			And(HaveField("ID", id), HaveField("CreatedBy", `CMD ["/bin/hello"]`),
+				HaveField("Tags", ConsistOf("fakebuild:latest"))),
+			HaveField("CreatedBy", `RUN echo "this won't run"`),
+			HaveField("CreatedBy", "LABEL foo=bar"),
+			HaveField("CreatedBy", "FROM busybox"),
+			And(HaveField("ID", "<missing>"), HaveField("CreatedBy", ContainSubstring("ADD file:")))))
+		Expect(sess.Client().ImageHistory(ctx, "busybox")).To(
+			HaveField("Items", HaveExactElements(HaveField("Tags", ConsistOf("busybox:latest")))))
+		Expect(sess.Client().ImageHistory(ctx, "nada")).Error().To(
+			MatchError(errdefs.IsNotFound, "IsNotFound"))
+	})
+
+	It("requires registry credentials", func(ctx context.Context) {
+		engine.AddRemoteImage(Image{Ref: "registry.example.com/alpine:latest", Cmd: []string{"/bin/sh"}})
+		engine.RequireRegistryAuth("registry.example.com", "morbyd", "s3cr3t")
+		Expect(sess.PullImage(ctx, "registry.example.com/alpine")).Error().To(
+			MatchError(errdefs.IsUnauthorized, "IsUnauthorized"))
+		auth := base64.URLEncoding.EncodeToString([]byte(`{"username":"morbyd","password":"s3cr3t"}`))
+		Expect(sess.PullImage(ctx, "registry.example.com/alpine", pull.WithRegistryAuth(auth))).Error().NotTo(HaveOccurred())
+		Expect(sess.PushImage(ctx, "registry.example.com/alpine")).To(
+			MatchError(errdefs.IsUnauthorized, "IsUnauthorized"))
+		Expect(sess.PushImage(ctx, "registry.example.com/alpine", push.WithRegistryAuth(auth))).To(Succeed())
+	})
+
+	It("injects failures", func(ctx context.Context) {
+		engine.FailNext("ContainerCreate", errdefs.ErrUnavailable.WithMessage("daemon on coffee break"))
+		Expect(sess.Run(ctx, "busybox")).Error().To(MatchError(ContainSubstring("coffee break")))
+		Expect(sess.Run(ctx, "busybox")).Error().NotTo(HaveOccurred())
+
+		engine.FailNext("ImageInspect", errors.New("out of beans"))
+		Expect(sess.HasImage(ctx, "busybox")).Error().To(MatchError(ContainSubstring("out of beans")))
+	})
+
+})
@@ -0,0 +1,236 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+)
+
+// fakeExec is a command execution inside a container.
+type fakeExec struct {
+	id       string
+	cntr     *fakeContainer
+	opts     client.ExecCreateOptions
+	started  bool
+	running  bool
+	pid      int
+	exitCode int
+
+	consoleSize client.ConsoleSize
+}
+
+// exec returns the command execution with the specified ID, or nil. exec must
+// be called with the engine lock held.
+func (e *Engine) exec(execID string) *fakeExec {
+	ex, ok := resolve(e.execs, execID, func(*fakeExec) []string { return nil })
+	if !ok {
+		return nil
+	}
+	return ex
+}
+
+// ExecCreate creates a new command execution inside a running container.
+func (e *Engine) ExecCreate(ctx context.Context, containerID string, options client.ExecCreateOptions) (client.ExecCreateResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ExecCreate"); err != nil {
+		return client.ExecCreateResult{}, err
+	}
+	cntr := e.container(containerID)
+	if cntr == nil {
+		return client.ExecCreateResult{}, noSuchContainer(containerID)
+	}
+	switch cntr.state {
+	case container.StatePaused:
+		return client.ExecCreateResult{}, conflict("container %s is paused, unpause the container before exec", cntr.id)
+	case container.StateRunning:
+	default:
+		return client.ExecCreateResult{}, conflict("container %s is not running", cntr.id)
+	}
+	if len(options.Cmd) == 0 {
+		return client.ExecCreateResult{}, invalid("No exec command specified")
+	}
+	options.Cmd = slices.Clone(options.Cmd)
+	options.Env = slices.Clone(options.Env)
+	ex := &fakeExec{
+		id:   newID(),
+		cntr: cntr,
+		opts: options,
+	}
+	e.execs[ex.id] = ex
+	cntr.execIDs = append(cntr.execIDs, ex.id)
+	return client.ExecCreateResult{ID: ex.id}, nil
+}
+
+// ExecAttach starts a created command execution, attaching to its streams as
+// specified when creating the command execution.
+func (e *Engine) ExecAttach(ctx context.Context, execID string, options client.ExecAttachOptions) (client.ExecAttachResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ExecAttach"); err != nil {
+		return client.ExecAttachResult{}, err
+	}
+	ex, err := e.startableExec(execID)
+	if err != nil {
+		return client.ExecAttachResult{}, err
+	}
+	strms := &streams{tty: ex.opts.TTY}
+	cln, srv := strms.attach(ex.opts.AttachStdout, ex.opts.AttachStderr)
+	var stdin io.Reader = eofReader{}
+	if ex.opts.AttachStdin {
+		stdin = srv
+	}
+	e.startExec(ex, strms, stdin)
+	mediaType := "application/vnd.docker.multiplexed-stream"
+	if ex.opts.TTY {
+		mediaType = "application/vnd.docker.raw-stream"
+	}
+	return client.ExecAttachResult{
+		HijackedResponse: client.NewHijackedResponse(cln, mediaType),
+	}, nil
+}
+
+// ExecStart starts a created command execution in detached mode.
+func (e *Engine) ExecStart(ctx context.Context, execID string, options client.ExecStartOptions) (client.ExecStartResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ExecStart"); err != nil {
+		return client.ExecStartResult{}, err
+	}
+	ex, err := e.startableExec(execID)
+	if err != nil {
+		return client.ExecStartResult{}, err
+	}
+	e.startExec(ex, &streams{tty: ex.opts.TTY}, eofReader{})
+	return client.ExecStartResult{}, nil
+}
+
+// startableExec returns the specified command execution if it hasn't been
+// started yet and its container is still running. startableExec must be
+// called with the engine lock held.
+func (e *Engine) startableExec(execID string) (*fakeExec, error) {
+	ex := e.exec(execID)
+	if ex == nil {
+		return nil, notFound("No such exec instance: %s", execID)
+	}
+	if ex.started {
+		return nil, conflict("exec %s has already been started", ex.id)
+	}
+	if ex.cntr.state != container.StateRunning {
+		return nil, conflict("container %s is not running", ex.cntr.id)
+	}
+	return ex, nil
+}
+
+// startExec runs the simulating program of a command execution in the
+// background. startExec must be called with the engine lock held.
+func (e *Engine) startExec(ex *fakeExec, strms *streams, stdin io.Reader) {
+	ex.started = true
+	ex.running = true
+	ex.pid = e.nextPID
+	e.nextPID++
+	ex.consoleSize = ex.opts.ConsoleSize
+
+	cntr := ex.cntr
+	user := ex.opts.User
+	if user == "" {
+		user = cntr.config.User
+	}
+	workdir := ex.opts.WorkingDir
+	if workdir == "" {
+		workdir = cntr.config.WorkingDir
+	}
+	stdout := streamWriter{streams: strms, stream: stdcopy.Stdout}
+	stderr := io.Writer(streamWriter{streams: strms, stream: stdcopy.Stderr})
+	if ex.opts.TTY {
+		stderr = stdout
+	}
+	proc := &Process{
+		ContainerID: cntr.id,
+		Args:        slices.Clone(ex.opts.Cmd),
+		Env:         append(slices.Clone(cntr.config.Env), ex.opts.Env...),
+		User:        user,
+		WorkingDir:  workdir,
+		TTY:         ex.opts.TTY,
+		PID:         ex.pid,
+		Stdin:       stdin,
+		Stdout:      stdout,
+		Stderr:      stderr,
+		ConsoleSize: func() (uint, uint) {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			return ex.consoleSize.Width, ex.consoleSize.Height
+		},
+	}
+	prog := e.program(ex.opts.Cmd, Exit(0))
+	ctx := cntr.ctx
+	go func() {
+		code := prog(ctx, proc)
+
+		// Update the execution state first before signalling EOF, so clients
+		// reacting to EOF will see the final state.
+		e.mu.Lock()
+		ex.running = false
+		ex.exitCode = code
+		ex.pid = 0
+		e.mu.Unlock()
+		strms.closeAll()
+	}()
+}
+
+// ExecResize changes the size of the pseudo TTY of a running command
+// execution.
+func (e *Engine) ExecResize(ctx context.Context, execID string, options client.ExecResizeOptions) (client.ExecResizeResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ExecResize"); err != nil {
+		return client.ExecResizeResult{}, err
+	}
+	ex := e.exec(execID)
+	if ex == nil {
+		return client.ExecResizeResult{}, notFound("No such exec instance: %s", execID)
+	}
+	if !ex.running {
+		return client.ExecResizeResult{}, conflict("exec %s is not running", ex.id)
+	}
+	ex.consoleSize = client.ConsoleSize{Height: options.Height, Width: options.Width}
+	return client.ExecResizeResult{}, nil
+}
+
+// ExecInspect returns the details of a command execution.
+func (e *Engine) ExecInspect(ctx context.Context, execID string, options client.ExecInspectOptions) (client.ExecInspectResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ExecInspect"); err != nil {
+		return client.ExecInspectResult{}, err
+	}
+	ex := e.exec(execID)
+	if ex == nil {
+		return client.ExecInspectResult{}, notFound("No such exec instance: %s", execID)
+	}
+	return client.ExecInspectResult{
+		ID:          ex.id,
+		ContainerID: ex.cntr.id,
+		Running:     ex.running,
+		ExitCode:    ex.exitCode,
+		PID:         ex.pid,
+	}, nil
+}
@@ -0,0 +1,451 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/api/types/jsonstream"
+	"github.com/moby/moby/api/types/registry"
+	"github.com/moby/moby/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/thediveo/morbyd/v2/internal/jsonmsgs"
+)
+
+// Image describes an image to be made known to a fake engine using
+// [Engine.AddImage] or [Engine.AddRemoteImage].
+type Image struct {
+	Ref          string            // image reference, such as “busybox:latest”.
+	Entrypoint   []string          // default entrypoint.
+	Cmd          []string          // default command.
+	Env          []string          // default environment variables.
+	Labels       map[string]string // image labels.
+	ExposedPorts []string          // exposed ports in “port/proto” format.
+	User         string            // default user.
+	WorkingDir   string            // default working directory.
+}
+
+// fakeImage is an image stored locally in the fake engine.
+type fakeImage struct {
+	id      string
+	tags    []string // normalized references.
+	digest  string
+	config  Image
+	created time.Time
+	history []string // Dockerfile instructions, if built.
+}
+
+// normalizeRef returns the normalized, familiar form of the specified image
+// reference, always including a tag unless the reference is digested.
+func normalizeRef(ref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", invalid("invalid reference format: %s", err.Error())
+	}
+	return reference.FamiliarString(reference.TagNameOnly(named)), nil
+}
+
+// AddImage adds the specified image to the locally available images of the
+// fake engine, returning the image ID. If the image reference is already in
+// use by another image, the reference is moved to the new image.
+func (e *Engine) AddImage(img Image) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.addImage(img, nil).id
+}
+
+// AddRemoteImage makes the specified image available for pulling from a
+// (fake) registry.
+func (e *Engine) AddRemoteImage(img Image) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ref, err := normalizeRef(img.Ref)
+	if err != nil {
+		panic(err)
+	}
+	e.remotes[ref] = img
+}
+
+// addImage adds a new image, moving any tags from existing images over to the
+// new image. addImage must be called with the engine lock held.
+func (e *Engine) addImage(img Image, history []string) *fakeImage {
+	fimg := &fakeImage{
+		id:      "sha256:" + newID(),
+		digest:  "sha256:" + newID(),
+		config:  img,
+		created: time.Now(),
+		history: history,
+	}
+	if img.Ref != "" {
+		ref, err := normalizeRef(img.Ref)
+		if err != nil {
+			panic(err)
+		}
+		e.untag(ref)
+		fimg.tags = []string{ref}
+	}
+	e.images[fimg.id] = fimg
+	return fimg
+}
+
+// untag removes the specified (normalized) reference from any image.
+func (e *Engine) untag(ref string) {
+	for _, img := range e.images {
+		img.tags = slices.DeleteFunc(img.tags, func(tag string) bool { return tag == ref })
+	}
+}
+
+// image returns the locally available image with the specified reference or
+// ID, or nil. image must be called with the engine lock held.
+func (e *Engine) image(refID string) *fakeImage {
+	if img, ok := e.images[refID]; ok {
+		return img
+	}
+	if img, ok := e.images["sha256:"+refID]; ok {
+		return img
+	}
+	if ref, err := normalizeRef(refID); err == nil {
+		for _, img := range e.images {
+			if slices.Contains(img.tags, ref) {
+				return img
+			}
+		}
+	}
+	img, ok := resolve(e.images, "sha256:"+refID, func(*fakeImage) []string { return nil })
+	if ok && len(refID) >= 4 {
+		return img
+	}
+	return nil
+}
+
+// ImageInspect returns the details of a locally available image.
+func (e *Engine) ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (client.ImageInspectResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImageInspect"); err != nil {
+		return client.ImageInspectResult{}, err
+	}
+	img := e.image(imageID)
+	if img == nil {
+		return client.ImageInspectResult{}, notFound("No such image: %s", imageID)
+	}
+	return client.ImageInspectResult{InspectResponse: img.inspect()}, nil
+}
+
+// ImageHistory returns the history of an image, with one entry per Dockerfile
+// instruction for images built by the fake engine, followed by the entry of the
+// image's single fake layer.
+func (e *Engine) ImageHistory(ctx context.Context, imageID string, historyOpts ...client.ImageHistoryOption) (client.ImageHistoryResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImageHistory"); err != nil {
+		return client.ImageHistoryResult{}, err
+	}
+	img := e.image(imageID)
+	if img == nil {
+		return client.ImageHistoryResult{}, notFound("No such image: %s", imageID)
+	}
+	items := make([]image.HistoryResponseItem, 0, len(img.history)+1)
+	for idx := len(img.history) - 1; idx >= 0; idx-- {
+		item := image.HistoryResponseItem{
+			ID:        "<missing>",
+			Created:   img.created.Unix(),
+			CreatedBy: img.history[idx],
+			Tags:      []string{},
+		}
+		if idx == len(img.history)-1 {
+			item.ID = img.id
+			item.Tags = slices.Clone(img.tags)
+		}
+		items = append(items, item)
+	}
+	base := image.HistoryResponseItem{
+		ID:        "<missing>",
+		Created:   img.created.Unix(),
+		CreatedBy: "/bin/sh -c #(nop) ADD file:" + img.digest[len("sha256:"):] + " in / ",
+		Size:      int64(len(img.id)) * 4096,
+		Tags:      []string{},
+	}
+	if len(items) == 0 {
+		base.ID = img.id
+		base.Tags = slices.Clone(img.tags)
+	}
+	items = append(items, base)
+	return client.ImageHistoryResult{Items: items}, nil
+}
+
+func (img *fakeImage) inspect() image.InspectResponse {
+	exposed := map[string]struct{}{}
+	for _, port := range img.config.ExposedPorts {
+		exposed[port] = struct{}{}
+	}
+	return image.InspectResponse{
+		ID:          img.id,
+		RepoTags:    slices.Clone(img.tags),
+		RepoDigests: img.repoDigests(),
+		Created:     img.created.Format(time.RFC3339Nano),
+		Config: &dockerspec.DockerOCIImageConfig{
+			ImageConfig: ocispec.ImageConfig{
+				User:         img.config.User,
+				ExposedPorts: exposed,
+				Env:          slices.Clone(img.config.Env),
+				Entrypoint:   slices.Clone(img.config.Entrypoint),
+				Cmd:          slices.Clone(img.config.Cmd),
+				WorkingDir:   img.config.WorkingDir,
+				Labels:       maps.Clone(img.config.Labels),
+			},
+		},
+		Architecture: "amd64",
+		Os:           "linux",
+		Size:         int64(len(img.id)) * 4096,
+		RootFS: image.RootFS{
+			Type:   "layers",
+			Layers: []string{img.digest},
+		},
+	}
+}
+
+func (img *fakeImage) repoDigests() []string {
+	digests := []string{}
+	for _, tag := range img.tags {
+		name, _, _ := strings.Cut(tag, ":")
+		digests = append(digests, name+"@"+img.digest)
+	}
+	return digests
+}
+
+// ImageList lists the locally available images, optionally filtered by
+// “reference” and “label”.
+func (e *Engine) ImageList(ctx context.Context, options client.ImageListOptions) (client.ImageListResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImageList"); err != nil {
+		return client.ImageListResult{}, err
+	}
+	items := []image.Summary{}
+	for _, img := range e.images {
+		if !matchesLabelFilters(options.Filters, img.config.Labels) ||
+			!matchesAny(options.Filters, "reference", func(ref string) bool {
+				ref, err := normalizeRef(ref)
+				return err == nil && slices.Contains(img.tags, ref)
+			}) {
+			continue
+		}
+		items = append(items, image.Summary{
+			ID:          img.id,
+			Created:     img.created.Unix(),
+			Labels:      maps.Clone(img.config.Labels),
+			RepoTags:    slices.Clone(img.tags),
+			RepoDigests: img.repoDigests(),
+			Containers:  int64(e.imageUsers(img.id)),
+		})
+	}
+	return client.ImageListResult{Items: items}, nil
+}
+
+// imageUsers returns the number of containers using the specified image.
+func (e *Engine) imageUsers(id string) int {
+	count := 0
+	for _, cntr := range e.containers {
+		if cntr.imageID == id {
+			count++
+		}
+	}
+	return count
+}
+
+// ImagePull pulls an image from the fake registry; see also
+// [Engine.AddRemoteImage].
+func (e *Engine) ImagePull(ctx context.Context, refStr string, options client.ImagePullOptions) (client.ImagePullResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImagePull"); err != nil {
+		return nil, err
+	}
+	ref, err := normalizeRef(refStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.authorized(refStr, options.RegistryAuth); err != nil {
+		return nil, err
+	}
+	remote, ok := e.remotes[ref]
+	if !ok {
+		return nil, notFound("pull access denied for %s, repository does not exist or may require 'docker login'", refStr)
+	}
+	name, tag, _ := strings.Cut(ref, ":")
+	msgs := []jsonstream.Message{{Status: "Pulling from " + name, ID: tag}}
+	if img := e.image(ref); img != nil {
+		msgs = append(msgs,
+			jsonstream.Message{Status: "Digest: " + img.digest},
+			jsonstream.Message{Status: "Status: Image is up to date for " + ref})
+		return messageStream(msgs), nil
+	}
+	img := e.addImage(remote, nil)
+	img.tags = []string{ref}
+	layer := img.digest[len("sha256:") : len("sha256:")+12]
+	msgs = append(msgs,
+		jsonstream.Message{Status: "Pulling fs layer", ID: layer},
+		jsonstream.Message{Status: "Download complete", ID: layer},
+		jsonstream.Message{Status: "Pull complete", ID: layer},
+		jsonstream.Message{Status: "Digest: " + img.digest},
+		jsonstream.Message{Status: "Status: Downloaded newer image for " + ref})
+	return messageStream(msgs), nil
+}
+
+// RequireRegistryAuth makes the fake registry with the specified host, such as
+// “registry.example.com:5000” or “docker.io”, require the specified
+// credentials for pulling and pushing images.
+func (e *Engine) RequireRegistryAuth(host, username, password string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.auths[host] = registry.AuthConfig{Username: username, Password: password}
+}
+
+// authorized returns nil if the specified base64 encoded registry credentials
+// are acceptable for the registry of the specified image reference, otherwise
+// an error.
+func (e *Engine) authorized(imageRef string, encodedAuth string) error {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return invalid("invalid reference format: %s", err.Error())
+	}
+	host := reference.Domain(named)
+	required, ok := e.auths[host]
+	if !ok {
+		return nil
+	}
+	var auth registry.AuthConfig
+	if b, err := base64.URLEncoding.DecodeString(encodedAuth); err == nil {
+		_ = json.Unmarshal(b, &auth)
+	}
+	if auth.Username != required.Username || auth.Password != required.Password {
+		return errdefs.ErrUnauthenticated.WithMessage(
+			"unauthorized: authentication required for registry " + host)
+	}
+	return nil
+}
+
+// ImagePush pushes a locally available image to the fake registry, making it
+// available for pulling.
+func (e *Engine) ImagePush(ctx context.Context, imageRef string, options client.ImagePushOptions) (client.ImagePushResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImagePush"); err != nil {
+		return nil, err
+	}
+	ref, err := normalizeRef(imageRef)
+	if err != nil {
+		return nil, err
+	}
+	img := e.image(ref)
+	if img == nil {
+		return nil, notFound("An image does not exist locally with the tag: %s", imageRef)
+	}
+	if err := e.authorized(imageRef, options.RegistryAuth); err != nil {
+		return nil, err
+	}
+	remote := img.config
+	remote.Ref = ref
+	e.remotes[ref] = remote
+	_, tag, _ := strings.Cut(ref, ":")
+	layer := img.digest[len("sha256:") : len("sha256:")+12]
+	return messageStream([]jsonstream.Message{
+		{Status: "The push refers to repository [" + ref + "]"},
+		{Status: "Pushed", ID: layer},
+		{Status: tag + ": digest: " + img.digest},
+	}), nil
+}
+
+// ImageTag adds a tag to a locally available image.
+func (e *Engine) ImageTag(ctx context.Context, options client.ImageTagOptions) (client.ImageTagResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImageTag"); err != nil {
+		return client.ImageTagResult{}, err
+	}
+	img := e.image(options.Source)
+	if img == nil {
+		return client.ImageTagResult{}, notFound("No such image: %s", options.Source)
+	}
+	ref, err := normalizeRef(options.Target)
+	if err != nil {
+		return client.ImageTagResult{}, err
+	}
+	e.untag(ref)
+	img.tags = append(img.tags, ref)
+	return client.ImageTagResult{}, nil
+}
+
+// ImageRemove removes an image tag and, when the last tag is gone or the image
+// is referenced by ID, the image itself.
+func (e *Engine) ImageRemove(ctx context.Context, imageID string, options client.ImageRemoveOptions) (client.ImageRemoveResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("ImageRemove"); err != nil {
+		return client.ImageRemoveResult{}, err
+	}
+	img := e.image(imageID)
+	if img == nil {
+		return client.ImageRemoveResult{}, notFound("No such image: %s", imageID)
+	}
+	items := []image.DeleteResponse{}
+	if ref, err := normalizeRef(imageID); err == nil && slices.Contains(img.tags, ref) {
+		img.tags = slices.DeleteFunc(img.tags, func(tag string) bool { return tag == ref })
+		items = append(items, image.DeleteResponse{Untagged: ref})
+		if len(img.tags) > 0 {
+			return client.ImageRemoveResult{Items: items}, nil
+		}
+	} else if len(img.tags) > 1 && !options.Force {
+		return client.ImageRemoveResult{}, conflict(
+			"unable to delete %s (must be forced) - image is referenced in multiple repositories",
+			img.id[len("sha256:"):len("sha256:")+12])
+	}
+	if !options.Force && e.imageUsers(img.id) > 0 {
+		return client.ImageRemoveResult{}, conflict(
+			"unable to delete %s (must be forced) - image is being used by a container",
+			img.id[len("sha256:"):len("sha256:")+12])
+	}
+	for _, tag := range img.tags {
+		items = append(items, image.DeleteResponse{Untagged: tag})
+	}
+	delete(e.images, img.id)
+	items = append(items, image.DeleteResponse{Deleted: img.id})
+	return client.ImageRemoveResult{Items: items}, nil
+}
+
+// messageStream returns a JSON message stream response with the specified
+// messages.
+func messageStream(msgs []jsonstream.Message) *jsonmsgs.Streamer {
+	var buff bytes.Buffer
+	enc := json.NewEncoder(&buff)
+	for _, msg := range msgs {
+		_ = enc.Encode(msg)
+	}
+	return jsonmsgs.New(io.NopCloser(&buff))
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/client"
+)
+
+// fakeNetwork is a network known to the fake engine.
+type fakeNetwork struct {
+	network.Network
+	predefined bool
+	nextHost   netip.Addr // next IP address to hand out.
+}
+
+// addPredefinedNetworks adds Docker's predefined “bridge”, “host”, and “none”
+// networks.
+func (e *Engine) addPredefinedNetworks() {
+	for _, predef := range []struct {
+		name   string
+		driver string
+		subnet string
+	}{
+		{name: "bridge", driver: "bridge", subnet: "172.17.0.0/16"},
+		{name: "host", driver: "host"},
+		{name: "none", driver: "null"},
+	} {
+		netw := &fakeNetwork{
+			Network: network.Network{
+				Name:       predef.name,
+				ID:         newID(),
+				Created:    time.Now(),
+				Scope:      "local",
+				Driver:     predef.driver,
+				EnableIPv4: predef.subnet != "",
+				Options:    map[string]string{},
+				Labels:     map[string]string{},
+			},
+			predefined: true,
+		}
+		if predef.subnet != "" {
+			setSubnet(netw, netip.MustParsePrefix(predef.subnet))
+		}
+		e.networks[netw.ID] = netw
+	}
+}
+
+// setSubnet configures the IPAM subnet with gateway of a network.
+func setSubnet(netw *fakeNetwork, subnet netip.Prefix) {
+	gw := subnet.Masked().Addr().Next()
+	netw.IPAM = network.IPAM{
+		Driver: "default",
+		Config: []network.IPAMConfig{{Subnet: subnet.Masked(), Gateway: gw}},
+	}
+	netw.nextHost = gw.Next()
+}
+
+// network returns the network with the specified name, ID, or unique ID
+// prefix, or nil. network must be called with the engine lock held.
+func (e *Engine) network(nameID string) *fakeNetwork {
+	netw, ok := resolve(e.networks, nameID, func(n *fakeNetwork) []string { return []string{n.Name} })
+	if !ok {
+		return nil
+	}
+	return netw
+}
+
+// NetworkCreate creates a new network, unless the name is already taken.
+func (e *Engine) NetworkCreate(ctx context.Context, name string, options client.NetworkCreateOptions) (client.NetworkCreateResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("NetworkCreate"); err != nil {
+		return client.NetworkCreateResult{}, err
+	}
+	if name == "" {
+		return client.NetworkCreateResult{}, invalid("network name must not be empty")
+	}
+	for _, netw := range e.networks {
+		if netw.Name == name {
+			return client.NetworkCreateResult{}, conflict("network with name %s already exists", name)
+		}
+	}
+	driver := options.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+	netw := &fakeNetwork{
+		Network: network.Network{
+			Name:       name,
+			ID:         newID(),
+			Created:    time.Now(),
+			Scope:      "local",
+			Driver:     driver,
+			EnableIPv4: options.EnableIPv4 == nil || *options.EnableIPv4,
+			EnableIPv6: options.EnableIPv6 != nil && *options.EnableIPv6,
+			Internal:   options.Internal,
+			Attachable: options.Attachable,
+			Options:    maps.Clone(options.Options),
+			Labels:     maps.Clone(options.Labels),
+		},
+	}
+	if netw.Options == nil {
+		netw.Options = map[string]string{}
+	}
+	if netw.Labels == nil {
+		netw.Labels = map[string]string{}
+	}
+	subnet := netip.MustParsePrefix(fmt.Sprintf("172.%d.0.0/16", e.nextSubnet))
+	if options.IPAM != nil && len(options.IPAM.Config) > 0 && options.IPAM.Config[0].Subnet.IsValid() {
+		subnet = options.IPAM.Config[0].Subnet
+	} else {
+		e.nextSubnet++
+	}
+	setSubnet(netw, subnet)
+	if options.IPAM != nil && len(options.IPAM.Config) > 0 && options.IPAM.Config[0].Gateway.IsValid() {
+		netw.IPAM.Config[0].Gateway = options.IPAM.Config[0].Gateway
+	}
+	e.networks[netw.ID] = netw
+	return client.NetworkCreateResult{ID: netw.ID}, nil
+}
+
+// NetworkInspect returns the details of a network, including the containers
+// attached to it.
+func (e *Engine) NetworkInspect(ctx context.Context, networkID string, options client.NetworkInspectOptions) (client.NetworkInspectResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("NetworkInspect"); err != nil {
+		return client.NetworkInspectResult{}, err
+	}
+	netw := e.network(networkID)
+	if netw == nil {
+		return client.NetworkInspectResult{}, notFound("network %s not found", networkID)
+	}
+	details := network.Inspect{
+		Network:    netw.Network,
+		Containers: map[string]network.EndpointResource{},
+	}
+	details.Options = maps.Clone(netw.Options)
+	details.Labels = maps.Clone(netw.Labels)
+	for _, cntr := range e.containers {
+		ep, ok := cntr.networks[netw.Name]
+		if !ok {
+			continue
+		}
+		res := network.EndpointResource{
+			Name:       cntr.name,
+			EndpointID: ep.EndpointID,
+			MacAddress: ep.MacAddress,
+		}
+		if ep.IPAddress.IsValid() {
+			res.IPv4Address = netip.PrefixFrom(ep.IPAddress, ep.IPPrefixLen)
+		}
+		details.Containers[cntr.id] = res
+	}
+	return client.NetworkInspectResult{Network: details}, nil
+}
+
+// NetworkList lists networks, optionally filtered by “label”, “name”, “id”,
+// and “driver”.
+func (e *Engine) NetworkList(ctx context.Context, options client.NetworkListOptions) (client.NetworkListResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("NetworkList"); err != nil {
+		return client.NetworkListResult{}, err
+	}
+	items := []network.Summary{}
+	for _, netw := range e.networks {
+		if !matchesLabelFilters(options.Filters, netw.Labels) ||
+			!matchesAny(options.Filters, "name", func(name string) bool { return strings.Contains(netw.Name, name) }) ||
+			!matchesAny(options.Filters, "id", func(id string) bool { return strings.HasPrefix(netw.ID, id) }) ||
+			!matchesAny(options.Filters, "driver", func(driver string) bool { return netw.Driver == driver }) {
+			continue
+		}
+		summary := network.Summary{Network: netw.Network}
+		summary.Options = maps.Clone(netw.Options)
+		summary.Labels = maps.Clone(netw.Labels)
+		items = append(items, summary)
+	}
+	return client.NetworkListResult{Items: items}, nil
+}
+
+// NetworkRemove removes a network, unless it is a predefined network or there
+// are still containers attached to it.
+func (e *Engine) NetworkRemove(ctx context.Context, networkID string, options client.NetworkRemoveOptions) (client.NetworkRemoveResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.injected("NetworkRemove"); err != nil {
+		return client.NetworkRemoveResult{}, err
+	}
+	netw := e.network(networkID)
+	if netw == nil {
+		return client.NetworkRemoveResult{}, notFound("network %s not found", networkID)
+	}
+	if netw.predefined {
+		return client.NetworkRemoveResult{}, errdefs.ErrPermissionDenied.WithMessage(
+			netw.Name + " is a pre-defined network and cannot be removed")
+	}
+	for _, cntr := range e.containers {
+		if _, ok := cntr.networks[netw.Name]; ok {
+			return client.NetworkRemoveResult{}, conflict(
+				"error while removing network: network %s has active endpoints", netw.Name)
+		}
+	}
+	delete(e.networks, netw.ID)
+	return client.NetworkRemoveResult{}, nil
+}
+
+// connect a container to a network, allocating an IP address if the network
+// has an IPv4 subnet and no specific IP address has been requested. connect
+// must be called with the engine lock held.
+func (e *Engine) connect(cntr *fakeContainer, netw *fakeNetwork, ep *network.EndpointSettings) {
+	if ep == nil {
+		ep = &network.EndpointSettings{}
+	} else {
+		ep = ep.Copy()
+	}
+	ep.NetworkID = netw.ID
+	ep.EndpointID = newID()
+	if len(netw.IPAM.Config) > 0 && netw.EnableIPv4 {
+		cfg := netw.IPAM.Config[0]
+		ep.Gateway = cfg.Gateway
+		ep.IPPrefixLen = cfg.Subnet.Bits()
+		if ep.IPAMConfig != nil && ep.IPAMConfig.IPv4Address.IsValid() {
+			ep.IPAddress = ep.IPAMConfig.IPv4Address
+		} else if !ep.IPAddress.IsValid() {
+			if netw.nextHost == cfg.Gateway {
+				netw.nextHost = netw.nextHost.Next()
+			}
+			ep.IPAddress = netw.nextHost
+			netw.nextHost = netw.nextHost.Next()
+		}
+		mac := ep.IPAddress.As4()
+		ep.MacAddress = network.HardwareAddr{0x02, 0x42, mac[0], mac[1], mac[2], mac[3]}
+	}
+	cntr.networks[netw.Name] = ep
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeengine
+
+import (
+	"context"
+	"io"
+)
+
+// Program simulates a process inside a container or an executed command. The
+// passed context gets cancelled when the container is stopped, killed, or
+// forcefully removed; programs must then return in a timely manner. The exit
+// code returned by a Program becomes the exit code of the container or
+// executed command.
+type Program func(ctx context.Context, proc *Process) (exitcode int)
+
+// Process describes the simulated process a [Program] is running as.
+type Process struct {
+	ContainerID string    // ID of the container the process runs in.
+	Args        []string  // command and arguments.
+	Env         []string  // environment variables in “key=value” format.
+	User        string    // user (and optional group) the process runs as.
+	WorkingDir  string    // working directory.
+	TTY         bool      // true if a pseudo TTY has been allocated.
+	PID         int       // fake PID of the process.
+	Stdin       io.Reader // always non-nil, returns EOF when not attached.
+	Stdout      io.Writer // always non-nil.
+	Stderr      io.Writer // always non-nil; same as Stdout when using a TTY.
+
+	// ConsoleSize returns the current width and height of the pseudo TTY, as
+	// initially configured and later changed by resizing.
+	ConsoleSize func() (width, height uint)
+}
+
+// Idle is a Program that does nothing except waiting for being stopped, then
+// returning exit code 143, as a process terminated by SIGTERM would do.
+func Idle(ctx context.Context, proc *Process) int {
+	<-ctx.Done()
+	return 128 + 15
+}
+
+// Exit returns a Program that immediately exits with the specified exit code.
+func Exit(code int) Program {
+	return func(context.Context, *Process) int { return code }
+}
+
+// Echo returns a Program that writes the specified text to its stdout and then
+// exits with the specified exit code.
+func Echo(text string, code int) Program {
+	return func(ctx context.Context, proc *Process) int {
+		_, _ = io.WriteString(proc.Stdout, text)
+		return code
+	}
+}
+
+// Cat is a Program that copies its stdin to its stdout until it reaches EOF on
+// stdin or gets stopped.
+func Cat(ctx context.Context, proc *Process) int {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(proc.Stdout, proc.Stdin)
+	}()
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+		return 128 + 15
+	}
+}
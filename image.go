@@ -0,0 +1,239 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/thediveo/morbyd/v2/push"
+	"github.com/thediveo/morbyd/v2/remove"
+	"github.com/thediveo/morbyd/v2/run"
+)
+
+// Image represents a locally available container image, providing typed
+// accessors to its inspection details, as well as notable operations specific
+// to it:
+//
+//   - [Image.History] to retrieve the history of the image's layers.
+//   - [Image.Tag] to tag the image with another reference.
+//   - [Image.Push] to push the image to a registry.
+//   - [Image.Run] to run a new container from the image.
+//   - [Image.Remove] to remove the image.
+type Image struct {
+	Ref     string // image reference used to look up this image.
+	Session *Session
+	Details client.ImageInspectResult
+}
+
+// ImageHistoryItem is a single entry of an image's history, in reverse
+// chronological order, that is, starting with the most recent entry.
+type ImageHistoryItem struct {
+	ID        string    // image ID, or "<missing>" for entries of intermediate layers.
+	Created   time.Time // when this entry was created.
+	CreatedBy string    // the command creating this entry, such as a Dockerfile instruction.
+	Comment   string    // optional comment.
+	Size      int64     // size of the layer created by this entry in bytes.
+	Tags      []string  // tags of the image at this entry, if any.
+}
+
+// Image returns the locally available image referenced by imageref, which can
+// be an image name with optional tag or digest, or an image ID. If there is no
+// such image, Image returns an error.
+func (s *Session) Image(ctx context.Context, imageref string) (*Image, error) {
+	details, err := s.moby.ImageInspect(ctx, imageref)
+	if err != nil {
+		return nil, fmt.Errorf("cannot inspect image %s, reason: %w", imageref, err)
+	}
+	return &Image{
+		Ref:     imageref,
+		Session: s,
+		Details: details,
+	}, nil
+}
+
+// ID returns the image ID, such as “sha256:...”.
+func (i *Image) ID() string { return i.Details.ID }
+
+// RepoDigests returns the repository digests of this image, such as
+// “busybox@sha256:...”.
+func (i *Image) RepoDigests() []string { return slices.Clone(i.Details.RepoDigests) }
+
+// Tags returns the repository tags of this image, such as “busybox:latest”.
+func (i *Image) Tags() []string { return slices.Clone(i.Details.RepoTags) }
+
+// Created returns when this image was created, or the zero time if unknown.
+func (i *Image) Created() time.Time {
+	created, _ := time.Parse(time.RFC3339Nano, i.Details.Created)
+	return created
+}
+
+// Platform returns the platform of this image.
+func (i *Image) Platform() ocispec.Platform {
+	return ocispec.Platform{
+		Architecture: i.Details.Architecture,
+		OS:           i.Details.Os,
+		OSVersion:    i.Details.OsVersion,
+		Variant:      i.Details.Variant,
+	}
+}
+
+// Size returns the total size of this image in bytes.
+func (i *Image) Size() int64 { return i.Details.Size }
+
+// Env returns the default environment variables of this image in “key=value”
+// format.
+func (i *Image) Env() []string {
+	if i.Details.Config == nil {
+		return nil
+	}
+	return slices.Clone(i.Details.Config.Env)
+}
+
+// Cmd returns the default command of this image.
+func (i *Image) Cmd() []string {
+	if i.Details.Config == nil {
+		return nil
+	}
+	return slices.Clone(i.Details.Config.Cmd)
+}
+
+// Entrypoint returns the entrypoint of this image.
+func (i *Image) Entrypoint() []string {
+	if i.Details.Config == nil {
+		return nil
+	}
+	return slices.Clone(i.Details.Config.Entrypoint)
+}
+
+// ExposedPorts returns the sorted ports exposed by this image in “port/proto”
+// format, such as “80/tcp”.
+func (i *Image) ExposedPorts() []string {
+	if i.Details.Config == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(i.Details.Config.ExposedPorts))
+}
+
+// Labels returns the labels of this image.
+func (i *Image) Labels() map[string]string {
+	if i.Details.Config == nil {
+		return nil
+	}
+	return maps.Clone(i.Details.Config.Labels)
+}
+
+// User returns the default user (and optional group) of this image.
+func (i *Image) User() string {
+	if i.Details.Config == nil {
+		return ""
+	}
+	return i.Details.Config.User
+}
+
+// WorkingDir returns the default working directory of this image.
+func (i *Image) WorkingDir() string {
+	if i.Details.Config == nil {
+		return ""
+	}
+	return i.Details.Config.WorkingDir
+}
+
+// Layers returns the digests of the layers of this image, starting with the
+// base layer.
+func (i *Image) Layers() []string { return slices.Clone(i.Details.RootFS.Layers) }
+
+// History returns the history of this image, starting with the most recent
+// entry.
+func (i *Image) History(ctx context.Context) ([]ImageHistoryItem, error) {
+	hist, err := i.Session.moby.ImageHistory(ctx, i.ID())
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve history of image %s, reason: %w", i.Ref, err)
+	}
+	items := make([]ImageHistoryItem, 0, len(hist.Items))
+	for _, item := range hist.Items {
+		items = append(items, ImageHistoryItem{
+			ID:        item.ID,
+			Created:   time.Unix(item.Created, 0),
+			CreatedBy: item.CreatedBy,
+			Comment:   item.Comment,
+			Size:      item.Size,
+			Tags:      item.Tags,
+		})
+	}
+	return items, nil
+}
+
+// Refresh the details about this image, or return an error in case refreshing
+// fails.
+func (i *Image) Refresh(ctx context.Context) error {
+	details, err := i.Session.moby.ImageInspect(ctx, i.ID())
+	if err != nil {
+		return fmt.Errorf("cannot refresh image %s details, reason: %w", i.Ref, err)
+	}
+	i.Details = details
+	return nil
+}
+
+// Tag tags this image with the specified target reference.
+func (i *Image) Tag(ctx context.Context, target string) error {
+	return i.Session.TagImage(ctx, i.ID(), target)
+}
+
+// Remove removes this image. As the image might be tagged multiple times, use
+// [remove.WithForce] to remove the image including all its tags.
+func (i *Image) Remove(ctx context.Context, opts ...remove.Opt) error {
+	_, err := i.Session.RemoveImage(ctx, i.ID(), opts...)
+	return err
+}
+
+// Push pushes this image to a container registry, using the reference this
+// image was looked up with. Push fails for images looked up by their IDs, as
+// these cannot be pushed; use [Image.Tag] and push the tagged image instead.
+func (i *Image) Push(ctx context.Context, opts ...push.Opt) error {
+	if !i.named() {
+		return fmt.Errorf("cannot push image %s, reason: %w", i.Ref,
+			errdefs.ErrInvalidArgument.WithMessage("image looked up by ID instead of name"))
+	}
+	return i.Session.PushImage(ctx, i.Ref, opts...)
+}
+
+// Run (create and start) a new container from this image; see [Session.Run]
+// for details. The new container references this image by the name it was
+// looked up with, unless the image was looked up by its ID.
+func (i *Image) Run(ctx context.Context, opts ...run.Opt) (*Container, error) {
+	imageref := i.ID()
+	if i.named() {
+		imageref = i.Ref
+	}
+	return i.Session.run(ctx, imageref, caller(1, 0), opts...)
+}
+
+// named returns true if this image was looked up by a name with optional tag
+// or digest, and false if it was looked up by its (possibly abbreviated) ID.
+func (i *Image) named() bool {
+	id := i.ID()
+	return i.Ref != "" &&
+		!strings.HasPrefix(id, i.Ref) &&
+		!strings.HasPrefix(strings.TrimPrefix(id, "sha256:"), i.Ref)
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/moby"
+	"github.com/thediveo/morbyd/v2/pull"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+// fakeCredentialHelper is a fake Docker credential helper that knows only
+// about registry.example.com.
+const fakeCredentialHelper = `#!/bin/sh
+case "$1" in
+get)
+	read server
+	if [ "$server" = "registry.example.com" ]; then
+		printf '{"ServerURL":"%s","Username":"morbyd","Secret":"s3cr3t"}' "$server"
+		exit 0
+	fi
+	echo "credentials not found in native keychain"
+	exit 1
+	;;
+list)
+	printf '{"registry.example.com":"morbyd"}'
+	;;
+*)
+	exit 1
+	;;
+esac
+`
+
+// buildSnooper wraps a fake engine in order to snoop on the image build
+// options.
+type buildSnooper struct {
+	*fakeengine.Engine
+	options client.ImageBuildOptions
+}
+
+func (b *buildSnooper) ImageBuild(ctx context.Context, buildContext io.Reader, options client.ImageBuildOptions) (client.ImageBuildResult, error) {
+	b.options = options
+	return b.Engine.ImageBuild(ctx, buildContext, options)
+}
+
+var _ = Describe("registry credentials from the Docker client configuration", func() {
+
+	var engine *fakeengine.Engine
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		tmpdir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(tmpdir, "docker-credential-morbydfake"),
+			[]byte(fakeCredentialHelper), 0o755)).To(Succeed())
+		GinkgoT().Setenv("PATH", tmpdir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		Expect(os.WriteFile(filepath.Join(tmpdir, "config.json"),
+			[]byte(`{"credHelpers": {"registry.example.com": "morbydfake"}}`), 0o600)).To(Succeed())
+		GinkgoT().Setenv("DOCKER_CONFIG", tmpdir)
+
+		engine = fakeengine.New()
+		engine.AddRemoteImage(fakeengine.Image{Ref: "registry.example.com/busybox", Cmd: []string{"sh"}})
+		engine.RequireRegistryAuth("registry.example.com", "morbyd", "s3cr3t")
+	})
+
+	It("pulls using credentials from a credential helper", func(ctx context.Context) {
+		sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+		defer sess.Close(ctx)
+
+		Expect(sess.PullImage(ctx, "registry.example.com/busybox")).Error().To(
+			MatchError(errdefs.IsUnauthorized, "IsUnauthorized"))
+		Expect(sess.PullImage(ctx, "registry.example.com/busybox",
+			pull.WithRegistryAuth("bm90LWpzb24="), pull.WithAuthFromConfig())).Error().To(
+			MatchError(errdefs.IsUnauthorized, "IsUnauthorized"))
+		Expect(sess.PullImage(ctx, "registry.example.com/busybox", pull.WithAuthFromConfig())).Error().NotTo(HaveOccurred())
+	})
+
+	It("pulls, runs, and pushes using session-wide credentials", func(ctx context.Context) {
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithRegistryAuthFromDockerConfig()))
+		defer sess.Close(ctx)
+
+		cntr := Successful(sess.Run(ctx, "registry.example.com/busybox"))
+		cntr.Kill(ctx)
+		Expect(sess.TagImage(ctx, "registry.example.com/busybox", "registry.example.com/bzzz")).To(Succeed())
+		Expect(sess.PushImage(ctx, "registry.example.com/bzzz")).To(Succeed())
+	})
+
+	It("passes credentials to builds", func(ctx context.Context) {
+		snooper := &buildSnooper{Engine: engine}
+		sess := Successful(NewSession(ctx,
+			func(o *session.Options) error {
+				o.Wrapper = func(moby.Client) moby.Client { return snooper }
+				return nil
+			},
+			session.WithRegistryAuthFromDockerConfig()))
+		defer sess.Close(ctx)
+
+		Expect(sess.BuildImageFromFS(ctx, fstest.MapFS{},
+			build.WithDockerfileContent("FROM registry.example.com/busybox\n"))).Error().NotTo(HaveOccurred())
+		Expect(snooper.options.AuthConfigs).To(HaveKeyWithValue("registry.example.com",
+			And(HaveField("Username", "morbyd"), HaveField("Password", "s3cr3t"))))
+	})
+
+	It("reports broken configurations", func(ctx context.Context) {
+		Expect(os.WriteFile(filepath.Join(os.Getenv("DOCKER_CONFIG"), "config.json"),
+			[]byte(`{`), 0o600)).To(Succeed())
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithRegistryAuthFromDockerConfig()))
+		defer sess.Close(ctx)
+
+		Expect(sess.PullImage(ctx, "registry.example.com/busybox")).Error().To(
+			MatchError(ContainSubstring("cannot load Docker client configuration")))
+		Expect(sess.PushImage(ctx, "registry.example.com/busybox")).To(
+			MatchError(ContainSubstring("cannot load Docker client configuration")))
+		Expect(sess.BuildImageFromFS(ctx, fstest.MapFS{},
+			build.WithDockerfileContent("FROM registry.example.com/busybox\n"))).Error().To(
+			MatchError(ContainSubstring("cannot determine registry credentials")))
+	})
+
+})
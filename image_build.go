@@ -17,29 +17,34 @@ package morbyd
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"net"
-	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 
 	bkcontrol "github.com/moby/buildkit/api/services/control"
 	bkclient "github.com/moby/buildkit/client"
 	bksession "github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
 	"github.com/moby/buildkit/util/progress/progressui"
-	"github.com/moby/go-archive"
 	"github.com/moby/moby/api/types/jsonstream"
 	"github.com/moby/moby/client"
 	"github.com/moby/moby/client/pkg/jsonmessage"
-	"github.com/moby/patternmatcher/ignorefile"
+	"github.com/moby/term"
 	"github.com/thediveo/nonstd/xatomic"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/internal/dockerconfig"
 )
 
 // BuildImage builds a container image using the specified build context and
@@ -48,7 +53,11 @@ import (
 // an image.
 //
 // BuildImage returns the ID of the built image, or an error in case of build
-// errors.
+// errors. Use [Session.Build] instead in order to additionally get the
+// warnings and steps of the build.
+//
+// In case a build step fails, BuildImage returns a [*BuildError] with the
+// failed step and the tail of its log output.
 //
 // Unless overridden using a build option, the following defaults apply:
 //   - Dockerfile: "Dockerfile"
@@ -58,12 +67,61 @@ import (
 // If no build process output writer has been specified using [build.WithOutput]
 // any output (such as build steps, et cetera) will simply be discarded.
 //
+// Concurrent builds of the same tags from the same build context directory
+// using identical build options within this process are deduplicated: only
+// one build is actually run, with only its output written and its progress
+// reported, while the other callers wait for its outcome. The build continues
+// even if the context of the caller that started it gets cancelled, as long as
+// other callers are still waiting for its outcome; only when all callers have
+// given up, the build gets cancelled.
+//
 // Note: using buildkit ([build.WithBuildKit]) currently is subject to
 // limitations, most notably, registry authentication is not passed on to
 // buildkit; please see also [API /build doesn't pass AuthConfig to BuildKit].
 //
 // [API /build doesn't pass AuthConfig to BuildKit]: https://github.com/moby/moby/issues/48112
 func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...build.Opt) (id string, err error) {
+	res, err := s.buildImage(ctx, buildctxpath, nil, opts...)
+	if err != nil {
+		return "", err
+	}
+	return res.ImageID, nil
+}
+
+// BuildImageFromFS builds a container image using the specified file system as
+// the build context, such as an [embed.FS] with test fixtures. Apart from the
+// build context, BuildImageFromFS works the same as [Session.BuildImage].
+// Files matching the patterns in a “.dockerignore” file in the root of the file
+// system are excluded from the build context.
+//
+// Use [build.WithDockerfileContent] in order to inject a generated Dockerfile
+// into the build context.
+func (s *Session) BuildImageFromFS(ctx context.Context, fsys fs.FS, opts ...build.Opt) (id string, err error) {
+	res, err := s.buildImage(ctx, "", fsys, opts...)
+	if err != nil {
+		return "", err
+	}
+	return res.ImageID, nil
+}
+
+// Build builds a container image the same as [Session.BuildImage] does, but
+// returns a [BuildResult] with the image ID, the applied tags, as well as the
+// warnings and steps of the build.
+func (s *Session) Build(ctx context.Context, buildctxpath string, opts ...build.Opt) (*BuildResult, error) {
+	return s.buildImage(ctx, buildctxpath, nil, opts...)
+}
+
+// BuildFromFS builds a container image the same as [Session.BuildImageFromFS]
+// does, but returns a [BuildResult] with the image ID, the applied tags, as
+// well as the warnings and steps of the build.
+func (s *Session) BuildFromFS(ctx context.Context, fsys fs.FS, opts ...build.Opt) (*BuildResult, error) {
+	return s.buildImage(ctx, "", fsys, opts...)
+}
+
+// buildImage builds a container image using either the build context
+// directory or the build context file system, with the latter taking
+// precedence if non-nil.
+func (s *Session) buildImage(ctx context.Context, buildctxpath string, fsys fs.FS, opts ...build.Opt) (res *BuildResult, err error) {
 	bios := build.Options{
 		ImageBuildOptions: client.ImageBuildOptions{
 			Dockerfile:  "Dockerfile",
@@ -73,23 +131,92 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 	}
 	for _, opt := range opts {
 		if err := opt(&bios); err != nil {
-			return "", err
+			return nil, err
 		}
 	}
+	buildctxname := buildctxpath
+	if fsys != nil {
+		buildctxname = fmt.Sprintf("%T", fsys)
+	}
+	ctx, op := s.begin(ctx, "image.build",
+		slog.String(AttrBuildContext, buildctxname),
+		slog.String(AttrImage, strings.Join(bios.Tags, ",")),
+		slog.String(AttrCaller, caller(2, 0)))
+	defer func() { op.end(err) }()
+
 	// In case no output writer was set, default to the discarding writer.
 	if bios.Out == nil {
 		bios.Out = io.Discard
 	}
+	if bios.DisplayMode == "" {
+		bios.DisplayMode = build.DisplayAuto
+	}
+	if bios.AuthConfigs == nil && s.opts.RegistryAuthFromDockerConfig {
+		bios.AuthConfigs, err = dockerconfig.AuthConfigs()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine registry credentials, reason: %w", err)
+		}
+	}
+	// Concurrent builds of the same tags from the same build context directory
+	// with the same effective build options are deduplicated; builds from
+	// file systems cannot be told apart and thus always run.
+	if fsys != nil || bios.Context != nil || len(bios.Tags) == 0 {
+		res, err = s.build(ctx, buildctxpath, fsys, &bios)
+	} else {
+		var optshash string
+		optshash, err = buildOptionsHash(&bios)
+		if err != nil {
+			return nil, err
+		}
+		var shared bool
+		res, shared, err = flight(ctx,
+			s.flightKey("build", absPath(buildctxpath), optshash),
+			func(ctx context.Context) (*BuildResult, error) { return s.build(ctx, buildctxpath, nil, &bios) },
+			(*BuildResult).clone)
+		op.set(slog.Bool(AttrShared, shared))
+	}
+	if res != nil {
+		op.set(slog.String(AttrImageID, res.ImageID))
+	}
+	return res, err
+}
+
+// buildOptionsHash returns the hash of the effective build options that
+// influence the built image, that is, all options except for the output and
+// progress reporting.
+func buildOptionsHash(bios *build.Options) (string, error) {
+	effective := struct {
+		client.ImageBuildOptions
+		DockerfileContent string
+		Secrets           []build.Secret
+		SSHAgents         []build.SSHAgent
+	}{
+		ImageBuildOptions: bios.ImageBuildOptions,
+		DockerfileContent: bios.DockerfileContent,
+		Secrets:           bios.Secrets,
+		SSHAgents:         bios.SSHAgents,
+	}
+	b, err := json.Marshal(effective)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash build options, reason: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// build runs the image build as configured in the passed build options, using
+// either the build context directory or the build context file system, with
+// the latter taking precedence if non-nil.
+func (s *Session) build(ctx context.Context, buildctxpath string, fsys fs.FS, bios *build.Options) (*BuildResult, error) {
 	// Tar up the files forming the build context, obeying the rules set down in
-	// a .dockerignore where present.
+	// a .dockerignore where present. In case of an early return we need to
+	// close the tar stream in order to not leak its producing go routine.
 	if bios.Context == nil {
-		buildCtxTar, err := archive.TarWithOptions(buildctxpath,
-			&archive.TarOptions{
-				ExcludePatterns: readIgnorePatterns(filepath.Join(buildctxpath, ".dockerignore")),
-			})
+		buildCtxTar, err := buildContext(buildctxpath, fsys, bios.Dockerfile, bios.DockerfileContent)
 		if err != nil {
-			return "", fmt.Errorf("cannot create build context, reason: %w", err)
+			return nil, fmt.Errorf("cannot create build context, reason: %w", err)
 		}
+		defer buildCtxTar.Close() //nolint:errcheck // any error is irrelevant at this point
 		bios.Context = buildCtxTar
 	}
 
@@ -110,6 +237,10 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 	})
 	defer closeStateCh()
 
+	if bios.Version != "2" && (len(bios.Secrets) > 0 || len(bios.SSHAgents) > 0) {
+		return nil, errors.New("build secrets and SSH agent forwarding require BuildKit")
+	}
+
 	if bios.Version == "2" {
 		// the caller foolishly requests BuildKit and now hell breaks loose. In
 		// order to build non-trivial Dockerfiles using the Docker
@@ -117,9 +248,19 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 		// buildkit session over a hijacked Docker API connection.
 		buildkitSession, err := bksession.NewSession(sessionCtx, "")
 		if err != nil {
-			return "", fmt.Errorf("buildkit session creation failed, reason: %w", err)
+			return nil, fmt.Errorf("buildkit session creation failed, reason: %w", err)
 		}
 		defer func() { _ = buildkitSession.Close() }()
+		// Secret and SSH providers must be registered before the session
+		// starts running, otherwise builds using "RUN --mount=type=secret" or
+		// "RUN --mount=type=ssh" will fail.
+		attachables, err := buildkitAttachables(bios)
+		if err != nil {
+			return nil, fmt.Errorf("buildkit session creation failed, reason: %w", err)
+		}
+		for _, attachable := range attachables {
+			buildkitSession.Allow(attachable)
+		}
 
 		wg.Go(func() error {
 			// Aaaaarghhhhh!!! This is one of those pitch-dark long-running
@@ -159,9 +300,9 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 		// the status change channel. Cancelling the context will instead result
 		// in an error ... which will be ignored by the waitgroup in case
 		// another of its go routines has failed earlier.
-		bkdisplay, err := progressui.NewDisplay(bios.Out, progressui.AutoMode)
+		bkdisplay, err := progressui.NewDisplay(bios.Out, progressui.DisplayMode(bios.DisplayMode))
 		if err != nil {
-			return "", fmt.Errorf("buildkit progress UI display creation failed, reason: %w", err)
+			return nil, fmt.Errorf("buildkit progress UI display creation failed, reason: %w", err)
 		}
 		statech = make(chan *bkclient.SolveStatus, 32)
 
@@ -176,9 +317,12 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 				return err
 			}
 			// If this was a clean return from UpdateFrom, then render the
-			// collected warnings, if any...
-			for _, warning := range warnings {
-				_, _ = bios.Out.Write([]byte(prettyPrintVertexWarning(warning)))
+			// collected warnings, if any, unless the display mode asks us to
+			// keep quiet or the warnings are already part of the raw JSON...
+			if bios.DisplayMode != build.DisplayQuiet && bios.DisplayMode != build.DisplayRawJSON {
+				for _, warning := range warnings {
+					_, _ = bios.Out.Write([]byte(prettyPrintVertexWarning(warning)))
+				}
 			}
 			// ...and cancel our error waitgroup-derived sub context; now, as we
 			// can only be done after the state change channel has been closed
@@ -195,6 +339,9 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 	}
 
 	var idval xatomic.Value[string] // never tickle the race detector
+	// The collector is only used from the go routine below, and only read
+	// after the error waitgroup has finished.
+	collector := newBuildCollector(bios.Progress)
 	wg.Go(func() error {
 		// Now initiate the image build, feeding it our tar(r)ed build context
 		// contents.
@@ -203,8 +350,35 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 			return fmt.Errorf("image build failed, reason: %w", err)
 		}
 		defer func() { _ = resp.Body.Close() }()
-		err = jsonmessage.DisplayStream(resp.Body, bios.Out,
-			jsonmessage.WithAuxCallback(func(auxmsg jsonstream.Message) {
+		// We need to see the individual JSON messages in order to pick up the
+		// classic builder's steps, so we feed the messages to the collector
+		// before they get displayed. In case of the raw JSON display mode with
+		// the classic builder, we pass the messages through as they are.
+		var rawjson *json.Encoder
+		if bios.DisplayMode == build.DisplayRawJSON && bios.Version != "2" {
+			rawjson = json.NewEncoder(bios.Out)
+		}
+		dec := json.NewDecoder(resp.Body)
+		messages := func(yield func(jsonstream.Message, error) bool) {
+			for {
+				var jm jsonstream.Message
+				err := dec.Decode(&jm)
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err == nil {
+					collector.message(jm)
+					if rawjson != nil {
+						_ = rawjson.Encode(jm)
+					}
+				}
+				if !yield(jm, err) {
+					return
+				}
+			}
+		}
+		err = displayMessages(messages, bios.Out, bios.DisplayMode,
+			func(auxmsg jsonstream.Message) {
 				// buildkit messages are rather complex in that they are
 				// protobuf-encoded and transmitted as aux messages with their
 				// dedicated buildkit aux message ID. See also:
@@ -224,7 +398,9 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 					if err := proto.Unmarshal(bkpbmsg, &status); err != nil {
 						return
 					}
-					statech <- bkclient.NewSolveStatus(&status)
+					solvestatus := bkclient.NewSolveStatus(&status)
+					collector.solveStatus(solvestatus)
+					statech <- solvestatus
 					return
 				}
 				// Please note that the image ID is reported using an aux message
@@ -238,29 +414,72 @@ func (s *Session) BuildImage(ctx context.Context, buildctxpath string, opts ...b
 				}
 				// Pick up the image ID when it floats by ... and is non-zero.
 				idval.Store(aux.ID)
-			}))
+				collector.imageID(aux.ID)
+			})
 		closeStateCh()
+		if jerr := (*jsonstream.Error)(nil); errors.As(err, &jerr) {
+			return collector.buildError(err)
+		}
+		if err == nil {
+			collector.done()
+		}
 		return err
 	})
 
-	err = wg.Wait()
-	return idval.Load(), err
+	if err := wg.Wait(); err != nil {
+		return nil, err
+	}
+	return collector.result(idval.Load(), bios.Tags), nil
 }
 
-// readIgnorePatterns reads the file specified by “name” in .dockerignore
-// format, returning the list of file patterns to ignore. In case of any error,
-// it returns nil.
-func readIgnorePatterns(name string) []string {
-	f, err := os.Open(name)
-	if err != nil {
-		return nil
+// displayMessages renders the JSON messages from the image build to the
+// specified output writer according to the display mode, passing aux messages
+// to the specified callback.
+func displayMessages(
+	messages jsonmessage.JSONMessagesStream,
+	out io.Writer,
+	mode build.DisplayMode,
+	auxCallback func(jsonstream.Message),
+) error {
+	switch mode {
+	case build.DisplayQuiet, build.DisplayRawJSON:
+		return jsonmessage.DisplayJSONMessages(messages, io.Discard, 0, false, auxCallback)
+	case build.DisplayPlain:
+		return jsonmessage.DisplayJSONMessages(messages, out, 0, false, auxCallback)
+	case build.DisplayTTY:
+		fd, _ := term.GetFdInfo(out)
+		return jsonmessage.DisplayJSONMessages(messages, out, fd, true, auxCallback)
 	}
-	defer f.Close() //nolint:errcheck // any error is irrelevant at this point
-	patterns, err := ignorefile.ReadAll(f)
-	if err != nil {
-		return nil
+	return jsonmessage.DisplayMessages(messages, out, jsonmessage.WithAuxCallback(auxCallback))
+}
+
+// buildkitAttachables returns the BuildKit session attachables providing the
+// build secrets and SSH agents specified in the build options, if any.
+func buildkitAttachables(bios *build.Options) ([]bksession.Attachable, error) {
+	var attachables []bksession.Attachable
+	if len(bios.Secrets) > 0 {
+		sources := make([]secretsprovider.Source, 0, len(bios.Secrets))
+		for _, secret := range bios.Secrets {
+			sources = append(sources, secretsprovider.Source{ID: secret.ID, FilePath: secret.Source})
+		}
+		store, err := secretsprovider.NewStore(sources)
+		if err != nil {
+			return nil, fmt.Errorf("cannot provide build secrets, reason: %w", err)
+		}
+		attachables = append(attachables, secretsprovider.NewSecretProvider(store))
+	}
+	if len(bios.SSHAgents) > 0 {
+		confs := make([]sshprovider.AgentConfig, 0, len(bios.SSHAgents))
+		for _, agent := range bios.SSHAgents {
+			confs = append(confs, sshprovider.AgentConfig{ID: agent.ID, Paths: agent.Paths})
+		}
+		sshagents, err := sshprovider.NewSSHAgentProvider(confs)
+		if err != nil {
+			return nil, fmt.Errorf("cannot forward SSH agents, reason: %w", err)
+		}
+		attachables = append(attachables, sshagents)
 	}
-	return patterns
+	return attachables, nil
 }
 
 func prettyPrintVertexWarning(warn bkclient.VertexWarning) string {
@@ -0,0 +1,175 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/moby/go-archive"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// buildContext returns a tar stream of the build context, either from the
+// specified file system if non-nil, or otherwise from the specified build
+// context directory. Files matching the patterns in a .dockerignore file in
+// the root of the build context are excluded. If dockerfileContent is not
+// empty, it gets injected into the build context as the file named
+// dockerfile, replacing any existing file of the same name.
+func buildContext(buildctxpath string, fsys fs.FS, dockerfile string, dockerfileContent string) (io.ReadCloser, error) {
+	if fsys == nil {
+		if dockerfileContent == "" {
+			return archive.TarWithOptions(buildctxpath,
+				&archive.TarOptions{
+					ExcludePatterns: readIgnorePatterns(filepath.Join(buildctxpath, ".dockerignore")),
+				})
+		}
+		fsys = os.DirFS(buildctxpath)
+	}
+	return tarFS(fsys, readIgnorePatternsFS(fsys, ".dockerignore"), dockerfile, dockerfileContent)
+}
+
+// tarFS returns a tar stream of the specified file system, excluding the files
+// matching the specified patterns, and optionally injecting the Dockerfile
+// contents. Closing the returned stream before reaching its end terminates the
+// background go routine producing the tar stream.
+func tarFS(fsys fs.FS, excludes []string, dockerfile string, dockerfileContent string) (io.ReadCloser, error) {
+	pm, err := patternmatcher.New(excludes)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTarFS(pw, fsys, pm, dockerfile, dockerfileContent))
+	}()
+	return pr, nil
+}
+
+// writeTarFS writes the files of the specified file system not matching the
+// exclusion patterns as a tar stream to the specified writer, optionally
+// injecting the Dockerfile contents.
+func writeTarFS(w io.Writer, fsys fs.FS, pm *patternmatcher.PatternMatcher, dockerfile string, dockerfileContent string) error {
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	dockerfile = path.Clean(filepath.ToSlash(dockerfile))
+	tw := tar.NewWriter(w)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		if dockerfileContent != "" && name == dockerfile {
+			return nil
+		}
+		excluded, err := pm.MatchesOrParentMatches(filepath.FromSlash(name))
+		if err != nil {
+			return err
+		}
+		if excluded {
+			// Unless there are exclusion exceptions (“!foo”) that might
+			// re-include files further down, skip the whole directory.
+			if d.IsDir() && !pm.Exclusions() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = fs.ReadLink(fsys, name); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if hdr.ModTime.IsZero() {
+			// such as in case of an embed.FS.
+			hdr.ModTime = time.Unix(0, 0)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck // any error is irrelevant at this point
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if dockerfileContent != "" {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     dockerfile,
+			Mode:     0o644,
+			Size:     int64(len(dockerfileContent)),
+			ModTime:  time.Unix(0, 0),
+		}); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tw, dockerfileContent); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// readIgnorePatterns reads the file specified by “name” in .dockerignore
+// format, returning the list of file patterns to ignore. In case of any error,
+// it returns nil.
+func readIgnorePatterns(name string) []string {
+	return readIgnorePatternsFS(os.DirFS(filepath.Dir(name)), filepath.Base(name))
+}
+
+// readIgnorePatternsFS reads the file specified by “name” from the specified
+// file system in .dockerignore format, returning the list of file patterns to
+// ignore. In case of any error, it returns nil.
+func readIgnorePatternsFS(fsys fs.FS, name string) []string {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close() //nolint:errcheck // any error is irrelevant at this point
+	patterns, err := ignorefile.ReadAll(f)
+	if err != nil {
+		return nil
+	}
+	return patterns
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"archive/tar"
+	"context"
+	"embed"
+	"errors"
+	"io"
+	"io/fs"
+	"testing/fstest"
+	"time"
+
+	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+//go:embed _test/buzzybocks
+var buzzybocksFS embed.FS
+
+// tarContents returns the names and contents of the regular files, as well as
+// the names of the directories, in the specified tar stream.
+func tarContents(r io.Reader) map[string]string {
+	GinkgoHelper()
+	contents := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return contents
+		}
+		Expect(err).NotTo(HaveOccurred())
+		contents[hdr.Name] = string(Successful(io.ReadAll(tr)))
+	}
+}
+
+var _ = Describe("build contexts from file systems", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	fsys := fstest.MapFS{
+		".dockerignore":   {Data: []byte("secrets\n**/*.log\n!keep.log\n")},
+		"Dockerfile":      {Data: []byte("FROM scratch\n")},
+		"app/main.go":     {Data: []byte("package main\n")},
+		"app/debug.log":   {Data: []byte("debug\n")},
+		"keep.log":        {Data: []byte("keep\n")},
+		"secrets/api.key": {Data: []byte("s3cr3t\n")},
+	}
+
+	It("tars a file system, obeying .dockerignore", func() {
+		r := Successful(tarFS(fsys, readIgnorePatternsFS(fsys, ".dockerignore"), "", ""))
+		defer r.Close()
+		Expect(tarContents(r)).To(And(
+			HaveKeyWithValue("Dockerfile", "FROM scratch\n"),
+			HaveKeyWithValue("app/main.go", "package main\n"),
+			HaveKeyWithValue("keep.log", "keep\n"),
+			HaveKey("app/"),
+			HaveKey(".dockerignore"),
+			Not(HaveKey("app/debug.log")),
+			Not(HaveKey("secrets/")),
+			Not(HaveKey("secrets/api.key")),
+		))
+	})
+
+	It("injects a Dockerfile, replacing an existing one", func() {
+		r := Successful(tarFS(fsys, nil, "", "FROM busybox\n"))
+		defer r.Close()
+		contents := tarContents(r)
+		Expect(contents).To(HaveKeyWithValue("Dockerfile", "FROM busybox\n"))
+		Expect(contents).To(HaveKey("secrets/api.key"))
+
+		r = Successful(tarFS(fsys, nil, "build/Dockerfile.test", "FROM busybox\n"))
+		defer r.Close()
+		Expect(tarContents(r)).To(And(
+			HaveKeyWithValue("Dockerfile", "FROM scratch\n"),
+			HaveKeyWithValue("build/Dockerfile.test", "FROM busybox\n")))
+	})
+
+	It("rejects invalid ignore patterns", func() {
+		Expect(tarFS(fsys, []string{"["}, "", "")).Error().To(HaveOccurred())
+	})
+
+	It("doesn't leak when not reading the build context", func() {
+		r := Successful(tarFS(fsys, nil, "", ""))
+		Expect(r.Close()).To(Succeed())
+	})
+
+	Context("using a fake engine", func() {
+
+		var engine *fakeengine.Engine
+
+		BeforeEach(func() {
+			engine = fakeengine.New()
+			engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		})
+
+		It("builds from an embedded file system", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			id := Successful(sess.BuildImageFromFS(ctx, Successful(fs.Sub(buzzybocksFS, "_test/buzzybocks")),
+				build.WithTag("buzzybocks-fs")))
+			img := Successful(sess.Client().ImageInspect(ctx, "buzzybocks-fs"))
+			Expect(img.ID).To(Equal(id))
+			Expect(img.Config.Cmd).To(ConsistOf("sh"))
+		})
+
+		It("builds with an inline Dockerfile", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			id := Successful(sess.BuildImageFromFS(ctx, fsys,
+				build.WithTag("inline"),
+				build.WithDockerfileContent("FROM busybox\nLABEL foo=bar\nCMD [\"/bin/hello\"]\n")))
+			img := Successful(sess.Client().ImageInspect(ctx, "inline"))
+			Expect(img.ID).To(Equal(id))
+			Expect(img.Config.Labels).To(HaveKeyWithValue("foo", "bar"))
+			Expect(img.Config.Cmd).To(ConsistOf("/bin/hello"))
+
+			_ = Successful(sess.BuildImage(ctx, "_test/dockerignore",
+				build.WithTag("inline-dir"),
+				build.WithDockerfileContent("FROM busybox\nLABEL baz=bar\n")))
+			img = Successful(sess.Client().ImageInspect(ctx, "inline-dir"))
+			Expect(img.Config.Labels).To(HaveKeyWithValue("baz", "bar"))
+		})
+
+	})
+
+})
@@ -0,0 +1,337 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/moby/api/types/jsonstream"
+
+	"github.com/thediveo/morbyd/v2/build"
+)
+
+// BuildErrorLogTailLines defines the maximum number of log lines of the failed
+// build step reported by a [BuildError].
+const BuildErrorLogTailLines = 20
+
+// BuildResult describes a successfully built image, as returned by
+// [Session.Build] and [Session.BuildFromFS].
+type BuildResult struct {
+	ImageID  string         // ID of the built image.
+	Tags     []string       // tags applied to the built image, if any.
+	Warnings []BuildWarning // BuildKit warnings, if any.
+	Steps    []BuildStep    // build steps in order of their appearance.
+}
+
+// clone returns a deep copy of this build result.
+func (r *BuildResult) clone() *BuildResult {
+	c := *r
+	c.Tags = slices.Clone(r.Tags)
+	c.Warnings = slices.Clone(r.Warnings)
+	for idx := range c.Warnings {
+		c.Warnings[idx].Detail = slices.Clone(c.Warnings[idx].Detail)
+	}
+	c.Steps = slices.Clone(r.Steps)
+	return &c
+}
+
+// BuildWarning is a warning raised by BuildKit while building an image, such
+// as a Dockerfile linter warning.
+type BuildWarning struct {
+	Rule    string   // such as “JSONArgsRecommended”, if any.
+	Message string   // short description of the warning.
+	Detail  []string // detailed description, if any.
+	URL     string   // URL with further information, if any.
+	File    string   // source file, if known.
+	Line    int      // line in source file, if known; 1-based.
+}
+
+// BuildStep is a single step, or “vertex” in BuildKit parlance, of an image
+// build.
+type BuildStep struct {
+	Name     string        // such as “[2/3] RUN make”.
+	Duration time.Duration // zero if the step has not been completed.
+	Cached   bool          // true if the step's result was taken from the cache.
+	Error    string        // error message in case the step failed.
+}
+
+// BuildError is returned by the image build methods when the build failed in
+// a particular build step, such as a failing RUN instruction.
+type BuildError struct {
+	Step string   // name of the failed build step, if known.
+	Log  []string // tail of the failed build step's log output.
+	Err  error    // error reported by the Docker daemon.
+}
+
+// Error returns the error message of this build error, including the failed
+// step, if known.
+func (e *BuildError) Error() string {
+	if e.Step == "" {
+		return fmt.Sprintf("image build failed, reason: %s", e.Err.Error())
+	}
+	return fmt.Sprintf("image build failed in step %q, reason: %s", e.Step, e.Err.Error())
+}
+
+// Unwrap returns the error reported by the Docker daemon.
+func (e *BuildError) Unwrap() error { return e.Err }
+
+// buildStep is a build step in the making, tracking its log output.
+type buildStep struct {
+	BuildStep
+	started   time.Time
+	announced bool // VertexStarted event has been emitted.
+	finished  bool // VertexCompleted or VertexCached event has been emitted.
+	log       []string
+	partial   string // incomplete last log line
+}
+
+// buildCollector collects the steps, logs, and warnings of an image build
+// from both the classic builder's JSON message stream as well as BuildKit's
+// solve status updates, optionally emitting normalized progress events.
+type buildCollector struct {
+	steps    []*buildStep
+	vertices map[string]*buildStep // BuildKit only: steps by vertex digest
+	warnings []BuildWarning
+	progress func(build.BuildEvent) // optional
+	now      func() time.Time
+}
+
+// newBuildCollector returns a new build collector, emitting progress events
+// to the optional progress function.
+func newBuildCollector(progress func(build.BuildEvent)) *buildCollector {
+	return &buildCollector{
+		vertices: map[string]*buildStep{},
+		progress: progress,
+		now:      time.Now,
+	}
+}
+
+// emit the specified progress event, if there's a progress function.
+func (c *buildCollector) emit(event build.BuildEvent) {
+	if c.progress == nil {
+		return
+	}
+	c.progress(event)
+}
+
+// message collects a classic builder's JSON message, where steps are
+// announced by “Step N/M : INSTRUCTION” stream messages.
+func (c *buildCollector) message(jm jsonstream.Message) {
+	if jm.Stream == "" {
+		return
+	}
+	if strings.HasPrefix(jm.Stream, "Step ") {
+		if _, instr, ok := strings.Cut(jm.Stream, " : "); ok {
+			c.completeClassicStep()
+			step := &buildStep{
+				BuildStep: BuildStep{Name: strings.TrimSpace(instr)},
+				started:   c.now(),
+				announced: true,
+			}
+			c.steps = append(c.steps, step)
+			c.emit(build.BuildEvent{Type: build.VertexStarted, Step: step.Name})
+			return
+		}
+	}
+	if len(c.steps) == 0 {
+		return
+	}
+	step := c.steps[len(c.steps)-1]
+	if strings.HasPrefix(jm.Stream, " ---> Using cache") {
+		step.Cached = true
+		return
+	}
+	if strings.HasPrefix(jm.Stream, " ---> ") || strings.HasPrefix(jm.Stream, "Successfully ") {
+		return
+	}
+	c.log(step, []byte(jm.Stream))
+}
+
+// completeClassicStep completes the current classic builder step, if any.
+func (c *buildCollector) completeClassicStep() {
+	if len(c.steps) == 0 || len(c.vertices) != 0 {
+		return
+	}
+	step := c.steps[len(c.steps)-1]
+	if step.finished {
+		return
+	}
+	step.Duration = c.now().Sub(step.started)
+	c.finish(step)
+}
+
+// finish emits the completion event for the specified step.
+func (c *buildCollector) finish(step *buildStep) {
+	step.finished = true
+	if step.Cached && step.Error == "" {
+		c.emit(build.BuildEvent{Type: build.VertexCached, Step: step.Name, Duration: step.Duration})
+		return
+	}
+	c.emit(build.BuildEvent{Type: build.VertexCompleted, Step: step.Name, Duration: step.Duration, Error: step.Error})
+}
+
+// log adds the log output to the specified step, emitting the completed log
+// lines.
+func (c *buildCollector) log(step *buildStep, data []byte) {
+	for _, line := range step.addLog(data) {
+		c.emit(build.BuildEvent{Type: build.LogLine, Step: step.Name, Line: line})
+	}
+}
+
+// imageID emits the ID of the built image.
+func (c *buildCollector) imageID(id string) {
+	c.emit(build.BuildEvent{Type: build.ImageID, ImageID: id})
+}
+
+// done completes the build step still in progress, if any, after a successful
+// classic build.
+func (c *buildCollector) done() {
+	c.completeClassicStep()
+}
+
+// solveStatus collects a BuildKit solve status update.
+func (c *buildCollector) solveStatus(status *bkclient.SolveStatus) {
+	for _, vertex := range status.Vertexes {
+		step := c.vertex(vertex.Digest.String())
+		if vertex.Name != "" {
+			step.Name = vertex.Name
+		}
+		step.Cached = step.Cached || vertex.Cached
+		if vertex.Error != "" {
+			step.Error = vertex.Error
+		}
+		if vertex.Started != nil && !step.announced {
+			step.announced = true
+			c.emit(build.BuildEvent{Type: build.VertexStarted, Step: step.Name})
+		}
+		if vertex.Completed != nil && !step.finished {
+			if vertex.Started != nil {
+				step.Duration = vertex.Completed.Sub(*vertex.Started)
+			}
+			c.finish(step)
+		}
+	}
+	for _, log := range status.Logs {
+		c.log(c.vertex(log.Vertex.String()), log.Data)
+	}
+	for _, warning := range status.Warnings {
+		c.warnings = append(c.warnings, newBuildWarning(warning))
+		c.emit(build.BuildEvent{Type: build.Warning, Warning: string(warning.Short)})
+	}
+}
+
+// vertex returns the build step for the specified BuildKit vertex digest,
+// creating a new step if necessary.
+func (c *buildCollector) vertex(digest string) *buildStep {
+	step, ok := c.vertices[digest]
+	if !ok {
+		step = &buildStep{}
+		c.vertices[digest] = step
+		c.steps = append(c.steps, step)
+	}
+	return step
+}
+
+// result returns the build result for the specified image ID and tags.
+func (c *buildCollector) result(id string, tags []string) *BuildResult {
+	res := &BuildResult{
+		ImageID:  id,
+		Tags:     tags,
+		Warnings: c.warnings,
+	}
+	for _, step := range c.steps {
+		res.Steps = append(res.Steps, step.BuildStep)
+	}
+	return res
+}
+
+// buildError returns a [BuildError] wrapping the specified daemon error,
+// identifying the failed step together with the tail of its log output. The
+// failed step is either the first BuildKit step with an error, or otherwise
+// the last step seen.
+func (c *buildCollector) buildError(err error) *BuildError {
+	var failed *buildStep
+	for _, step := range c.steps {
+		if step.Error != "" {
+			failed = step
+			break
+		}
+	}
+	if failed == nil && len(c.steps) > 0 && len(c.vertices) == 0 {
+		failed = c.steps[len(c.steps)-1]
+		failed.Error = err.Error()
+		c.completeClassicStep()
+	}
+	if failed == nil {
+		return &BuildError{Err: err}
+	}
+	log := failed.log
+	if failed.partial != "" {
+		log = append(log, failed.partial)
+	}
+	if len(log) > BuildErrorLogTailLines {
+		log = log[len(log)-BuildErrorLogTailLines:]
+	}
+	return &BuildError{
+		Step: failed.Name,
+		Log:  log,
+		Err:  err,
+	}
+}
+
+// addLog adds log output to this build step, splitting it into lines and
+// returning the completed lines.
+func (s *buildStep) addLog(data []byte) []string {
+	lines := strings.Split(s.partial+string(data), "\n")
+	s.partial = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSuffix(line, "\r")
+	}
+	s.log = append(s.log, lines...)
+	// don't hoard more log lines than necessary.
+	if len(s.log) > 2*BuildErrorLogTailLines {
+		s.log = append(s.log[:0], s.log[len(s.log)-BuildErrorLogTailLines:]...)
+	}
+	return lines
+}
+
+// newBuildWarning returns a BuildWarning for the specified BuildKit warning.
+func newBuildWarning(warn *bkclient.VertexWarning) BuildWarning {
+	w := BuildWarning{
+		Message: string(warn.Short),
+		URL:     warn.URL,
+	}
+	// Linter warnings start with the rule name, such as
+	// "JSONArgsRecommended: JSON arguments recommended for ...".
+	if rule, msg, ok := strings.Cut(w.Message, ": "); ok && !strings.ContainsAny(rule, " \t") {
+		w.Rule = rule
+		w.Message = msg
+	}
+	for _, detail := range warn.Detail {
+		w.Detail = append(w.Detail, string(detail))
+	}
+	if warn.SourceInfo != nil {
+		w.File = warn.SourceInfo.Filename
+	}
+	if len(warn.Range) > 0 && warn.Range[0].Start != nil {
+		w.Line = int(warn.Range[0].Start.Line)
+	}
+	return w
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/moby/api/types/jsonstream"
+	"github.com/onsi/gomega/types"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("build results", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	Context("collecting classic builder messages", func() {
+
+		It("collects steps, cache hits, and the log tail of a failed step", func() {
+			c := newBuildCollector(nil)
+			clock := time.Unix(0, 0)
+			c.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+			for _, stream := range []string{
+				"Step 1/3 : FROM busybox\n",
+				" ---> 0123456789ab\n",
+				"Step 2/3 : RUN echo foo\n",
+				" ---> Using cache\n",
+				" ---> 0123456789ab\n",
+				"Step 3/3 : RUN false\n",
+				" ---> Running in 0123456789ab\n",
+			} {
+				c.message(jsonstream.Message{Stream: stream})
+			}
+			for i := range 2 * BuildErrorLogTailLines {
+				c.message(jsonstream.Message{Stream: fmt.Sprintf("line %d\n", i)})
+			}
+			c.message(jsonstream.Message{Stream: "partial"})
+
+			res := c.result("sha256:1234", []string{"foo:bar"})
+			Expect(res.ImageID).To(Equal("sha256:1234"))
+			Expect(res.Tags).To(ConsistOf("foo:bar"))
+			Expect(res.Steps).To(HaveExactElements(
+				And(HaveField("Name", "FROM busybox"), HaveField("Cached", false), HaveField("Duration", time.Second)),
+				And(HaveField("Name", "RUN echo foo"), HaveField("Cached", true)),
+				HaveField("Name", "RUN false"),
+			))
+
+			err := c.buildError(errors.New("returned a non-zero code: 1"))
+			Expect(err.Step).To(Equal("RUN false"))
+			Expect(err.Log).To(HaveLen(BuildErrorLogTailLines))
+			Expect(err.Log[len(err.Log)-2:]).To(HaveExactElements(
+				fmt.Sprintf("line %d", 2*BuildErrorLogTailLines-1), "partial"))
+			Expect(err).To(MatchError(`image build failed in step "RUN false", reason: returned a non-zero code: 1`))
+			Expect(errors.Unwrap(err)).To(MatchError("returned a non-zero code: 1"))
+		})
+
+		It("reports errors without steps", func() {
+			err := newBuildCollector(nil).buildError(errors.New("D'OH!"))
+			Expect(err.Step).To(BeEmpty())
+			Expect(err).To(MatchError("image build failed, reason: D'OH!"))
+		})
+
+	})
+
+	Context("collecting BuildKit solve status", func() {
+
+		It("collects vertices, logs, and warnings", func() {
+			c := newBuildCollector(nil)
+			started := time.Unix(42, 0)
+			completed := started.Add(3 * time.Second)
+			from := digest.FromString("from")
+			run := digest.FromString("run")
+			c.solveStatus(&bkclient.SolveStatus{
+				Vertexes: []*bkclient.Vertex{
+					{Digest: from, Name: "[1/2] FROM busybox", Started: &started},
+					{Digest: run, Name: "[2/2] RUN make", Started: &started},
+				},
+			})
+			c.solveStatus(&bkclient.SolveStatus{
+				Vertexes: []*bkclient.Vertex{
+					{Digest: from, Started: &started, Completed: &completed, Cached: true},
+					{Digest: run, Started: &started, Completed: &completed, Error: "exit code: 2"},
+				},
+				Logs: []*bkclient.VertexLog{
+					{Vertex: run, Data: []byte("make: *** No rule to make target\nmake: giving up\n")},
+				},
+				Warnings: []*bkclient.VertexWarning{
+					{
+						Short:      []byte("JSONArgsRecommended: JSON arguments recommended for CMD"),
+						Detail:     [][]byte{[]byte("use JSON arguments")},
+						URL:        "https://docs.docker.com/go/dockerfile/rule/json-args-recommended/",
+						SourceInfo: &pb.SourceInfo{Filename: "Dockerfile"},
+						Range:      []*pb.Range{{Start: &pb.Position{Line: 2}}},
+					},
+					{Short: []byte("something is off")},
+				},
+			})
+
+			res := c.result("sha256:1234", nil)
+			Expect(res.Steps).To(HaveExactElements(
+				And(HaveField("Name", "[1/2] FROM busybox"), HaveField("Cached", true), HaveField("Duration", 3*time.Second)),
+				And(HaveField("Name", "[2/2] RUN make"), HaveField("Error", "exit code: 2")),
+			))
+			Expect(res.Warnings).To(HaveExactElements(
+				BuildWarning{
+					Rule:    "JSONArgsRecommended",
+					Message: "JSON arguments recommended for CMD",
+					Detail:  []string{"use JSON arguments"},
+					URL:     "https://docs.docker.com/go/dockerfile/rule/json-args-recommended/",
+					File:    "Dockerfile",
+					Line:    2,
+				},
+				BuildWarning{Message: "something is off"},
+			))
+
+			err := c.buildError(errors.New("process did not complete successfully"))
+			Expect(err.Step).To(Equal("[2/2] RUN make"))
+			Expect(err.Log).To(HaveExactElements(
+				"make: *** No rule to make target", "make: giving up"))
+		})
+
+	})
+
+	Context("progress events", func() {
+
+		It("emits classic builder progress events", func() {
+			events := []build.BuildEvent{}
+			c := newBuildCollector(func(event build.BuildEvent) { events = append(events, event) })
+			for _, stream := range []string{
+				"Step 1/2 : FROM busybox\n",
+				" ---> Using cache\n",
+				"Step 2/2 : RUN echo foo\n",
+				"foo\n",
+			} {
+				c.message(jsonstream.Message{Stream: stream})
+			}
+			c.imageID("sha256:1234")
+			c.done()
+			Expect(events).To(HaveExactElements(
+				build.BuildEvent{Type: build.VertexStarted, Step: "FROM busybox"},
+				And(HaveField("Type", build.VertexCached), HaveField("Step", "FROM busybox")),
+				build.BuildEvent{Type: build.VertexStarted, Step: "RUN echo foo"},
+				build.BuildEvent{Type: build.LogLine, Step: "RUN echo foo", Line: "foo"},
+				build.BuildEvent{Type: build.ImageID, ImageID: "sha256:1234"},
+				And(HaveField("Type", build.VertexCompleted), HaveField("Step", "RUN echo foo")),
+			))
+
+			events = events[:0]
+			c.message(jsonstream.Message{Stream: "Step 3/3 : RUN false\n"})
+			_ = c.buildError(errors.New("returned a non-zero code: 1"))
+			Expect(events).To(HaveExactElements(
+				HaveField("Type", build.VertexStarted),
+				And(HaveField("Type", build.VertexCompleted), HaveField("Error", "returned a non-zero code: 1")),
+			))
+		})
+
+		It("emits BuildKit progress events", func() {
+			events := []build.BuildEvent{}
+			c := newBuildCollector(func(event build.BuildEvent) { events = append(events, event) })
+			started := time.Unix(42, 0)
+			completed := started.Add(time.Second)
+			from := digest.FromString("from")
+			run := digest.FromString("run")
+			c.solveStatus(&bkclient.SolveStatus{
+				Vertexes: []*bkclient.Vertex{
+					{Digest: from, Name: "[1/2] FROM busybox", Started: &started, Completed: &completed, Cached: true},
+					{Digest: run, Name: "[2/2] RUN echo foo", Started: &started},
+				},
+				Logs: []*bkclient.VertexLog{{Vertex: run, Data: []byte("foo\n")}},
+			})
+			c.solveStatus(&bkclient.SolveStatus{
+				Vertexes: []*bkclient.Vertex{
+					{Digest: run, Name: "[2/2] RUN echo foo", Started: &started, Completed: &completed},
+				},
+				Warnings: []*bkclient.VertexWarning{{Short: []byte("D'OH!")}},
+			})
+			c.done()
+			Expect(events).To(HaveExactElements(
+				build.BuildEvent{Type: build.VertexStarted, Step: "[1/2] FROM busybox"},
+				build.BuildEvent{Type: build.VertexCached, Step: "[1/2] FROM busybox", Duration: time.Second},
+				build.BuildEvent{Type: build.VertexStarted, Step: "[2/2] RUN echo foo"},
+				build.BuildEvent{Type: build.LogLine, Step: "[2/2] RUN echo foo", Line: "foo"},
+				build.BuildEvent{Type: build.VertexCompleted, Step: "[2/2] RUN echo foo", Duration: time.Second},
+				build.BuildEvent{Type: build.Warning, Warning: "D'OH!"},
+			))
+		})
+
+	})
+
+	Context("using a fake engine", func() {
+
+		var engine *fakeengine.Engine
+
+		BeforeEach(func() {
+			engine = fakeengine.New()
+			engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		})
+
+		It("returns build results", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			res := Successful(sess.BuildFromFS(ctx, fstest.MapFS{},
+				build.WithTag("morbyd/result"),
+				build.WithDockerfileContent("FROM busybox\nLABEL foo=bar\nCMD [\"/bin/hello\"]\n")))
+			Expect(res.ImageID).To(HavePrefix("sha256:"))
+			Expect(res.Tags).To(ConsistOf("morbyd/result"))
+			Expect(res.Steps).To(HaveExactElements(
+				HaveField("Name", "FROM busybox"),
+				HaveField("Name", "LABEL foo=bar"),
+				HaveField("Name", `CMD ["/bin/hello"]`),
+			))
+			Expect(sess.BuildImageFromFS(ctx, fstest.MapFS{},
+				build.WithDockerfileContent("FROM busybox\n"))).To(HavePrefix("sha256:"))
+		})
+
+		DescribeTable("renders the build output according to the display mode",
+			func(ctx context.Context, mode build.DisplayMode, expected types.GomegaMatcher) {
+				sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+				defer sess.Close(ctx)
+
+				var out strings.Builder
+				events := []build.BuildEvent{}
+				Expect(sess.BuildImageFromFS(ctx, fstest.MapFS{},
+					build.WithDockerfileContent("FROM busybox\n"),
+					build.WithOutput(&out),
+					build.WithDisplayMode(mode),
+					build.WithProgress(func(event build.BuildEvent) { events = append(events, event) }),
+				)).To(HavePrefix("sha256:"))
+				Expect(out.String()).To(expected)
+				Expect(events).To(HaveExactElements(
+					HaveField("Type", build.VertexStarted),
+					HaveField("Type", build.ImageID),
+					HaveField("Type", build.VertexCompleted),
+				))
+			},
+			Entry("auto", build.DisplayAuto, ContainSubstring("Step 1/1 : FROM busybox")),
+			Entry("plain", build.DisplayPlain, ContainSubstring("Step 1/1 : FROM busybox")),
+			Entry("quiet", build.DisplayQuiet, BeEmpty()),
+			Entry("rawjson", build.DisplayRawJSON, ContainSubstring(`{"stream":"Step 1/1 : FROM busybox\n"}`)),
+		)
+
+		It("returns a build error", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			_, err := sess.BuildFromFS(ctx, fstest.MapFS{},
+				build.WithDockerfileContent("FROM nonexisting\n"))
+			var builderr *BuildError
+			Expect(errors.As(err, &builderr)).To(BeTrue())
+			Expect(builderr.Step).To(Equal("FROM nonexisting"))
+			Expect(builderr.Err).To(MatchError(ContainSubstring("nonexisting")))
+
+			Expect(sess.BuildImageFromFS(ctx, fstest.MapFS{},
+				build.WithDockerfileContent("FROM nonexisting\n"))).Error().To(
+				MatchError(ContainSubstring(`image build failed in step "FROM nonexisting"`)))
+		})
+
+	})
+
+})
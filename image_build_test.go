@@ -19,7 +19,10 @@ import (
 	"context"
 	"io"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"strings"
+	"testing/fstest"
 	"time"
 
 	"github.com/moby/moby/client"
@@ -27,6 +30,7 @@ import (
 	mock "go.uber.org/mock/gomock"
 
 	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/fakeengine"
 	"github.com/thediveo/morbyd/v2/run"
 	"github.com/thediveo/morbyd/v2/session"
 
@@ -217,6 +221,37 @@ var _ = Describe("build image", Ordered, func() {
 			Entry("with buildkit", true),
 		)
 
+		It("mounts build secrets when using buildkit", func(ctx context.Context) {
+			hello := hello()
+			secret := filepath.Join(GinkgoT().TempDir(), "secret")
+			Expect(os.WriteFile(secret, []byte(hello), 0o600)).To(Succeed())
+			var out safe.Buffer
+			imgid := Successful(sess.BuildImageFromFS(ctx, fstest.MapFS{},
+				build.WithBuildKit(),
+				build.WithoutCache(),
+				build.WithSecret("hello", secret),
+				build.WithDockerfileContent(`FROM busybox
+RUN --mount=type=secret,id=hello echo "..$(cat /run/secrets/hello).."
+`),
+				build.WithOutput(io.MultiWriter(GinkgoWriter, &out)),
+			))
+			Expect(imgid).To(HavePrefix("sha256:"))
+			Expect(out.String()).To(ContainSubstring(".." + hello + ".."))
+		})
+
+		It("returns warnings and steps when using buildkit", func(ctx context.Context) {
+			res := Successful(sess.Build(ctx, "./_test/warning",
+				build.WithBuildKit(),
+				build.WithOutput(GinkgoWriter),
+			))
+			Expect(res.ImageID).To(HavePrefix("sha256:"))
+			Expect(res.Warnings).To(ContainElement(And(
+				HaveField("Rule", "JSONArgsRecommended"),
+				HaveField("File", "Dockerfile"),
+				HaveField("Line", 2))))
+			Expect(res.Steps).NotTo(BeEmpty())
+		})
+
 		It("raises warnings when using buildkit", func(ctx context.Context) {
 			var out safe.Buffer
 			imgid := Successful(sess.BuildImage(ctx, "./_test/warning",
@@ -252,4 +287,27 @@ var _ = Describe("build image", Ordered, func() {
 		Expect(sess.BuildImage(ctx, "./_test/dockerignore")).To(Equal("foobar"))
 	})
 
+	It("rejects secrets and SSH agents without BuildKit", func(ctx context.Context) {
+		sess := Successful(NewSession(ctx, fakeengine.WithEngine(fakeengine.New())))
+		DeferCleanup(func(ctx context.Context) {
+			sess.Close(ctx)
+		})
+		Expect(sess.BuildImage(ctx, "./_test/buzzybocks",
+			build.WithSecret("token", "/dev/null"))).Error().To(
+			MatchError("build secrets and SSH agent forwarding require BuildKit"))
+		Expect(sess.BuildImage(ctx, "./_test/buzzybocks",
+			build.WithSSHAgent(""))).Error().To(
+			MatchError("build secrets and SSH agent forwarding require BuildKit"))
+	})
+
+	It("provides secrets and SSH agents to BuildKit", func() {
+		Expect(buildkitAttachables(&build.Options{})).To(BeEmpty())
+		Expect(buildkitAttachables(&build.Options{
+			Secrets: []build.Secret{{ID: "token", Source: "/dev/null"}},
+		})).To(HaveLen(1))
+		Expect(buildkitAttachables(&build.Options{
+			SSHAgents: []build.SSHAgent{{ID: "default", Paths: []string{"/nonexisting.sock"}}},
+		})).Error().To(MatchError(ContainSubstring("cannot forward SSH agents")))
+	})
+
 })
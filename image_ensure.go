@@ -0,0 +1,146 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/thediveo/morbyd/v2/session"
+)
+
+// EnsureImages makes sure that the referenced images are locally available,
+// concurrently pulling the missing ones. It is intended for warming up images
+// before running tests, such as in a Ginkgo BeforeSuite or in TestMain.
+//
+// The number of concurrent pulls is bounded by [session.WithPullConcurrency].
+// If the session has been configured using [session.WithPullOutput], the
+// aggregated progress is written to it, one line per image and state change,
+// as well as a final summary line.
+//
+// EnsureImages doesn't stop at the first failure but instead tries to pull all
+// missing images, returning an aggregated error.
+//
+// Pulls are deduplicated with concurrent pulls of the same images within this
+// process, see [Session.PullImage]. When the passed context gets cancelled,
+// EnsureImages stops waiting for its pulls, which then get cancelled unless
+// other callers are still waiting for them.
+func (s *Session) EnsureImages(ctx context.Context, refs ...string) (err error) {
+	runner := caller(1, 0)
+	ctx, op := s.begin(ctx, "image.ensure",
+		slog.String(AttrImage, strings.Join(refs, ",")),
+		slog.String(AttrCaller, runner))
+	defer func() { op.end(err) }()
+
+	concurrency := s.opts.PullConcurrency
+	if concurrency <= 0 {
+		concurrency = session.DefaultPullConcurrency
+	}
+	progress := &ensureProgress{out: s.opts.PullOutput}
+	if progress.out == nil {
+		progress.out = io.Discard
+	}
+
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	errs := make([]error, len(refs))
+	seen := map[string]struct{}{}
+	for idx, ref := range refs {
+		if _, ok := seen[ref]; ok {
+			continue
+		}
+		seen[ref] = struct{}{}
+		g.Go(func() error {
+			errs[idx] = s.ensureImage(ctx, ref, runner, progress)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	progress.summary()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("cannot ensure images, reason: %w", err)
+	}
+	return nil
+}
+
+// ensureImage pulls the referenced image if it isn't locally available,
+// reporting its progress and attributing any pull to the specified caller.
+func (s *Session) ensureImage(ctx context.Context, ref string, runner string, progress *ensureProgress) error {
+	hasimg, err := s.HasImage(ctx, ref)
+	if err != nil {
+		progress.failed(ref, err)
+		return fmt.Errorf("cannot check image %s, reason: %w", ref, err)
+	}
+	if hasimg {
+		progress.present(ref)
+		return nil
+	}
+	progress.printf("%s: pulling", ref)
+	res, err := s.pullImage(ctx, ref, runner)
+	if err != nil {
+		progress.failed(ref, err)
+		return fmt.Errorf("cannot pull image %s, reason: %w", ref, err)
+	}
+	progress.pulled(res)
+	return nil
+}
+
+// ensureProgress aggregates the progress of concurrently ensuring images into
+// a single output, one line at a time.
+type ensureProgress struct {
+	mu                      sync.Mutex
+	out                     io.Writer
+	npresent, npulled, nerr int
+}
+
+func (p *ensureProgress) printf(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = fmt.Fprintf(p.out, format+"\n", args...)
+}
+
+func (p *ensureProgress) present(ref string) {
+	p.mu.Lock()
+	p.npresent++
+	p.mu.Unlock()
+	p.printf("%s: present", ref)
+}
+
+func (p *ensureProgress) pulled(res *PullResult) {
+	p.mu.Lock()
+	p.npulled++
+	p.mu.Unlock()
+	p.printf("%s: pulled %d layers, %d already present (%s)",
+		res.Ref, len(res.Downloaded), len(res.Present), res.Digest)
+}
+
+func (p *ensureProgress) failed(ref string, err error) {
+	p.mu.Lock()
+	p.nerr++
+	p.mu.Unlock()
+	p.printf("%s: failed: %s", ref, err.Error())
+}
+
+func (p *ensureProgress) summary() {
+	p.printf("%d images present, %d pulled, %d failed", p.npresent, p.npulled, p.nerr)
+}
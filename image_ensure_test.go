@@ -0,0 +1,356 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/moby"
+	"github.com/thediveo/morbyd/v2/pull"
+	"github.com/thediveo/morbyd/v2/session"
+	"github.com/thediveo/safe"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+// gatedEngine wraps a fake engine in order to count image pulls and builds,
+// holding them until released or cancelled.
+type gatedEngine struct {
+	*fakeengine.Engine
+	pulls, builds atomic.Int32
+	cancelled     atomic.Int32
+	gate          chan struct{}
+}
+
+func (g *gatedEngine) ImagePull(ctx context.Context, refStr string, options client.ImagePullOptions) (client.ImagePullResponse, error) {
+	g.pulls.Add(1)
+	select {
+	case <-g.gate:
+	case <-ctx.Done():
+		g.cancelled.Add(1)
+		return nil, ctx.Err()
+	}
+	return g.Engine.ImagePull(ctx, refStr, options)
+}
+
+func (g *gatedEngine) ImageBuild(ctx context.Context, buildContext io.Reader, options client.ImageBuildOptions) (client.ImageBuildResult, error) {
+	g.builds.Add(1)
+	select {
+	case <-g.gate:
+	case <-ctx.Done():
+		g.cancelled.Add(1)
+		return client.ImageBuildResult{}, ctx.Err()
+	}
+	return g.Engine.ImageBuild(ctx, buildContext, options)
+}
+
+var _ = Describe("ensuring images", func() {
+
+	var engine *fakeengine.Engine
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		engine = fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		engine.AddRemoteImage(fakeengine.Image{Ref: "alpine", Cmd: []string{"sh"}})
+		engine.AddRemoteImage(fakeengine.Image{Ref: "debian:bookworm", Cmd: []string{"bash"}})
+	})
+
+	It("pulls missing images and reports aggregated progress", func(ctx context.Context) {
+		var out safe.Buffer
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithPullConcurrency(2),
+			session.WithPullOutput(&out)))
+		defer sess.Close(ctx)
+
+		Expect(sess.EnsureImages(ctx, "busybox", "alpine", "debian:bookworm", "alpine")).To(Succeed())
+		Expect(sess.HasImage(ctx, "alpine")).To(BeTrue())
+		Expect(sess.HasImage(ctx, "debian:bookworm")).To(BeTrue())
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(ConsistOf(
+			"busybox: present",
+			"alpine: pulling",
+			MatchRegexp(`^alpine: pulled 1 layers, 0 already present \(sha256:[[:xdigit:]]{64}\)$`),
+			"debian:bookworm: pulling",
+			MatchRegexp(`^debian:bookworm: pulled 1 layers, 0 already present \(sha256:.*\)$`),
+			"1 images present, 2 pulled, 0 failed",
+		))
+		Expect(lines[len(lines)-1]).To(Equal("1 images present, 2 pulled, 0 failed"))
+
+		Expect(sess.EnsureImages(ctx, "alpine", "debian:bookworm")).To(Succeed())
+		Expect(out.String()).To(HaveSuffix("2 images present, 0 pulled, 0 failed\n"))
+	})
+
+	It("reports all failures", func(ctx context.Context) {
+		var out safe.Buffer
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithPullOutput(&out)))
+		defer sess.Close(ctx)
+
+		err := sess.EnsureImages(ctx, "morbyd/nada", "alpine", "morbyd/niente")
+		Expect(err).To(MatchError(And(
+			HavePrefix("cannot ensure images, reason: "),
+			ContainSubstring("cannot pull image morbyd/nada"),
+			ContainSubstring("cannot pull image morbyd/niente"))))
+		Expect(sess.HasImage(ctx, "alpine")).To(BeTrue())
+		Expect(out.String()).To(And(
+			ContainSubstring("morbyd/nada: failed: image pull failed"),
+			ContainSubstring("0 images present, 1 pulled, 2 failed")))
+
+		engine.FailNext("ImageInspect", context.DeadlineExceeded)
+		Expect(sess.EnsureImages(ctx, "busybox")).To(MatchError(
+			ContainSubstring("cannot check image busybox")))
+	})
+
+	When("deduplicating", func() {
+
+		var gated *gatedEngine
+
+		BeforeEach(func() {
+			gated = &gatedEngine{Engine: engine, gate: make(chan struct{})}
+		})
+
+		newSession := func(ctx context.Context, opts ...session.Opt) *Session {
+			GinkgoHelper()
+			sess := Successful(NewSession(ctx, append([]session.Opt{
+				func(o *session.Options) error {
+					o.Wrapper = func(moby.Client) moby.Client { return gated }
+					return nil
+				}}, opts...)...))
+			DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+			return sess
+		}
+
+		It("pulls the same image only once", func(ctx context.Context) {
+			var logs safe.Buffer
+			sess1 := newSession(ctx, session.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+			sess2 := newSession(ctx)
+
+			var wg sync.WaitGroup
+			results := make([]*PullResult, 4)
+			for idx := range results {
+				sess := sess1
+				if idx%2 == 1 {
+					sess = sess2
+				}
+				wg.Go(func() {
+					defer GinkgoRecover()
+					results[idx] = Successful(sess.PullImage(ctx, "alpine"))
+				})
+			}
+			Eventually(gated.pulls.Load).Should(Equal(int32(1)))
+			time.Sleep(100 * time.Millisecond)
+			close(gated.gate)
+			wg.Wait()
+			Expect(gated.pulls.Load()).To(Equal(int32(1)))
+			for _, res := range results[1:] {
+				Expect(res.Digest).To(Equal(results[0].Digest))
+				Expect(res).NotTo(BeIdenticalTo(results[0]), "results must be copies")
+			}
+			Expect(logRecords(&logs)).To(ContainElement(And(
+				HaveKeyWithValue("msg", "image.pull"),
+				HaveKeyWithValue(AttrShared, true))))
+
+			By("pulling again after the first pull has completed")
+			Expect(sess1.PullImage(ctx, "alpine")).Error().NotTo(HaveOccurred())
+			Expect(gated.pulls.Load()).To(Equal(int32(2)))
+		})
+
+		It("doesn't share pulls using different registry credentials", func(ctx context.Context) {
+			sess := newSession(ctx)
+
+			var wg sync.WaitGroup
+			for idx, auth := range []string{"", "Zm9vOmJhcg=="} {
+				wg.Go(func() {
+					defer GinkgoRecover()
+					Expect(sess.PullImage(ctx, "alpine", pull.WithRegistryAuth(auth))).Error().NotTo(HaveOccurred())
+				})
+				Eventually(gated.pulls.Load).Should(Equal(int32(1 + idx)))
+			}
+			close(gated.gate)
+			wg.Wait()
+			Expect(gated.pulls.Load()).To(Equal(int32(2)))
+		})
+
+		It("shares pull failures", func(ctx context.Context) {
+			sess := newSession(ctx)
+
+			var wg sync.WaitGroup
+			errs := make([]error, 2)
+			for idx := range errs {
+				wg.Go(func() { _, errs[idx] = sess.PullImage(ctx, "morbyd/nada") })
+			}
+			Eventually(gated.pulls.Load).Should(Equal(int32(1)))
+			time.Sleep(100 * time.Millisecond)
+			close(gated.gate)
+			wg.Wait()
+			Expect(gated.pulls.Load()).To(Equal(int32(1)))
+			Expect(errs).To(HaveEach(MatchError(ContainSubstring("pull access denied"))))
+		})
+
+		It("stops waiting for an in-flight pull when the context is done", func(ctx context.Context) {
+			sess := newSession(ctx)
+
+			done := make(chan error)
+			go func() {
+				_, err := sess.PullImage(ctx, "alpine")
+				done <- err
+			}()
+			Eventually(gated.pulls.Load).Should(Equal(int32(1)))
+			waitctx, cancel := context.WithCancel(ctx)
+			cancel()
+			Expect(sess.PullImage(waitctx, "alpine")).Error().To(MatchError(context.Canceled))
+			close(gated.gate)
+			Eventually(done).Should(Receive(Succeed()))
+		})
+
+		It("continues a shared pull when its starting caller gives up", func(ctx context.Context) {
+			sess := newSession(ctx)
+
+			leaderctx, cancel := context.WithCancel(ctx)
+			leaderdone := make(chan error)
+			go func() {
+				_, err := sess.PullImage(leaderctx, "alpine")
+				leaderdone <- err
+			}()
+			Eventually(gated.pulls.Load).Should(Equal(int32(1)))
+
+			followerdone := make(chan *PullResult)
+			go func() {
+				defer GinkgoRecover()
+				followerdone <- Successful(sess.PullImage(ctx, "alpine"))
+			}()
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			Eventually(leaderdone).Should(Receive(MatchError(context.Canceled)))
+
+			close(gated.gate)
+			var res *PullResult
+			Eventually(followerdone).Should(Receive(&res))
+			Expect(res.Digest).NotTo(BeEmpty())
+			Expect(gated.pulls.Load()).To(Equal(int32(1)))
+		})
+
+		It("cancels a shared pull when all its callers give up", func(ctx context.Context) {
+			sess := newSession(ctx)
+
+			waitctx, cancel := context.WithCancel(ctx)
+			var wg sync.WaitGroup
+			for range 2 {
+				wg.Go(func() {
+					defer GinkgoRecover()
+					Expect(sess.PullImage(waitctx, "alpine")).Error().To(MatchError(context.Canceled))
+				})
+			}
+			Eventually(gated.pulls.Load).Should(Equal(int32(1)))
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			wg.Wait()
+			Eventually(gated.cancelled.Load).Should(Equal(int32(1)))
+
+			By("starting afresh after the abandoned pull")
+			close(gated.gate)
+			Expect(sess.PullImage(ctx, "alpine")).Error().NotTo(HaveOccurred())
+			Expect(gated.pulls.Load()).To(Equal(int32(2)))
+		})
+
+		It("hands out deep copies of shared results", func() {
+			res := &PullResult{Downloaded: []string{"1"}, Present: []string{"2"}}
+			c := res.clone()
+			c.Downloaded[0] = "foo"
+			c.Present[0] = "bar"
+			Expect(res.Downloaded).To(ConsistOf("1"))
+			Expect(res.Present).To(ConsistOf("2"))
+
+			bres := &BuildResult{
+				Tags:     []string{"foo"},
+				Warnings: []BuildWarning{{Detail: []string{"bar"}}},
+				Steps:    []BuildStep{{Name: "baz"}},
+			}
+			bc := bres.clone()
+			bc.Tags[0] = ""
+			bc.Warnings[0].Detail[0] = ""
+			bc.Steps[0].Name = ""
+			Expect(bres).To(Equal(&BuildResult{
+				Tags:     []string{"foo"},
+				Warnings: []BuildWarning{{Detail: []string{"bar"}}},
+				Steps:    []BuildStep{{Name: "baz"}},
+			}))
+		})
+
+		It("builds the same tags from the same context only once", func(ctx context.Context) {
+			sess := newSession(ctx)
+
+			var wg sync.WaitGroup
+			ids := make([]string, 3)
+			for idx := range ids {
+				wg.Go(func() {
+					defer GinkgoRecover()
+					ids[idx] = Successful(sess.BuildImage(ctx, "./_test/buzzybocks",
+						build.WithTag("morbyd/buzzybocks:dedup")))
+				})
+			}
+			Eventually(gated.builds.Load).Should(Equal(int32(1)))
+			time.Sleep(100 * time.Millisecond)
+			close(gated.gate)
+			wg.Wait()
+			Expect(gated.builds.Load()).To(Equal(int32(1)))
+			Expect(ids).To(HaveEach(Equal(ids[0])))
+
+			By("not deduplicating builds with different build options")
+			gated.gate = make(chan struct{})
+			for idx, arg := range []string{"foo", "bar"} {
+				wg.Go(func() {
+					defer GinkgoRecover()
+					Expect(sess.BuildImage(ctx, "./_test/buzzybocks",
+						build.WithTag("morbyd/buzzybocks:dedup"),
+						build.WithBuildArg("ARG="+arg))).Error().NotTo(HaveOccurred())
+				})
+				Eventually(gated.builds.Load).Should(Equal(int32(2 + idx)))
+			}
+			close(gated.gate)
+			wg.Wait()
+
+			By("always building untagged images")
+			var out bytes.Buffer
+			Expect(sess.BuildImage(ctx, "./_test/buzzybocks", build.WithOutput(&out))).Error().NotTo(HaveOccurred())
+			Expect(sess.BuildImage(ctx, "./_test/buzzybocks")).Error().NotTo(HaveOccurred())
+			Expect(gated.builds.Load()).To(Equal(int32(5)))
+		})
+
+	})
+
+})
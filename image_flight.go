@@ -0,0 +1,115 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// imageFlights deduplicates concurrent pulls and builds of the same image
+// references on the same Docker daemon within this process, such as when
+// several tests run the same not yet locally available image at the same time.
+var (
+	imageFlightsMu sync.Mutex
+	imageFlights   = map[string]*imageFlight{}
+)
+
+// imageFlight is an in-flight pull or build, together with the number of its
+// callers still waiting for its outcome.
+type imageFlight struct {
+	done    chan struct{} // closed when the operation has finished.
+	val     any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightKey returns the deduplication key for the specified operation and its
+// parameters, scoped to the Docker daemon of this session.
+func (s *Session) flightKey(op string, params ...string) string {
+	daemon := fmt.Sprintf("%p", s.moby)
+	if dh, ok := s.moby.(interface{ DaemonHost() string }); ok {
+		daemon = dh.DaemonHost()
+	}
+	return op + "\x00" + daemon + "\x00" + strings.Join(params, "\x00")
+}
+
+// flight runs fn, unless an operation with the same key is already in flight,
+// in which case flight waits for the result of the in-flight operation instead
+// and reports it as shared. Waiting stops early when the passed context is
+// done, while the in-flight operation continues on behalf of its other
+// callers: fn thus runs with its own context that doesn't get cancelled as
+// long as there still is any caller waiting, even if the context of the
+// caller starting the operation gets cancelled. Only when all callers have
+// given up, flight cancels the context of fn; subsequent callers then start a
+// new operation.
+//
+// Each caller receives its own deep copy of the result, as returned by clone.
+func flight[T any](ctx context.Context, key string, fn func(context.Context) (*T, error), clone func(*T) *T) (*T, bool, error) {
+	imageFlightsMu.Lock()
+	f, shared := imageFlights[key]
+	if !shared {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &imageFlight{done: make(chan struct{}), cancel: cancel}
+		imageFlights[key] = f
+		go func() {
+			defer cancel()
+			val, err := fn(fctx)
+			imageFlightsMu.Lock()
+			if imageFlights[key] == f {
+				delete(imageFlights, key)
+			}
+			imageFlightsMu.Unlock()
+			f.val, f.err = val, err
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	imageFlightsMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		imageFlightsMu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// Nobody is interested anymore, so abandon the operation.
+			if imageFlights[key] == f {
+				delete(imageFlights, key)
+			}
+			f.cancel()
+		}
+		imageFlightsMu.Unlock()
+		return nil, shared, ctx.Err()
+	case <-f.done:
+		if f.err != nil {
+			return nil, shared, f.err
+		}
+		// Hand out copies, so callers cannot trip on each other.
+		return clone(f.val.(*T)), shared, nil
+	}
+}
+
+// absPath returns the absolute representation of the specified path, falling
+// back to the path itself.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -16,14 +16,40 @@ package morbyd
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/containerd/platforms"
+	"github.com/moby/moby/api/types/jsonstream"
 	"github.com/moby/moby/client/pkg/jsonmessage"
 
+	"github.com/thediveo/morbyd/v2/internal/dockerconfig"
 	"github.com/thediveo/morbyd/v2/pull"
 )
 
+// PullResult describes a pulled image, as returned by [Session.PullImage].
+type PullResult struct {
+	Ref        string   // image reference pulled.
+	Digest     string   // resolved digest, such as “sha256:...”, if reported.
+	UpToDate   bool     // true if the local image already was up to date.
+	Downloaded []string // IDs of the layers downloaded.
+	Present    []string // IDs of the layers already present locally.
+}
+
+// clone returns a deep copy of this pull result.
+func (r *PullResult) clone() *PullResult {
+	c := *r
+	c.Downloaded = slices.Clone(r.Downloaded)
+	c.Present = slices.Clone(r.Present)
+	return &c
+}
+
 // PullImage pulls a container image specified by the image reference, if not
 // already locally available. The additional pull options are applied in the
 // order they are provided.
@@ -31,22 +57,130 @@ import (
 // If no pull process output writer has been specified using [pull.WithOutput]
 // any output (such as pull progress, et cetera) will simply be discarded.
 //
-// Any pull process errors will be reported.
-func (s *Session) PullImage(ctx context.Context, imgref string, opts ...pull.Opt) error {
+// If the session has been configured using
+// [session.WithRegistryAuthFromDockerConfig], or if [pull.WithAuthFromConfig]
+// is specified, the registry credentials are taken from the Docker client
+// configuration, unless explicitly specified using [pull.WithRegistryAuth].
+//
+// Concurrent pulls of the same image reference (and platforms) using the same
+// registry credentials within this process are deduplicated: only one pull is
+// actually run, with only its output written, while the other callers wait
+// for its outcome. The pull continues even if the context of the caller that
+// started it gets cancelled, as long as other callers are still waiting for
+// its outcome; only when all callers have given up, the pull gets cancelled.
+//
+// PullImage returns a [PullResult] with the resolved digest and the layers
+// downloaded versus the layers already present. Any pull process errors will
+// be reported.
+func (s *Session) PullImage(ctx context.Context, imgref string, opts ...pull.Opt) (*PullResult, error) {
+	return s.pullImage(ctx, imgref, caller(1, 0), opts...)
+}
+
+// pullImage pulls the referenced image, attributing the pull to the specified
+// caller.
+func (s *Session) pullImage(ctx context.Context, imgref string, runner string, opts ...pull.Opt) (res *PullResult, err error) {
 	piopts := pull.Options{}
 	for _, opt := range opts {
 		if err := opt(&piopts); err != nil {
-			return err
+			return nil, err
 		}
 	}
+	ctx, op := s.begin(ctx, "image.pull",
+		slog.String(AttrImage, imgref),
+		slog.String(AttrCaller, runner))
+	defer func() { op.end(err) }()
+
 	if piopts.Out == nil {
 		piopts.Out = io.Discard
 	}
+	if piopts.RegistryAuth == "" && (piopts.AuthFromConfig || s.opts.RegistryAuthFromDockerConfig) {
+		piopts.RegistryAuth, err = dockerconfig.EncodedAuth(imgref)
+		if err != nil {
+			return nil, fmt.Errorf("image pull failed, reason: %w", err)
+		}
+	}
+	pltfrms := make([]string, 0, len(piopts.Platforms))
+	for _, p := range piopts.Platforms {
+		pltfrms = append(pltfrms, platforms.Format(p))
+	}
+	// Pulls using different registry credentials must not be merged, as
+	// otherwise callers might receive the outcome of a pull with wrong or no
+	// credentials at all.
+	authsum := sha256.Sum256([]byte(piopts.RegistryAuth))
+	res, shared, err := flight(ctx,
+		s.flightKey("pull", imgref, strconv.FormatBool(piopts.All), strings.Join(pltfrms, ","),
+			hex.EncodeToString(authsum[:])),
+		func(ctx context.Context) (*PullResult, error) { return s.pull(ctx, imgref, &piopts) },
+		(*PullResult).clone)
+	op.set(slog.Bool(AttrShared, shared))
+	return res, err
+}
+
+// pull pulls the referenced image as configured in the passed pull options.
+func (s *Session) pull(ctx context.Context, imgref string, piopts *pull.Options) (*PullResult, error) {
 	r, err := s.moby.ImagePull(ctx, imgref, piopts.ImagePullOptions)
 	if err != nil {
-		return fmt.Errorf("image pull failed, reason: %w", err)
+		return nil, fmt.Errorf("image pull failed, reason: %w", err)
 	}
 	defer func() { _ = r.Close() }()
-	err = jsonmessage.DisplayMessages(r.JSONMessages(ctx), piopts.Out)
-	return err
+	collector := newPullCollector(imgref)
+	if err := jsonmessage.DisplayMessages(collector.tap(r.JSONMessages(ctx)), piopts.Out); err != nil {
+		return nil, fmt.Errorf("image pull failed, reason: %w", err)
+	}
+	return collector.res, nil
+}
+
+// pullCollector collects the digest and layer information from the JSON
+// messages of an image pull.
+type pullCollector struct {
+	res    *PullResult
+	layers map[string]struct{}
+}
+
+func newPullCollector(imgref string) *pullCollector {
+	return &pullCollector{
+		res:    &PullResult{Ref: imgref},
+		layers: map[string]struct{}{},
+	}
+}
+
+// tap returns a message stream passing on the messages from the specified
+// stream, collecting pull information along the way.
+func (c *pullCollector) tap(messages jsonmessage.JSONMessagesStream) jsonmessage.JSONMessagesStream {
+	return func(yield func(jsonstream.Message, error) bool) {
+		for msg, err := range messages {
+			if err == nil {
+				c.message(msg)
+			}
+			if !yield(msg, err) {
+				return
+			}
+		}
+	}
+}
+
+// message collects the pull information from the specified message.
+func (c *pullCollector) message(msg jsonstream.Message) {
+	switch {
+	case strings.HasPrefix(msg.Status, "Digest: "):
+		c.res.Digest = strings.TrimPrefix(msg.Status, "Digest: ")
+	case strings.HasPrefix(msg.Status, "Status: Image is up to date"):
+		c.res.UpToDate = true
+	case msg.ID == "":
+		return
+	case msg.Status == "Already exists":
+		c.layer(msg.ID, &c.res.Present)
+	case msg.Status == "Pull complete":
+		c.layer(msg.ID, &c.res.Downloaded)
+	}
+}
+
+// layer adds the specified layer ID to the specified list, unless the layer
+// has already been seen.
+func (c *pullCollector) layer(id string, list *[]string) {
+	if _, ok := c.layers[id]; ok {
+		return
+	}
+	c.layers[id] = struct{}{}
+	*list = append(*list, id)
 }
@@ -22,11 +22,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/containerd/errdefs"
 	client "github.com/moby/moby/client"
 	mock "go.uber.org/mock/gomock"
 
+	"github.com/thediveo/morbyd/v2/fakeengine"
 	"github.com/thediveo/morbyd/v2/internal/jsonmsgs"
 	"github.com/thediveo/morbyd/v2/pull"
+	"github.com/thediveo/morbyd/v2/run"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -59,7 +62,7 @@ var _ = Describe("pulling images", func() {
 		rec.ImagePull(Any, Any, Any).Return(client.ImagePullResponse(jsonmsgs.New(rc)), nil)
 
 		var buff bytes.Buffer
-		Expect(sess.PullImage(ctx, "buzzybocks:earliest", pull.WithOutput(&buff))).To(Succeed())
+		Expect(sess.PullImage(ctx, "buzzybocks:earliest", pull.WithOutput(&buff))).Error().NotTo(HaveOccurred())
 		Expect(buff.String()).To(Equal("foobar\n"))
 	})
 
@@ -73,7 +76,7 @@ var _ = Describe("pulling images", func() {
 		rec := sess.Client().(*MockClient).EXPECT()
 		rec.ImagePull(Any, Any, Any).Return(nil, errors.New("error IJK305I"))
 
-		Expect(sess.PullImage(ctx, "buzzybocks:earliest")).NotTo(Succeed())
+		Expect(sess.PullImage(ctx, "buzzybocks:earliest")).Error().To(HaveOccurred())
 	})
 
 	It("reports stream errors", func(ctx context.Context) {
@@ -89,7 +92,105 @@ var _ = Describe("pulling images", func() {
 `))
 		rec.ImagePull(Any, Any, Any).Return(client.ImagePushResponse(jsonmsgs.New(rc)), nil)
 
-		Expect(sess.PullImage(ctx, "buzzybocks:earliest")).NotTo(Succeed())
+		Expect(sess.PullImage(ctx, "buzzybocks:earliest")).Error().To(MatchError(
+			"image pull failed, reason: error IJK305I"))
+	})
+
+	It("returns the digest and layers pulled", func(ctx context.Context) {
+		ctrl := mock.NewController(GinkgoT())
+		sess := Successful(NewSession(ctx,
+			WithMockController(ctrl, "ImagePull")))
+		DeferCleanup(func(ctx context.Context) {
+			sess.Close(ctx)
+		})
+		rec := sess.Client().(*MockClient).EXPECT()
+		rc := io.NopCloser(strings.NewReader(`
+{"status":"Pulling from library/buzzybocks","id":"earliest"}
+{"status":"Already exists","id":"aaaaaaaaaaaa"}
+{"status":"Pulling fs layer","id":"bbbbbbbbbbbb"}
+{"status":"Pulling fs layer","id":"cccccccccccc"}
+{"status":"Downloading","id":"bbbbbbbbbbbb","progressDetail":{"current":1,"total":2}}
+{"status":"Download complete","id":"bbbbbbbbbbbb"}
+{"status":"Pull complete","id":"bbbbbbbbbbbb"}
+{"status":"Pull complete","id":"cccccccccccc"}
+{"status":"Pull complete","id":"cccccccccccc"}
+{"status":"Digest: sha256:0123456789abcdef"}
+{"status":"Status: Downloaded newer image for buzzybocks:earliest"}
+`))
+		rec.ImagePull(Any, Any, Any).Return(client.ImagePullResponse(jsonmsgs.New(rc)), nil)
+
+		res := Successful(sess.PullImage(ctx, "buzzybocks:earliest"))
+		Expect(res).To(HaveValue(Equal(PullResult{
+			Ref:        "buzzybocks:earliest",
+			Digest:     "sha256:0123456789abcdef",
+			Downloaded: []string{"bbbbbbbbbbbb", "cccccccccccc"},
+			Present:    []string{"aaaaaaaaaaaa"},
+		})))
+	})
+
+	When("running containers", func() {
+
+		var engine *fakeengine.Engine
+
+		BeforeEach(func() {
+			engine = fakeengine.New()
+			engine.AddRemoteImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		})
+
+		It("pulls missing images using pull options", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			var buff bytes.Buffer
+			cntr := Successful(sess.Run(ctx, "busybox",
+				run.WithPullOptions(pull.WithOutput(&buff))))
+			cntr.Kill(ctx)
+			Expect(buff.String()).To(ContainSubstring("Downloaded newer image for busybox:latest"))
+
+			buff.Reset()
+			cntr = Successful(sess.Run(ctx, "busybox",
+				run.WithPullOptions(pull.WithOutput(&buff))))
+			cntr.Kill(ctx)
+			Expect(buff.String()).To(BeEmpty())
+
+			res := Successful(sess.PullImage(ctx, "busybox"))
+			Expect(res.UpToDate).To(BeTrue())
+			Expect(res.Digest).To(HavePrefix("sha256:"))
+			Expect(res.Downloaded).To(BeEmpty())
+		})
+
+		It("always pulls images", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			Expect(sess.PullImage(ctx, "busybox")).Error().NotTo(HaveOccurred())
+			var buff bytes.Buffer
+			cntr := Successful(sess.Run(ctx, "busybox",
+				run.WithPullPolicy(run.PullAlways),
+				run.WithPullOptions(pull.WithOutput(&buff))))
+			cntr.Kill(ctx)
+			Expect(buff.String()).To(ContainSubstring("Image is up to date"))
+		})
+
+		It("never pulls images", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			Expect(sess.Run(ctx, "busybox", run.WithPullPolicy(run.PullNever))).Error().To(And(
+				MatchError(errdefs.IsNotFound, "IsNotFound"),
+				MatchError(ContainSubstring("pull policy is never"))))
+			Expect(sess.HasImage(ctx, "busybox")).To(BeFalse())
+		})
+
+		It("reports the daemon's pull error", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			Expect(sess.Run(ctx, "morbyd/nada")).Error().To(MatchError(
+				"cannot run image morbyd/nada, reason: image pull failed, reason: " +
+					"pull access denied for morbyd/nada, repository does not exist or may require 'docker login'"))
+		})
+
 	})
 
 })
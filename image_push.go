@@ -21,10 +21,14 @@ import (
 
 	"github.com/moby/moby/client/pkg/jsonmessage"
 
+	"github.com/thediveo/morbyd/v2/internal/dockerconfig"
 	"github.com/thediveo/morbyd/v2/push"
 )
 
-// PushImage pushes a container image to a container registry.
+// PushImage pushes a container image to a container registry. If the session
+// has been configured using [session.WithRegistryAuthFromDockerConfig], the
+// registry credentials are taken from the Docker client configuration, unless
+// explicitly specified using [push.WithRegistryAuth].
 func (s *Session) PushImage(ctx context.Context, image string, opts ...push.Opt) error {
 	popts := push.Options{}
 	for _, opt := range opts {
@@ -35,6 +39,13 @@ func (s *Session) PushImage(ctx context.Context, image string, opts ...push.Opt)
 	if popts.Out == nil {
 		popts.Out = io.Discard
 	}
+	if popts.RegistryAuth == "" && s.opts.RegistryAuthFromDockerConfig {
+		auth, err := dockerconfig.EncodedAuth(image)
+		if err != nil {
+			return fmt.Errorf("image push failed, reason: %w", err)
+		}
+		popts.RegistryAuth = auth
+	}
 	r, err := s.moby.ImagePush(ctx, image, popts.ImagePushOptions)
 	if err != nil {
 		return fmt.Errorf("image push failed, reason: %w", err)
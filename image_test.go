@@ -0,0 +1,163 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/thediveo/morbyd/v2/build"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/remove"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("images", func() {
+
+	var engine *fakeengine.Engine
+	var sess *Session
+
+	BeforeEach(func(ctx context.Context) {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		engine = fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		sess = Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=image")))
+		DeferCleanup(func(ctx context.Context) { sess.Close(ctx) })
+	})
+
+	It("inspects a built image", func(ctx context.Context) {
+		id := Successful(sess.BuildImageFromFS(ctx, fstest.MapFS{},
+			build.WithTag("morbyd/inspectee"),
+			build.WithDockerfileContent(`FROM busybox
+ENV FOO=bar
+LABEL morbyd.test=inspectee
+EXPOSE 8080/tcp 53/udp
+USER nobody:nogroup
+WORKDIR /srv
+ENTRYPOINT ["/bin/sh", "-c"]
+CMD ["sleep infinity"]
+`)))
+
+		img := Successful(sess.Image(ctx, "morbyd/inspectee"))
+		Expect(img.Ref).To(Equal("morbyd/inspectee"))
+		Expect(img.ID()).To(Equal(id))
+		Expect(img.Tags()).To(ConsistOf("morbyd/inspectee:latest"))
+		Expect(img.RepoDigests()).To(ConsistOf(HavePrefix("morbyd/inspectee@sha256:")))
+		Expect(img.Created()).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(img.Platform()).To(And(
+			HaveField("OS", "linux"),
+			HaveField("Architecture", "amd64")))
+		Expect(img.Size()).To(BeNumerically(">", 0))
+		Expect(img.Env()).To(ContainElement("FOO=bar"))
+		Expect(img.Entrypoint()).To(Equal([]string{"/bin/sh", "-c"}))
+		Expect(img.Cmd()).To(Equal([]string{"sleep infinity"}))
+		Expect(img.ExposedPorts()).To(Equal([]string{"53/udp", "8080/tcp"}))
+		Expect(img.Labels()).To(HaveKeyWithValue("morbyd.test", "inspectee"))
+		Expect(img.User()).To(Equal("nobody:nogroup"))
+		Expect(img.WorkingDir()).To(Equal("/srv"))
+		Expect(img.Layers()).To(HaveLen(1))
+
+		history := Successful(img.History(ctx))
+		Expect(history).To(HaveLen(9))
+		Expect(history[0]).To(And(
+			HaveField("ID", id),
+			HaveField("CreatedBy", `CMD ["sleep infinity"]`),
+			HaveField("Created", BeTemporally("~", time.Now(), time.Minute))))
+		Expect(history[7].CreatedBy).To(Equal("FROM busybox"))
+
+		By("refreshing")
+		Expect(img.Tag(ctx, "morbyd/inspectee:other")).To(Succeed())
+		Expect(img.Refresh(ctx)).To(Succeed())
+		Expect(img.Tags()).To(ConsistOf("morbyd/inspectee:latest", "morbyd/inspectee:other"))
+	})
+
+	It("tags, runs, pushes and removes an image", func(ctx context.Context) {
+		img := Successful(sess.Image(ctx, "busybox"))
+		Expect(img.Tag(ctx, "registry.example.com/busybox:canary")).To(Succeed())
+		Expect(sess.HasImage(ctx, "registry.example.com/busybox:canary")).To(BeTrue())
+
+		cntr := Successful(img.Run(ctx))
+		Expect(cntr.Details.Container.Image).To(Equal(img.ID()))
+		Expect(cntr.Details.Container.Config.Image).To(Equal("busybox"))
+		Expect(cntr.Details.Container.Config.Labels).To(
+			HaveKeyWithValue(ContainerRunnerLabelName, ContainSubstring("image_test.go")))
+		cntr.Kill(ctx)
+
+		canary := Successful(sess.Image(ctx, "registry.example.com/busybox:canary"))
+		Expect(canary.Push(ctx)).To(Succeed())
+
+		Expect(canary.Remove(ctx, remove.WithForce())).To(Succeed())
+		Expect(sess.HasImage(ctx, "busybox")).To(BeFalse())
+		Expect(canary.Refresh(ctx)).To(MatchError(errdefs.IsNotFound, "IsNotFound"))
+		Expect(canary.History(ctx)).Error().To(MatchError(errdefs.IsNotFound, "IsNotFound"))
+		Expect(canary.Run(ctx, run.WithPullPolicy(run.PullNever))).Error().To(HaveOccurred())
+	})
+
+	It("runs but doesn't push an image looked up by ID", func(ctx context.Context) {
+		id := Successful(sess.Image(ctx, "busybox")).ID()
+		for _, ref := range []string{id, strings.TrimPrefix(id, "sha256:")[:12]} {
+			img := Successful(sess.Image(ctx, ref))
+			cntr := Successful(img.Run(ctx))
+			Expect(cntr.Details.Container.Config.Image).To(Equal(id))
+			cntr.Kill(ctx)
+			Expect(img.Push(ctx)).To(And(
+				MatchError(errdefs.IsInvalidArgument, "IsInvalidArgument"),
+				MatchError(ContainSubstring("image looked up by ID"))))
+		}
+	})
+
+	It("reports missing images", func(ctx context.Context) {
+		Expect(sess.Image(ctx, "morbyd/nada")).Error().To(And(
+			MatchError(errdefs.IsNotFound, "IsNotFound"),
+			MatchError(ContainSubstring("cannot inspect image morbyd/nada"))))
+	})
+
+	It("handles images without configuration", func() {
+		img := &Image{}
+		Expect(img.Env()).To(BeNil())
+		Expect(img.Cmd()).To(BeNil())
+		Expect(img.Entrypoint()).To(BeNil())
+		Expect(img.ExposedPorts()).To(BeNil())
+		Expect(img.Labels()).To(BeNil())
+		Expect(img.User()).To(BeEmpty())
+		Expect(img.WorkingDir()).To(BeEmpty())
+		Expect(img.Created()).To(BeZero())
+	})
+
+	It("reports history failures", func(ctx context.Context) {
+		img := Successful(sess.Image(ctx, "busybox"))
+		engine.FailNext("ImageHistory", errors.New("error IJK305I"))
+		Expect(img.History(ctx)).Error().To(MatchError(ContainSubstring("error IJK305I")))
+	})
+
+})
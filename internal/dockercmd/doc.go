@@ -0,0 +1,5 @@
+/*
+Package dockercmd renders copy-pasteable Docker CLI command lines, quoting
+arguments for POSIX shells where necessary.
+*/
+package dockercmd
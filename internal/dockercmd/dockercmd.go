@@ -0,0 +1,110 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockercmd
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Command accumulates the arguments of a Docker CLI command line.
+type Command struct {
+	args []string
+}
+
+// New returns a new Command starting with the specified arguments, such as
+// “docker” and “run”.
+func New(args ...string) *Command {
+	return &Command{args: slices.Clone(args)}
+}
+
+// Args returns the unquoted arguments of the command line.
+func (c *Command) Args() []string {
+	return slices.Clone(c.args)
+}
+
+// Add adds the specified arguments as they are.
+func (c *Command) Add(args ...string) {
+	c.args = append(c.args, args...)
+}
+
+// Flag adds the named flag with the specified value in “--name=value” format,
+// but only if the value isn't empty.
+func (c *Command) Flag(name string, value string) {
+	if value == "" {
+		return
+	}
+	c.args = append(c.args, "--"+name+"="+value)
+}
+
+// Flags adds the named flag with each of the specified values.
+func (c *Command) Flags(name string, values []string) {
+	for _, value := range values {
+		c.args = append(c.args, "--"+name+"="+value)
+	}
+}
+
+// Map adds the named flag with each of the specified map entries in
+// “key=value” format, in order of the keys.
+func (c *Command) Map(name string, m map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		c.args = append(c.args, "--"+name+"="+key+"="+m[key])
+	}
+}
+
+// Bool adds the named flag without any value, but only if set.
+func (c *Command) Bool(name string, set bool) {
+	if !set {
+		return
+	}
+	c.args = append(c.args, "--"+name)
+}
+
+// String returns the command line with its arguments quoted where necessary.
+func (c *Command) String() string {
+	quoted := make([]string, 0, len(c.args))
+	for _, arg := range c.args {
+		quoted = append(quoted, Quote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Quote returns the argument quoted for a POSIX shell, but only if necessary.
+// Quote uses single quotes, so shells won't expand anything inside.
+func Quote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, unsafe) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// unsafe returns true if the rune needs quoting in a POSIX shell.
+func unsafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("@%+=:,./_-", r)
+}
+
+// CSV returns the field quoted for comma-separated values, such as the fields
+// of “--mount” and “--network” flags, but only if necessary.
+func CSV(field string) string {
+	if !strings.ContainsAny(field, ",\"\n") {
+		return field
+	}
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockercmd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("docker CLI command lines", func() {
+
+	DescribeTable("quoting arguments",
+		func(arg string, expected string) {
+			Expect(Quote(arg)).To(Equal(expected))
+		},
+		Entry("plain", "busybox:latest", "busybox:latest"),
+		Entry("flag", "--env=FOO=/bar,baz", "--env=FOO=/bar,baz"),
+		Entry("empty", "", "''"),
+		Entry("whitespace", "echo hello", "'echo hello'"),
+		Entry("expansion", "$HOME", "'$HOME'"),
+		Entry("brackets", "[::1]:80", "'[::1]:80'"),
+		Entry("single quotes", "it's", `'it'\''s'`),
+	)
+
+	DescribeTable("quoting CSV fields",
+		func(field string, expected string) {
+			Expect(CSV(field)).To(Equal(expected))
+		},
+		Entry("plain", "src=/foo", "src=/foo"),
+		Entry("comma", "volume-opt=o=a,b", `"volume-opt=o=a,b"`),
+		Entry("double quotes", `a"b`, `"a""b"`),
+	)
+
+	It("renders command lines", func() {
+		cmd := New("docker", "run")
+		cmd.Bool("rm", true)
+		cmd.Bool("tty", false)
+		cmd.Flag("name", "foo")
+		cmd.Flag("user", "")
+		cmd.Flags("env", []string{"FOO=BAR", "GREETING=hello world"})
+		cmd.Map("label", map[string]string{"z": "1", "a": "2"})
+		cmd.Add("busybox", "/bin/sh", "-c", "echo $GREETING")
+		Expect(cmd.String()).To(Equal(
+			"docker run --rm --name=foo --env=FOO=BAR '--env=GREETING=hello world' " +
+				"--label=a=2 --label=z=1 busybox /bin/sh -c 'echo $GREETING'"))
+		Expect(cmd.Args()).To(HaveLen(12))
+	})
+
+})
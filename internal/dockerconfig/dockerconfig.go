@@ -0,0 +1,142 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dockerconfig resolves registry credentials from the Docker client
+// configuration in “config.json”, including credential stores and
+// per-registry credential helpers.
+package dockerconfig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	clitypes "github.com/docker/cli/cli/config/types"
+	"github.com/moby/moby/api/types/registry"
+)
+
+// Dir returns the directory of the Docker client configuration, which is
+// either specified by the DOCKER_CONFIG environment variable, or otherwise
+// defaults to “~/.docker”.
+func Dir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".docker"
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// Load returns the Docker client configuration. A missing configuration file
+// is not an error, but results in an empty configuration.
+func Load() (*configfile.ConfigFile, error) {
+	cf, err := config.Load(Dir())
+	if err != nil {
+		return nil, fmt.Errorf("cannot load Docker client configuration, reason: %w", err)
+	}
+	return cf, nil
+}
+
+// RegistryHost returns the registry host of the specified image reference,
+// such as “docker.io” for “busybox”.
+func RegistryHost(imageref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageref)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q, reason: %w", imageref, err)
+	}
+	return reference.Domain(named), nil
+}
+
+// EncodedAuth returns the base64 encoded credentials for the registry of the
+// specified image reference, as required by the Docker API for pulling and
+// pushing images. If there are no credentials for the registry, EncodedAuth
+// returns "".
+func EncodedAuth(imageref string) (string, error) {
+	host, err := RegistryHost(imageref)
+	if err != nil {
+		return "", err
+	}
+	cf, err := Load()
+	if err != nil {
+		return "", err
+	}
+	authcfg, err := cf.GetAuthConfig(host)
+	if err != nil {
+		return "", fmt.Errorf("cannot retrieve credentials for registry %s, reason: %w", host, err)
+	}
+	auth := authConfig(authcfg)
+	if !hasCredentials(auth) {
+		return "", nil
+	}
+	return Encode(auth)
+}
+
+// AuthConfigs returns the credentials of all registries known to the Docker
+// client configuration, including the registries with credential helpers,
+// indexed by registry host, as required by the Docker API for building
+// images.
+func AuthConfigs() (map[string]registry.AuthConfig, error) {
+	cf, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	authcfgs, err := cf.GetAllCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve registry credentials, reason: %w", err)
+	}
+	auths := make(map[string]registry.AuthConfig, len(authcfgs))
+	for host, authcfg := range authcfgs {
+		if auth := authConfig(authcfg); hasCredentials(auth) {
+			auths[host] = auth
+		}
+	}
+	return auths, nil
+}
+
+// Encode returns the specified registry credentials in the base64url encoded
+// format of the Docker API.
+func Encode(auth registry.AuthConfig) (string, error) {
+	b, err := json.Marshal(auth)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode registry credentials, reason: %w", err)
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
+
+// authConfig returns the Docker API registry credentials for the specified
+// Docker client credentials.
+func authConfig(authcfg clitypes.AuthConfig) registry.AuthConfig {
+	return registry.AuthConfig{
+		Username:      authcfg.Username,
+		Password:      authcfg.Password,
+		Auth:          authcfg.Auth,
+		ServerAddress: authcfg.ServerAddress,
+		IdentityToken: authcfg.IdentityToken,
+		RegistryToken: authcfg.RegistryToken,
+	}
+}
+
+// hasCredentials returns true if the specified registry credentials contain
+// any actual credentials, not just the server address.
+func hasCredentials(auth registry.AuthConfig) bool {
+	return auth.Username != "" || auth.Password != "" || auth.Auth != "" ||
+		auth.IdentityToken != "" || auth.RegistryToken != ""
+}
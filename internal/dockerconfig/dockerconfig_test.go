@@ -0,0 +1,136 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockerconfig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/moby/api/types/registry"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+// fakeHelper is a fake Docker credential helper that knows only about
+// registry.example.com.
+const fakeHelper = `#!/bin/sh
+case "$1" in
+get)
+	read server
+	if [ "$server" = "registry.example.com" ]; then
+		printf '{"ServerURL":"%s","Username":"helpeduser","Secret":"helpedsecret"}' "$server"
+		exit 0
+	fi
+	echo "credentials not found in native keychain"
+	exit 1
+	;;
+list)
+	printf '{"registry.example.com":"helpeduser"}'
+	;;
+*)
+	exit 1
+	;;
+esac
+`
+
+// decode returns the registry credentials from the specified base64url
+// encoded credentials.
+func decode(encoded string) registry.AuthConfig {
+	GinkgoHelper()
+	var auth registry.AuthConfig
+	Expect(json.Unmarshal(Successful(base64.URLEncoding.DecodeString(encoded)), &auth)).To(Succeed())
+	return auth
+}
+
+var _ = Describe("Docker client configuration", func() {
+
+	BeforeEach(func() {
+		tmpdir := GinkgoT().TempDir()
+		bindir := filepath.Join(tmpdir, "bin")
+		Expect(os.Mkdir(bindir, 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(bindir, "docker-credential-morbydfake"),
+			[]byte(fakeHelper), 0o755)).To(Succeed())
+		GinkgoT().Setenv("PATH", bindir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		cfgdir := filepath.Join(tmpdir, "docker")
+		Expect(os.Mkdir(cfgdir, 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cfgdir, "config.json"), []byte(`{
+	"auths": {
+		"https://index.docker.io/v1/": {"auth": "`+base64.StdEncoding.EncodeToString([]byte("hubuser:hubsecret"))+`"}
+	},
+	"credHelpers": {
+		"registry.example.com": "morbydfake"
+	}
+}`), 0o600)).To(Succeed())
+		GinkgoT().Setenv("DOCKER_CONFIG", cfgdir)
+	})
+
+	It("determines the configuration directory", func() {
+		Expect(Dir()).To(Equal(os.Getenv("DOCKER_CONFIG")))
+		GinkgoT().Setenv("DOCKER_CONFIG", "")
+		GinkgoT().Setenv("HOME", "/home/morbyd")
+		Expect(Dir()).To(Equal("/home/morbyd/.docker"))
+	})
+
+	It("determines registry hosts", func() {
+		Expect(RegistryHost("busybox")).To(Equal("docker.io"))
+		Expect(RegistryHost("registry.example.com:5000/foo/bar:latest")).To(Equal("registry.example.com:5000"))
+		Expect(RegistryHost("Foo")).Error().To(HaveOccurred())
+	})
+
+	It("returns credentials from the configuration file", func() {
+		auth := decode(Successful(EncodedAuth("busybox:latest")))
+		Expect(auth.Username).To(Equal("hubuser"))
+		Expect(auth.Password).To(Equal("hubsecret"))
+	})
+
+	It("returns credentials from a credential helper", func() {
+		auth := decode(Successful(EncodedAuth("registry.example.com/foo/bar")))
+		Expect(auth.Username).To(Equal("helpeduser"))
+		Expect(auth.Password).To(Equal("helpedsecret"))
+	})
+
+	It("returns no credentials for unknown registries", func() {
+		Expect(EncodedAuth("registry.example.org/foo")).To(BeEmpty())
+	})
+
+	It("returns all credentials", func() {
+		auths := Successful(AuthConfigs())
+		Expect(auths).To(HaveKeyWithValue("registry.example.com",
+			And(HaveField("Username", "helpeduser"), HaveField("Password", "helpedsecret"))))
+		Expect(auths).To(HaveKeyWithValue("https://index.docker.io/v1/",
+			And(HaveField("Username", "hubuser"), HaveField("Password", "hubsecret"))))
+	})
+
+	It("reports broken configurations and credential helpers", func() {
+		Expect(os.WriteFile(filepath.Join(os.Getenv("DOCKER_CONFIG"), "config.json"),
+			[]byte(`{"credsStore": "nonexisting"}`), 0o600)).To(Succeed())
+		Expect(EncodedAuth("busybox")).Error().To(
+			MatchError(ContainSubstring("cannot retrieve credentials for registry docker.io")))
+		Expect(AuthConfigs()).Error().To(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(os.Getenv("DOCKER_CONFIG"), "config.json"),
+			[]byte(`{`), 0o600)).To(Succeed())
+		Expect(EncodedAuth("busybox")).Error().To(
+			MatchError(ContainSubstring("cannot load Docker client configuration")))
+		Expect(AuthConfigs()).Error().To(HaveOccurred())
+		Expect(EncodedAuth("Foo")).Error().To(HaveOccurred())
+	})
+
+})
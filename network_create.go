@@ -17,6 +17,7 @@ package morbyd
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"maps"
 
 	"github.com/moby/moby/client"
@@ -39,7 +40,7 @@ import (
 // See also: [docker network create]
 //
 // [docker network create]: https://docs.docker.com/engine/reference/commandline/network_create/
-func (s *Session) CreateNetwork(ctx context.Context, name string, opts ...net.Opt) (*Network, error) {
+func (s *Session) CreateNetwork(ctx context.Context, name string, opts ...net.Opt) (_ *Network, err error) {
 	nopts := net.Options{
 		Labels: map[string]string{},
 	}
@@ -50,11 +51,17 @@ func (s *Session) CreateNetwork(ctx context.Context, name string, opts ...net.Op
 		}
 	}
 
+	ctx, op := s.begin(ctx, "network.create",
+		slog.String(AttrNetworkName, name),
+		slog.String(AttrCaller, caller(1, 0)))
+	defer func() { op.end(err) }()
+
 	createResp, err := s.moby.NetworkCreate(ctx, name, client.NetworkCreateOptions(nopts))
 	if err != nil {
 		return nil, fmt.Errorf("cannot create new network %q, reason: %w",
 			name, err)
 	}
+	op.set(slog.String(AttrNetworkID, createResp.ID))
 
 	detailsResp, err := s.moby.NetworkInspect(ctx, createResp.ID, client.NetworkInspectOptions{
 		Verbose: true,
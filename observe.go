@@ -0,0 +1,138 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerName is the instrumentation scope name of the tracer used for
+// creating session operation spans.
+const TracerName = "github.com/thediveo/morbyd/v2"
+
+// Attribute keys of session operation log records and span attributes.
+const (
+	AttrCaller        = "caller"
+	AttrContainerID   = "container.id"
+	AttrContainerName = "container.name"
+	AttrImage         = "image"
+	AttrImageID       = "image.id"
+	AttrBuildContext  = "build.context"
+	AttrNetworkID     = "network.id"
+	AttrNetworkName   = "network.name"
+	AttrExecID        = "exec.id"
+	AttrCommand       = "command"
+	AttrLabel         = "label"
+	AttrDuration      = "duration"
+	AttrError         = "error"
+	AttrReused        = "reused"
+	AttrShared        = "shared"
+)
+
+var (
+	discardLogger = slog.New(slog.DiscardHandler)
+	noopTracer    = noop.NewTracerProvider().Tracer(TracerName)
+)
+
+// logger returns the logger configured for this session, or a discarding
+// logger.
+func (s *Session) logger() *slog.Logger {
+	if s == nil || s.opts.Logger == nil {
+		return discardLogger
+	}
+	return s.opts.Logger
+}
+
+// tracer returns the tracer from the tracer provider configured for this
+// session, or a no-op tracer.
+func (s *Session) tracer() trace.Tracer {
+	if s == nil || s.opts.TracerProvider == nil {
+		return noopTracer
+	}
+	return s.opts.TracerProvider.Tracer(TracerName)
+}
+
+// operation is a session operation in progress that gets logged and traced.
+type operation struct {
+	ctx   context.Context
+	sess  *Session
+	name  string
+	start time.Time
+	span  trace.Span
+	attrs []slog.Attr
+}
+
+// begin a new session operation with the specified name and attributes,
+// returning the operation together with a context carrying the operation's
+// span.
+func (s *Session) begin(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *operation) {
+	ctx, span := s.tracer().Start(ctx, "morbyd."+name,
+		trace.WithAttributes(spanAttrs(attrs)...))
+	return ctx, &operation{
+		ctx:   ctx,
+		sess:  s,
+		name:  name,
+		start: time.Now(),
+		span:  span,
+		attrs: attrs,
+	}
+}
+
+// set additional attributes that become known only while the operation is in
+// progress, such as the ID of a newly created container.
+func (o *operation) set(attrs ...slog.Attr) {
+	o.attrs = append(o.attrs, attrs...)
+	o.span.SetAttributes(spanAttrs(attrs)...)
+}
+
+// end the operation, logging its outcome and duration, as well as ending its
+// span.
+func (o *operation) end(err error) {
+	duration := time.Since(o.start)
+	level := slog.LevelInfo
+	attrs := append(o.attrs, slog.Duration(AttrDuration, duration))
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String(AttrError, err.Error()))
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	}
+	o.span.End()
+	o.sess.logger().LogAttrs(o.ctx, level, o.name, attrs...)
+}
+
+// spanAttrs returns the span attributes corresponding with the specified log
+// attributes.
+func spanAttrs(attrs []slog.Attr) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch attr.Value.Kind() {
+		case slog.KindInt64:
+			kvs = append(kvs, attribute.Int64(attr.Key, attr.Value.Int64()))
+		case slog.KindBool:
+			kvs = append(kvs, attribute.Bool(attr.Key, attr.Value.Bool()))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, attr.Value.String()))
+		}
+	}
+	return kvs
+}
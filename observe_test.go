@@ -0,0 +1,181 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/thediveo/safe"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/thediveo/morbyd/v2/exec"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+// logRecords returns the JSON log records from the specified buffer.
+func logRecords(buff *safe.Buffer) []map[string]any {
+	GinkgoHelper()
+	recs := []map[string]any{}
+	for line := range strings.SplitSeq(strings.TrimSpace(buff.String()), "\n") {
+		var rec map[string]any
+		Expect(json.Unmarshal([]byte(line), &rec)).To(Succeed())
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+var _ = Describe("logging and tracing session operations", func() {
+
+	var engine *fakeengine.Engine
+
+	BeforeEach(func() {
+		engine = fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+	})
+
+	It("logs session operations", func(ctx context.Context) {
+		var buff safe.Buffer
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=observe"),
+			session.WithLogger(slog.New(slog.NewJSONHandler(&buff, nil)))))
+		defer sess.Close(ctx)
+
+		netw := Successful(sess.CreateNetwork(ctx, "morbyd-observe"))
+		cntr := Successful(sess.Run(ctx, "busybox",
+			run.WithName("morbyd-observe"),
+			run.WithNetwork(netw.ID)))
+		es := Successful(cntr.Exec(ctx, exec.Command("true")))
+		Expect(es.Wait(ctx)).To(BeZero())
+		sess.AutoClean(ctx)
+
+		recs := logRecords(&buff)
+		Expect(recs).To(ContainElements(
+			And(HaveKeyWithValue("msg", "network.create"),
+				HaveKeyWithValue(AttrNetworkName, "morbyd-observe"),
+				HaveKeyWithValue(AttrNetworkID, netw.ID),
+				HaveKeyWithValue(AttrCaller, ContainSubstring("observe_test.go")),
+				HaveKey(AttrDuration)),
+			And(HaveKeyWithValue("msg", "container.run"),
+				HaveKeyWithValue("level", "INFO"),
+				HaveKeyWithValue(AttrImage, "busybox"),
+				HaveKeyWithValue(AttrContainerID, cntr.ID),
+				HaveKeyWithValue(AttrContainerName, "morbyd-observe"),
+				HaveKeyWithValue(AttrCaller, ContainSubstring("observe_test.go"))),
+			And(HaveKeyWithValue("msg", "container.exec"),
+				HaveKeyWithValue(AttrExecID, es.ID),
+				HaveKeyWithValue(AttrCommand, "true")),
+			And(HaveKeyWithValue("msg", "autoclean.container"),
+				HaveKeyWithValue(AttrContainerID, cntr.ID)),
+			And(HaveKeyWithValue("msg", "autoclean.network"),
+				HaveKeyWithValue(AttrNetworkID, netw.ID)),
+			And(HaveKeyWithValue("msg", "autoclean"),
+				HaveKeyWithValue(AttrLabel, "test.morbyd=observe")),
+		))
+	})
+
+	It("logs failures", func(ctx context.Context) {
+		var buff safe.Buffer
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=observe"),
+			session.WithLogger(slog.New(slog.NewJSONHandler(&buff, nil)))))
+		defer sess.Close(ctx)
+
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		engine.FailNext("ContainerStop", errors.New("error DEAD-BEEF"))
+		cntr.Stop(ctx)
+		engine.FailNext("ContainerRemove", errors.New("error C0FFEE"))
+		sess.AutoClean(ctx)
+		Expect(sess.PullImage(ctx, "morbyd/nada")).Error().To(HaveOccurred())
+
+		Expect(logRecords(&buff)).To(ContainElements(
+			And(HaveKeyWithValue("msg", "container.stop"),
+				HaveKeyWithValue("level", "ERROR"),
+				HaveKeyWithValue(AttrError, "error DEAD-BEEF"),
+				HaveKeyWithValue(AttrCaller, ContainSubstring("observe_test.go"))),
+			And(HaveKeyWithValue("msg", "autoclean.container"),
+				HaveKeyWithValue("level", "ERROR"),
+				HaveKeyWithValue(AttrError, "error C0FFEE")),
+			And(HaveKeyWithValue("msg", "image.pull"),
+				HaveKeyWithValue("level", "ERROR"),
+				HaveKeyWithValue(AttrImage, "morbyd/nada"),
+				HaveKeyWithValue(AttrCaller, ContainSubstring("observe_test.go"))),
+		))
+	})
+
+	It("attributes implicit pulls to the callers", func(ctx context.Context) {
+		engine.AddRemoteImage(fakeengine.Image{Ref: "alpine", Cmd: []string{"sh"}})
+		engine.AddRemoteImage(fakeengine.Image{Ref: "debian", Cmd: []string{"bash"}})
+		var buff safe.Buffer
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithLogger(slog.New(slog.NewJSONHandler(&buff, nil)))))
+		defer sess.Close(ctx)
+
+		cntr := Successful(sess.Run(ctx, "alpine"))
+		defer cntr.Kill(ctx)
+		Expect(sess.EnsureImages(ctx, "debian")).To(Succeed())
+
+		Expect(logRecords(&buff)).To(ContainElements(
+			And(HaveKeyWithValue("msg", "image.pull"),
+				HaveKeyWithValue(AttrImage, "alpine"),
+				HaveKeyWithValue(AttrCaller, ContainSubstring("observe_test.go"))),
+			And(HaveKeyWithValue("msg", "image.pull"),
+				HaveKeyWithValue(AttrImage, "debian"),
+				HaveKeyWithValue(AttrCaller, ContainSubstring("observe_test.go"))),
+		))
+	})
+
+	It("creates spans", func(ctx context.Context) {
+		spanrec := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanrec))
+		defer func() { _ = tp.Shutdown(context.Background()) }()
+
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithTracerProvider(tp)))
+		defer sess.Close(ctx)
+
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		cntr.Kill(ctx)
+		Expect(sess.Run(ctx, "morbyd/nada")).Error().To(HaveOccurred())
+
+		spans := spanrec.Ended()
+		Expect(spans).To(HaveLen(4))
+		Expect(spans[0].Name()).To(Equal("morbyd.container.run"))
+		Expect(spans[0].InstrumentationScope().Name).To(Equal(TracerName))
+		Expect(spans[0].Attributes()).To(ContainElement(
+			HaveField("Value.AsString()", cntr.ID)))
+		Expect(spans[1].Name()).To(Equal("morbyd.container.kill"))
+		Expect(spans[2].Name()).To(Equal("morbyd.image.pull"))
+		Expect(spans[2].Parent().SpanID()).To(Equal(spans[3].SpanContext().SpanID()))
+		Expect(spans[3].Name()).To(Equal("morbyd.container.run"))
+		Expect(spans[3].Status().Code).To(Equal(codes.Error))
+	})
+
+})
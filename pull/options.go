@@ -31,6 +31,11 @@ type Opt func(*Options) error
 type Options struct {
 	Out io.Writer
 	client.ImagePullOptions
+
+	// If true, the registry credentials are taken from the Docker client
+	// configuration, unless explicitly specified; see also
+	// [WithAuthFromConfig].
+	AuthFromConfig bool
 }
 
 // WithOutput specifies the writer to send the output of the image pull process
@@ -65,6 +70,19 @@ func WithPlatform(platform string) Opt {
 	}
 }
 
+// WithAuthFromConfig specifies to take the credentials for the registry to pull
+// the image from from the Docker client configuration in “config.json”, unless
+// explicitly specified using [WithRegistryAuth]. This includes running the
+// “docker-credential-*” helpers configured in “credsStore” and “credHelpers”.
+// The configuration is read from the directory specified by the DOCKER_CONFIG
+// environment variable, defaulting to “~/.docker”.
+func WithAuthFromConfig() Opt {
+	return func(o *Options) error {
+		o.AuthFromConfig = true
+		return nil
+	}
+}
+
 // WithRegistryAuth specifies the base64 encoded credentials for the registry to
 // pull the image from.
 func WithRegistryAuth(base64cred string) Opt {
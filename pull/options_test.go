@@ -31,6 +31,7 @@ var _ = Describe("pull image options", func() {
 			WithAllTags(),
 			WithPlatform("plan9/wasm"),
 			WithRegistryAuth("deadfoobar"),
+			WithAuthFromConfig(),
 			WithOutput(io.Discard),
 		} {
 			Expect(opt(&popts)).NotTo(HaveOccurred())
@@ -39,6 +40,7 @@ var _ = Describe("pull image options", func() {
 		Expect(popts.Platforms).NotTo(BeEmpty())
 		Expect(popts.Platforms).To(ContainElement(Equal(v1.Platform{OS: "plan9", Architecture: "wasm"})))
 		Expect(popts.Out).To(BeIdenticalTo(io.Discard))
+		Expect(popts.AuthFromConfig).To(BeTrue())
 	})
 
 	It("rejects invalid platforms", func() {
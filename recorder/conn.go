@@ -0,0 +1,159 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/moby/moby/client"
+)
+
+// recordingConn records the data read from and written to a hijacked
+// connection, calling done once when reading has reached EOF or failed, or the
+// connection has been closed.
+type recordingConn struct {
+	net.Conn
+	r    io.Reader // the hijacked response's (buffered) reader.
+	done func(stream, input []byte, err error)
+
+	mu     sync.Mutex // protects the following fields.
+	stream bytes.Buffer
+	input  bytes.Buffer
+	ended  bool
+}
+
+var _ client.CloseWriter = (*recordingConn)(nil)
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.mu.Lock()
+	c.stream.Write(p[:n])
+	c.mu.Unlock()
+	if err != nil {
+		c.end(err)
+	}
+	return n, err
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.mu.Lock()
+	c.input.Write(p[:n])
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c *recordingConn) Close() error {
+	err := c.Conn.Close()
+	c.end(nil)
+	return err
+}
+
+func (c *recordingConn) CloseWrite() error {
+	if cw, ok := c.Conn.(client.CloseWriter); ok {
+		return cw.CloseWrite()
+	}
+	return nil
+}
+
+// end the recording, passing on any error other than EOF.
+func (c *recordingConn) end(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	c.mu.Lock()
+	if c.ended {
+		c.mu.Unlock()
+		return
+	}
+	c.ended = true
+	stream := bytes.Clone(c.stream.Bytes())
+	input := bytes.Clone(c.input.Bytes())
+	c.mu.Unlock()
+	c.done(stream, input, err)
+}
+
+// recordingReadCloser records the data read from a stream, calling done once
+// when reading has reached EOF or failed, or the stream has been closed.
+type recordingReadCloser struct {
+	rc   io.ReadCloser
+	done func(stream []byte, err error)
+
+	mu     sync.Mutex // protects the following fields.
+	stream bytes.Buffer
+	ended  bool
+}
+
+func (s *recordingReadCloser) Read(p []byte) (int, error) {
+	n, err := s.rc.Read(p)
+	s.mu.Lock()
+	s.stream.Write(p[:n])
+	s.mu.Unlock()
+	if err != nil {
+		s.end(err)
+	}
+	return n, err
+}
+
+func (s *recordingReadCloser) Close() error {
+	err := s.rc.Close()
+	s.end(nil)
+	return err
+}
+
+// end the recording, passing on any error other than EOF.
+func (s *recordingReadCloser) end(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	stream := bytes.Clone(s.stream.Bytes())
+	s.mu.Unlock()
+	s.done(stream, err)
+}
+
+// replayConn is a hijacked connection serving recorded stream data, while
+// discarding all data written to it.
+type replayConn struct {
+	r *bytes.Reader
+}
+
+var _ client.CloseWriter = (*replayConn)(nil)
+
+func (c *replayConn) Read(p []byte) (int, error)  { return c.r.Read(p) }
+func (c *replayConn) Write(p []byte) (int, error) { return len(p), nil }
+func (c *replayConn) Close() error                { return nil }
+func (c *replayConn) CloseWrite() error           { return nil }
+
+func (c *replayConn) LocalAddr() net.Addr                { return replayAddr{} }
+func (c *replayConn) RemoteAddr() net.Addr               { return replayAddr{} }
+func (c *replayConn) SetDeadline(t time.Time) error      { return nil }
+func (c *replayConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *replayConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type replayAddr struct{}
+
+func (replayAddr) Network() string { return "replay" }
+func (replayAddr) String() string  { return "replay" }
@@ -0,0 +1,42 @@
+/*
+Package recorder provides a recording decorator for
+[github.com/thediveo/morbyd/v2/moby.Client] that logs every Docker API call
+with its arguments, timing, result, and error to a JSON-lines log, as well as a
+replaying client that serves the recorded results back without any Docker
+daemon.
+
+Recording is especially useful to diagnose flaky CI failures, as it shows what
+morbyd sent to the daemon and what it got back:
+
+	f, _ := os.Create("docker-api.jsonl")
+	defer f.Close()
+	sess, err := morbyd.NewSession(ctx, recorder.WithRecording(f))
+
+A recorded test sequence, such as [github.com/thediveo/morbyd/v2.Session.Run],
+[github.com/thediveo/morbyd/v2.Container.Exec], and
+[github.com/thediveo/morbyd/v2.Container.Wait], can then be replayed
+deterministically:
+
+	f, _ := os.Open("docker-api.jsonl")
+	defer f.Close()
+	sess, err := morbyd.NewSession(ctx, recorder.WithReplay(f))
+
+# Log Format
+
+Each line of a log is a JSON-encoded [Record]. Calls returning streams, such as
+attaching to containers and command executions, pulling, pushing, and building
+images, get a second “follow-up” record when their stream has been fully read
+or closed; this follow-up record refers to the call's record and contains the
+stream data. Similarly, [moby.Client.ContainerWait] calls get a follow-up
+record with the outcome of waiting.
+
+Registry credentials are redacted in the log. Build contexts are not recorded.
+
+# Replay
+
+When replaying, recorded calls are served in the order they were recorded, but
+separately for each API method. This keeps replays deterministic even when the
+code under test issues API calls from concurrent go routines. Replay neither
+checks the call arguments nor reproduces the recorded timing.
+*/
+package recorder
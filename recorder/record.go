@@ -0,0 +1,130 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/containerd/errdefs"
+)
+
+// Record is a single recorded Docker API call, or the follow-up of a call
+// returning a stream or waiting for a container.
+type Record struct {
+	Seq      uint64            `json:"seq"`               // sequence number, starting at 1.
+	Follows  uint64            `json:"follows,omitempty"` // sequence number of the call followed up on.
+	Method   string            `json:"method"`            // name of the moby.Client method.
+	Args     []json.RawMessage `json:"args,omitempty"`    // call arguments, except for the context.
+	Start    time.Time         `json:"start"`             // when the call was made.
+	Duration time.Duration     `json:"duration"`          // until the call returned, or the stream ended.
+	Result   json.RawMessage   `json:"result,omitempty"`  // result, unless there was an error.
+	Error    *Error            `json:"error,omitempty"`   // error, if any.
+	Stream   []byte            `json:"stream,omitempty"`  // stream data read by the client.
+	Input    []byte            `json:"input,omitempty"`   // stream data written by the client.
+}
+
+// Error is a recorded error, consisting of its message and its [errdefs]
+// class, such as “not-found” or “conflict”.
+//
+// [errdefs]: https://pkg.go.dev/github.com/containerd/errdefs
+type Error struct {
+	Message string `json:"message"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// errorKinds maps error classes to their checks and sentinel errors. The
+// first matching class wins.
+var errorKinds = []struct {
+	kind     string
+	is       func(error) bool
+	sentinel error
+}{
+	{"canceled", func(err error) bool { return errors.Is(err, context.Canceled) }, context.Canceled},
+	{"deadline-exceeded", func(err error) bool { return errors.Is(err, context.DeadlineExceeded) }, context.DeadlineExceeded},
+	{"not-found", errdefs.IsNotFound, errdefs.ErrNotFound},
+	{"conflict", errdefs.IsConflict, errdefs.ErrConflict},
+	{"already-exists", errdefs.IsAlreadyExists, errdefs.ErrAlreadyExists},
+	{"invalid-argument", errdefs.IsInvalidArgument, errdefs.ErrInvalidArgument},
+	{"permission-denied", errdefs.IsPermissionDenied, errdefs.ErrPermissionDenied},
+	{"unauthorized", errdefs.IsUnauthorized, errdefs.ErrUnauthenticated},
+	{"unavailable", errdefs.IsUnavailable, errdefs.ErrUnavailable},
+	{"not-implemented", errdefs.IsNotImplemented, errdefs.ErrNotImplemented},
+	{"not-modified", errdefs.IsNotModified, errdefs.ErrNotModified},
+	{"failed-precondition", errdefs.IsFailedPrecondition, errdefs.ErrFailedPrecondition},
+	{"internal", errdefs.IsInternal, errdefs.ErrInternal},
+}
+
+// newError returns the recorded form of the specified error, or nil if there
+// is no error.
+func newError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{Message: err.Error()}
+	for _, k := range errorKinds {
+		if k.is(err) {
+			e.Kind = k.kind
+			break
+		}
+	}
+	return e
+}
+
+// Err returns an error with the recorded message that matches the recorded
+// error class, so that checks such as [errdefs.IsNotFound] work the same as
+// for the original error. Err returns nil for a nil *Error.
+//
+// [errdefs.IsNotFound]: https://pkg.go.dev/github.com/containerd/errdefs#IsNotFound
+func (e *Error) Err() error {
+	if e == nil {
+		return nil
+	}
+	for _, k := range errorKinds {
+		if k.kind != e.Kind {
+			continue
+		}
+		if e.Message == k.sentinel.Error() {
+			return k.sentinel
+		}
+		if wm, ok := k.sentinel.(interface{ WithMessage(string) error }); ok {
+			return wm.WithMessage(e.Message)
+		}
+		return fmt.Errorf("%s: %w", e.Message, k.sentinel)
+	}
+	return errors.New(e.Message)
+}
+
+// encode returns the JSON encoding of the specified value, falling back to
+// a JSON string of its Go representation if the value cannot be encoded.
+func encode(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%+v", v))
+	}
+	return b
+}
+
+// encodeArgs returns the JSON encodings of the specified call arguments.
+func encodeArgs(args ...any) []json.RawMessage {
+	enc := make([]json.RawMessage, 0, len(args))
+	for _, arg := range args {
+		enc = append(enc, encode(arg))
+	}
+	return enc
+}
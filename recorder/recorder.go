@@ -0,0 +1,496 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/registry"
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/internal/jsonmsgs"
+	"github.com/thediveo/morbyd/v2/moby"
+	"github.com/thediveo/morbyd/v2/session"
+)
+
+// Redacted replaces registry credentials in recorded call arguments.
+const Redacted = "REDACTED"
+
+// Recorder is a [moby.Client] decorator that records all calls to the
+// decorated client, writing them as JSON lines to a log.
+type Recorder struct {
+	client moby.Client
+	seq    atomic.Uint64
+
+	mu  sync.Mutex // protects the following fields.
+	enc *json.Encoder
+	err error // first error writing to the log.
+}
+
+var (
+	_ moby.Client         = (*Recorder)(nil)
+	_ client.HijackDialer = (*Recorder)(nil)
+)
+
+// New returns a new Recorder that decorates the specified client and writes
+// the records of all calls to the specified writer.
+func New(client moby.Client, w io.Writer) *Recorder {
+	return &Recorder{
+		client: client,
+		enc:    json.NewEncoder(w),
+	}
+}
+
+// WithRecording returns a session option that records all Docker API calls of
+// the session to the specified writer, in JSON-lines format. If the session
+// already has a client wrapper configured, the calls to the wrapped client get
+// recorded.
+func WithRecording(w io.Writer) session.Opt {
+	return func(o *session.Options) error {
+		wrapper := o.Wrapper
+		o.Wrapper = func(client moby.Client) moby.Client {
+			if wrapper != nil {
+				client = wrapper(client)
+			}
+			return New(client, w)
+		}
+		return nil
+	}
+}
+
+// Err returns the first error encountered while writing to the log, if any.
+// After such an error, no further records are written.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// write a record to the log, unless writing has failed before.
+func (r *Recorder) write(rec *Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(rec)
+}
+
+// begin a new record for a call to the named method with the specified
+// arguments.
+func (r *Recorder) begin(method string, args ...any) *Record {
+	return &Record{
+		Seq:    r.seq.Add(1),
+		Method: method,
+		Args:   encodeArgs(args...),
+		Start:  time.Now(),
+	}
+}
+
+// end a record with the specified call result and error, and write it.
+func (r *Recorder) end(rec *Record, res any, err error) {
+	rec.Duration = time.Since(rec.Start)
+	rec.Error = newError(err)
+	if err == nil && res != nil {
+		rec.Result = encode(res)
+	}
+	r.write(rec)
+}
+
+// follow up on the specified call record, writing a new record with the
+// stream data and error.
+func (r *Recorder) follow(call *Record, res any, stream, input []byte, err error) {
+	rec := &Record{
+		Seq:      r.seq.Add(1),
+		Follows:  call.Seq,
+		Method:   call.Method,
+		Start:    call.Start,
+		Duration: time.Since(call.Start),
+		Error:    newError(err),
+		Stream:   stream,
+		Input:    input,
+	}
+	if err == nil && res != nil {
+		rec.Result = encode(res)
+	}
+	r.write(rec)
+}
+
+// record a call to the named method with the specified arguments.
+func record[R any](r *Recorder, method string, args []any, call func() (R, error)) (R, error) {
+	rec := r.begin(method, args...)
+	res, err := call()
+	r.end(rec, res, err)
+	return res, err
+}
+
+// Close the decorated client.
+func (r *Recorder) Close() error {
+	_, err := record(r, "Close", nil, func() (struct{}, error) {
+		return struct{}{}, r.client.Close()
+	})
+	return err
+}
+
+// ContainerAttach records attaching to a container; the data read from the
+// container and written to it gets recorded in a follow-up record after the
+// connection has been closed or reached EOF.
+func (r *Recorder) ContainerAttach(ctx context.Context, containerID string, options client.ContainerAttachOptions) (client.ContainerAttachResult, error) {
+	rec := r.begin("ContainerAttach", containerID, options)
+	res, err := r.client.ContainerAttach(ctx, containerID, options)
+	r.end(rec, nil, err)
+	if err != nil {
+		return res, err
+	}
+	return client.ContainerAttachResult{HijackedResponse: r.hijacked(rec, res.HijackedResponse)}, nil
+}
+
+// hijacked returns a new hijacked response that records the data read from
+// and written to the specified hijacked response.
+func (r *Recorder) hijacked(call *Record, resp client.HijackedResponse) client.HijackedResponse {
+	mediaType, _ := resp.MediaType()
+	conn := &recordingConn{
+		Conn: resp.Conn,
+		r:    resp.Reader,
+		done: func(stream, input []byte, err error) {
+			r.follow(call, nil, stream, input, err)
+		},
+	}
+	return client.NewHijackedResponse(conn, mediaType)
+}
+
+// ContainerCreate records creating a container.
+func (r *Recorder) ContainerCreate(ctx context.Context, options client.ContainerCreateOptions) (client.ContainerCreateResult, error) {
+	return record(r, "ContainerCreate", []any{options}, func() (client.ContainerCreateResult, error) {
+		return r.client.ContainerCreate(ctx, options)
+	})
+}
+
+// ContainerInspect records inspecting a container.
+func (r *Recorder) ContainerInspect(ctx context.Context, containerID string, options client.ContainerInspectOptions) (client.ContainerInspectResult, error) {
+	return record(r, "ContainerInspect", []any{containerID, options}, func() (client.ContainerInspectResult, error) {
+		return r.client.ContainerInspect(ctx, containerID, options)
+	})
+}
+
+// ContainerKill records killing a container.
+func (r *Recorder) ContainerKill(ctx context.Context, containerID string, options client.ContainerKillOptions) (client.ContainerKillResult, error) {
+	return record(r, "ContainerKill", []any{containerID, options}, func() (client.ContainerKillResult, error) {
+		return r.client.ContainerKill(ctx, containerID, options)
+	})
+}
+
+// ContainerList records listing containers.
+func (r *Recorder) ContainerList(ctx context.Context, options client.ContainerListOptions) (client.ContainerListResult, error) {
+	return record(r, "ContainerList", []any{options}, func() (client.ContainerListResult, error) {
+		return r.client.ContainerList(ctx, options)
+	})
+}
+
+// ContainerLogs records retrieving the logs of a container; the logs get
+// recorded in a follow-up record.
+func (r *Recorder) ContainerLogs(ctx context.Context, containerID string, options client.ContainerLogsOptions) (client.ContainerLogsResult, error) {
+	rec := r.begin("ContainerLogs", containerID, options)
+	res, err := r.client.ContainerLogs(ctx, containerID, options)
+	r.end(rec, nil, err)
+	if err != nil {
+		return res, err
+	}
+	return r.stream(rec, res), nil
+}
+
+// ContainerPause records pausing a container.
+func (r *Recorder) ContainerPause(ctx context.Context, containerID string, options client.ContainerPauseOptions) (client.ContainerPauseResult, error) {
+	return record(r, "ContainerPause", []any{containerID, options}, func() (client.ContainerPauseResult, error) {
+		return r.client.ContainerPause(ctx, containerID, options)
+	})
+}
+
+// ContainerRemove records removing a container.
+func (r *Recorder) ContainerRemove(ctx context.Context, containerID string, options client.ContainerRemoveOptions) (client.ContainerRemoveResult, error) {
+	return record(r, "ContainerRemove", []any{containerID, options}, func() (client.ContainerRemoveResult, error) {
+		return r.client.ContainerRemove(ctx, containerID, options)
+	})
+}
+
+// ContainerRename records renaming a container.
+func (r *Recorder) ContainerRename(ctx context.Context, containerID string, options client.ContainerRenameOptions) (client.ContainerRenameResult, error) {
+	return record(r, "ContainerRename", []any{containerID, options}, func() (client.ContainerRenameResult, error) {
+		return r.client.ContainerRename(ctx, containerID, options)
+	})
+}
+
+// ContainerResize records resizing the TTY of a container.
+func (r *Recorder) ContainerResize(ctx context.Context, containerID string, options client.ContainerResizeOptions) (client.ContainerResizeResult, error) {
+	return record(r, "ContainerResize", []any{containerID, options}, func() (client.ContainerResizeResult, error) {
+		return r.client.ContainerResize(ctx, containerID, options)
+	})
+}
+
+// ContainerRestart records restarting a container.
+func (r *Recorder) ContainerRestart(ctx context.Context, containerID string, options client.ContainerRestartOptions) (client.ContainerRestartResult, error) {
+	return record(r, "ContainerRestart", []any{containerID, options}, func() (client.ContainerRestartResult, error) {
+		return r.client.ContainerRestart(ctx, containerID, options)
+	})
+}
+
+// ContainerStart records starting a container.
+func (r *Recorder) ContainerStart(ctx context.Context, containerID string, options client.ContainerStartOptions) (client.ContainerStartResult, error) {
+	return record(r, "ContainerStart", []any{containerID, options}, func() (client.ContainerStartResult, error) {
+		return r.client.ContainerStart(ctx, containerID, options)
+	})
+}
+
+// ContainerStop records stopping a container.
+func (r *Recorder) ContainerStop(ctx context.Context, containerID string, options client.ContainerStopOptions) (client.ContainerStopResult, error) {
+	return record(r, "ContainerStop", []any{containerID, options}, func() (client.ContainerStopResult, error) {
+		return r.client.ContainerStop(ctx, containerID, options)
+	})
+}
+
+// ContainerUnpause records unpausing a container.
+func (r *Recorder) ContainerUnpause(ctx context.Context, containerID string, options client.ContainerUnpauseOptions) (client.ContainerUnpauseResult, error) {
+	return record(r, "ContainerUnpause", []any{containerID, options}, func() (client.ContainerUnpauseResult, error) {
+		return r.client.ContainerUnpause(ctx, containerID, options)
+	})
+}
+
+// ContainerWait records waiting for a container; the outcome of waiting gets
+// recorded in a follow-up record.
+func (r *Recorder) ContainerWait(ctx context.Context, containerID string, options client.ContainerWaitOptions) client.ContainerWaitResult {
+	rec := r.begin("ContainerWait", containerID, options)
+	res := r.client.ContainerWait(ctx, containerID, options)
+	r.end(rec, nil, nil)
+
+	resultCh := make(chan container.WaitResponse, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		select {
+		case resp := <-res.Result:
+			r.follow(rec, resp, nil, nil, nil)
+			resultCh <- resp
+		case err := <-res.Error:
+			r.follow(rec, nil, nil, nil, err)
+			errCh <- err
+		}
+	}()
+	return client.ContainerWaitResult{Result: resultCh, Error: errCh}
+}
+
+// ExecAttach records starting and attaching to a command execution; the data
+// read from the command and written to it gets recorded in a follow-up record
+// after the connection has been closed or reached EOF.
+func (r *Recorder) ExecAttach(ctx context.Context, execID string, options client.ExecAttachOptions) (client.ExecAttachResult, error) {
+	rec := r.begin("ExecAttach", execID, options)
+	res, err := r.client.ExecAttach(ctx, execID, options)
+	r.end(rec, nil, err)
+	if err != nil {
+		return res, err
+	}
+	return client.ExecAttachResult{HijackedResponse: r.hijacked(rec, res.HijackedResponse)}, nil
+}
+
+// ExecCreate records creating a command execution.
+func (r *Recorder) ExecCreate(ctx context.Context, container string, options client.ExecCreateOptions) (client.ExecCreateResult, error) {
+	return record(r, "ExecCreate", []any{container, options}, func() (client.ExecCreateResult, error) {
+		return r.client.ExecCreate(ctx, container, options)
+	})
+}
+
+// ExecStart records starting a command execution.
+func (r *Recorder) ExecStart(ctx context.Context, execID string, options client.ExecStartOptions) (client.ExecStartResult, error) {
+	return record(r, "ExecStart", []any{execID, options}, func() (client.ExecStartResult, error) {
+		return r.client.ExecStart(ctx, execID, options)
+	})
+}
+
+// ExecInspect records inspecting a command execution.
+func (r *Recorder) ExecInspect(ctx context.Context, execID string, options client.ExecInspectOptions) (client.ExecInspectResult, error) {
+	return record(r, "ExecInspect", []any{execID, options}, func() (client.ExecInspectResult, error) {
+		return r.client.ExecInspect(ctx, execID, options)
+	})
+}
+
+// ExecResize records resizing the TTY of a command execution.
+func (r *Recorder) ExecResize(ctx context.Context, execID string, options client.ExecResizeOptions) (client.ExecResizeResult, error) {
+	return record(r, "ExecResize", []any{execID, options}, func() (client.ExecResizeResult, error) {
+		return r.client.ExecResize(ctx, execID, options)
+	})
+}
+
+// ImageBuild records building an image, except for the build context; the
+// build output gets recorded in a follow-up record.
+func (r *Recorder) ImageBuild(ctx context.Context, buildContext io.Reader, options client.ImageBuildOptions) (client.ImageBuildResult, error) {
+	redacted := options
+	redacted.Context = nil
+	if options.AuthConfigs != nil {
+		redacted.AuthConfigs = map[string]registry.AuthConfig{}
+		for host := range maps.Keys(options.AuthConfigs) {
+			redacted.AuthConfigs[host] = registry.AuthConfig{Username: Redacted}
+		}
+	}
+	rec := r.begin("ImageBuild", redacted)
+	res, err := r.client.ImageBuild(ctx, buildContext, options)
+	r.end(rec, nil, err)
+	if err != nil {
+		return res, err
+	}
+	res.Body = r.stream(rec, res.Body)
+	return res, nil
+}
+
+// stream returns a reader that records the data read from the specified
+// stream.
+func (r *Recorder) stream(call *Record, rc io.ReadCloser) io.ReadCloser {
+	return &recordingReadCloser{
+		rc: rc,
+		done: func(stream []byte, err error) {
+			r.follow(call, nil, stream, nil, err)
+		},
+	}
+}
+
+// ImageHistory records retrieving the history of an image.
+func (r *Recorder) ImageHistory(ctx context.Context, imageID string, historyOpts ...client.ImageHistoryOption) (client.ImageHistoryResult, error) {
+	return record(r, "ImageHistory", []any{imageID}, func() (client.ImageHistoryResult, error) {
+		return r.client.ImageHistory(ctx, imageID, historyOpts...)
+	})
+}
+
+// ImageInspect records inspecting an image.
+func (r *Recorder) ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (client.ImageInspectResult, error) {
+	return record(r, "ImageInspect", []any{imageID}, func() (client.ImageInspectResult, error) {
+		return r.client.ImageInspect(ctx, imageID, inspectOpts...)
+	})
+}
+
+// ImageList records listing images.
+func (r *Recorder) ImageList(ctx context.Context, options client.ImageListOptions) (client.ImageListResult, error) {
+	return record(r, "ImageList", []any{options}, func() (client.ImageListResult, error) {
+		return r.client.ImageList(ctx, options)
+	})
+}
+
+// ImagePull records pulling an image; the progress messages get recorded in a
+// follow-up record.
+func (r *Recorder) ImagePull(ctx context.Context, refStr string, options client.ImagePullOptions) (client.ImagePullResponse, error) {
+	redacted := options
+	redacted.RegistryAuth = redact(options.RegistryAuth)
+	redacted.PrivilegeFunc = nil
+	rec := r.begin("ImagePull", refStr, redacted)
+	res, err := r.client.ImagePull(ctx, refStr, options)
+	r.end(rec, nil, err)
+	if err != nil {
+		return res, err
+	}
+	return jsonmsgs.New(r.stream(rec, res)), nil
+}
+
+// ImagePush records pushing an image; the progress messages get recorded in
+// a follow-up record.
+func (r *Recorder) ImagePush(ctx context.Context, image string, options client.ImagePushOptions) (client.ImagePushResponse, error) {
+	redacted := options
+	redacted.RegistryAuth = redact(options.RegistryAuth)
+	redacted.PrivilegeFunc = nil
+	rec := r.begin("ImagePush", image, redacted)
+	res, err := r.client.ImagePush(ctx, image, options)
+	r.end(rec, nil, err)
+	if err != nil {
+		return res, err
+	}
+	return jsonmsgs.New(r.stream(rec, res)), nil
+}
+
+func redact(auth string) string {
+	if auth == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// ImageRemove records removing an image.
+func (r *Recorder) ImageRemove(ctx context.Context, imageID string, options client.ImageRemoveOptions) (client.ImageRemoveResult, error) {
+	return record(r, "ImageRemove", []any{imageID, options}, func() (client.ImageRemoveResult, error) {
+		return r.client.ImageRemove(ctx, imageID, options)
+	})
+}
+
+// ImageTag records tagging an image.
+func (r *Recorder) ImageTag(ctx context.Context, options client.ImageTagOptions) (client.ImageTagResult, error) {
+	return record(r, "ImageTag", []any{options}, func() (client.ImageTagResult, error) {
+		return r.client.ImageTag(ctx, options)
+	})
+}
+
+// NetworkCreate records creating a network.
+func (r *Recorder) NetworkCreate(ctx context.Context, name string, options client.NetworkCreateOptions) (client.NetworkCreateResult, error) {
+	return record(r, "NetworkCreate", []any{name, options}, func() (client.NetworkCreateResult, error) {
+		return r.client.NetworkCreate(ctx, name, options)
+	})
+}
+
+// NetworkInspect records inspecting a network.
+func (r *Recorder) NetworkInspect(ctx context.Context, networkID string, options client.NetworkInspectOptions) (client.NetworkInspectResult, error) {
+	return record(r, "NetworkInspect", []any{networkID, options}, func() (client.NetworkInspectResult, error) {
+		return r.client.NetworkInspect(ctx, networkID, options)
+	})
+}
+
+// NetworkList records listing networks.
+func (r *Recorder) NetworkList(ctx context.Context, options client.NetworkListOptions) (client.NetworkListResult, error) {
+	return record(r, "NetworkList", []any{options}, func() (client.NetworkListResult, error) {
+		return r.client.NetworkList(ctx, options)
+	})
+}
+
+// NetworkRemove records removing a network.
+func (r *Recorder) NetworkRemove(ctx context.Context, networkID string, options client.NetworkRemoveOptions) (client.NetworkRemoveResult, error) {
+	return record(r, "NetworkRemove", []any{networkID, options}, func() (client.NetworkRemoveResult, error) {
+		return r.client.NetworkRemove(ctx, networkID, options)
+	})
+}
+
+// ServerVersion records querying the server version information.
+func (r *Recorder) ServerVersion(ctx context.Context, options client.ServerVersionOptions) (client.ServerVersionResult, error) {
+	return record(r, "ServerVersion", []any{options}, func() (client.ServerVersionResult, error) {
+		return r.client.ServerVersion(ctx, options)
+	})
+}
+
+// DialHijack records dialing a hijacked connection, as used by BuildKit
+// sessions, but not the data exchanged over the connection. If the decorated
+// client doesn't support hijacking connections, DialHijack returns a “not
+// implemented” error.
+func (r *Recorder) DialHijack(ctx context.Context, url, proto string, meta map[string][]string) (net.Conn, error) {
+	rec := r.begin("DialHijack", url, proto)
+	dialer, ok := r.client.(client.HijackDialer)
+	if !ok {
+		err := errdefs.ErrNotImplemented.WithMessage("client does not support hijacking connections")
+		r.end(rec, nil, err)
+		return nil, err
+	}
+	conn, err := dialer.DialHijack(ctx, url, proto, meta)
+	r.end(rec, nil, err)
+	return conn, err
+}
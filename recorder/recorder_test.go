@@ -0,0 +1,213 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/client"
+	"github.com/thediveo/safe"
+
+	"github.com/thediveo/morbyd/v2"
+	"github.com/thediveo/morbyd/v2/exec"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/pull"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+// records returns the records from the specified log.
+func records(log []byte) []Record {
+	GinkgoHelper()
+	recs := []Record{}
+	for line := range strings.SplitSeq(strings.TrimSpace(string(log)), "\n") {
+		var rec Record
+		Expect(json.Unmarshal([]byte(line), &rec)).To(Succeed())
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+// runExecWait runs a test sequence of running a container, executing a
+// command inside it, and waiting for the container to terminate, returning
+// the output of the container and command, as well as their exit codes.
+func runExecWait(ctx context.Context, sess *morbyd.Session) (cntrout string, cntrexit int, execout string, execexit int) {
+	GinkgoHelper()
+	var cout, eout safe.Buffer
+	cntr := Successful(sess.Run(ctx, "busybox",
+		run.WithCommand("/bin/sh", "-c", "echo Hellorld!"),
+		run.WithCombinedOutput(&cout)))
+	es := Successful(cntr.Exec(ctx, exec.Command("ls", "/"),
+		exec.WithCombinedOutput(&eout)))
+	execexit = Successful(es.Wait(ctx))
+	Expect(cntr.Wait(ctx)).To(Succeed())
+	Expect(cntr.Refresh(ctx)).To(Succeed())
+	Eventually(cout.String).ShouldNot(BeEmpty())
+	return cout.String(), cntr.Details.Container.State.ExitCode, eout.String(), execexit
+}
+
+var _ = Describe("recording and replaying Docker API calls", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("records calls, results, and errors", func(ctx context.Context) {
+		engine := fakeengine.New()
+		var log bytes.Buffer
+		sess := Successful(morbyd.NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			WithRecording(&log)))
+		Expect(sess.Client()).To(BeAssignableToTypeOf(&Recorder{}))
+
+		Expect(sess.Container(ctx, "fool")).Error().To(HaveOccurred())
+		_ = Successful(sess.Client().ServerVersion(ctx, client.ServerVersionOptions{}))
+		sess.Close(ctx)
+		Expect(sess.Client().(*Recorder).Err()).NotTo(HaveOccurred())
+
+		recs := records(log.Bytes())
+		Expect(recs).To(HaveLen(3))
+		Expect(recs[0]).To(And(
+			HaveField("Seq", uint64(1)),
+			HaveField("Method", "ContainerInspect"),
+			HaveField("Args", HaveLen(2)),
+			HaveField("Result", BeEmpty()),
+			HaveField("Error", And(
+				HaveField("Kind", "not-found"),
+				HaveField("Message", ContainSubstring("fool")))),
+		))
+		Expect(string(recs[0].Args[0])).To(Equal(`"fool"`))
+		Expect(recs[1]).To(And(
+			HaveField("Method", "ServerVersion"),
+			HaveField("Error", BeNil()),
+			HaveField("Start", Not(BeZero())),
+		))
+		Expect(string(recs[1].Result)).To(ContainSubstring(fakeengine.DefaultPlatformName))
+		Expect(recs[2]).To(HaveField("Method", "Close"))
+	})
+
+	It("redacts registry credentials", func(ctx context.Context) {
+		engine := fakeengine.New()
+		engine.AddRemoteImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		var log bytes.Buffer
+		sess := Successful(morbyd.NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			WithRecording(&log)))
+		defer sess.Close(ctx)
+
+		Expect(sess.PullImage(ctx, "busybox", pull.WithRegistryAuth("s3cr3t"))).Error().NotTo(HaveOccurred())
+		Expect(log.String()).NotTo(ContainSubstring("s3cr3t"))
+		Expect(log.String()).To(ContainSubstring(Redacted))
+
+		recs := records(log.Bytes())
+		Expect(recs).To(ContainElement(And(
+			HaveField("Follows", recs[0].Seq),
+			HaveField("Method", "ImagePull"),
+			HaveField("Stream", Not(BeEmpty())))))
+	})
+
+	It("replays a recorded Run, Exec, and Wait sequence", func(ctx context.Context) {
+		By("recording a test sequence against a fake engine")
+		engine := fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		engine.Handle("ls", func(ctx context.Context, proc *fakeengine.Process) int {
+			_, _ = proc.Stdout.Write([]byte("bin\netc\n"))
+			_, _ = proc.Stderr.Write([]byte("ls: oops\n"))
+			<-time.After(100 * time.Millisecond)
+			return 1
+		})
+		// Keep the container's program running until the command execution
+		// has finished.
+		engine.Handle("sh", func(ctx context.Context, proc *fakeengine.Process) int {
+			_, _ = proc.Stdout.Write([]byte("Hellorld!\n"))
+			select {
+			case <-ctx.Done():
+			case <-time.After(500 * time.Millisecond):
+			}
+			return 42
+		})
+		var log safe.Buffer
+		sess := Successful(morbyd.NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd=recorder"),
+			WithRecording(&log)))
+		cntrout, cntrexit, execout, execexit := runExecWait(ctx, sess)
+		// Reading the container's output stream up to EOF races with the
+		// container's termination, so wait for the stream record to appear
+		// before closing the session.
+		Eventually(func() []Record { return records([]byte(log.String())) }).Should(ContainElement(And(
+			HaveField("Method", "ContainerAttach"),
+			HaveField("Follows", Not(BeZero())))))
+		sess.Close(ctx)
+		Expect(cntrout).To(Equal("Hellorld!\n"))
+		Expect(cntrexit).To(Equal(42))
+		Expect(execout).To(Equal("bin\netc\nls: oops\n"))
+		Expect(execexit).To(Equal(1))
+
+		By("replaying the recorded test sequence")
+		rep := Successful(NewReplayer(strings.NewReader(log.String())))
+		sess = Successful(morbyd.NewSession(ctx,
+			session.WithAutoCleaning("test.morbyd=recorder"),
+			func(o *session.Options) error {
+				o.Wrapper = rep.Wrapper
+				return nil
+			}))
+		rcntrout, rcntrexit, rexecout, rexecexit := runExecWait(ctx, sess)
+		Expect(rcntrout).To(Equal(cntrout))
+		Expect(rcntrexit).To(Equal(cntrexit))
+		Expect(rexecout).To(Equal(execout))
+		Expect(rexecexit).To(Equal(execexit))
+		sess.Close(ctx)
+		Expect(rep.Remaining()).To(BeZero())
+
+		By("running out of recorded calls")
+		Expect(sess.Container(ctx, "foo")).Error().To(MatchError(ErrNotRecorded))
+	})
+
+	It("replays errors of the same class", func(ctx context.Context) {
+		log := `{"seq":1,"method":"ContainerInspect","args":["foo",{}],"start":"2026-01-01T00:00:00Z","duration":1000,"error":{"message":"No such container: foo","kind":"not-found"}}
+{"seq":2,"method":"ContainerWait","args":["bar",{}],"start":"2026-01-01T00:00:00Z","duration":1000}
+{"seq":3,"follows":2,"method":"ContainerWait","start":"2026-01-01T00:00:00Z","duration":1000,"error":{"message":"context canceled","kind":"canceled"}}
+`
+		sess := Successful(morbyd.NewSession(ctx, WithReplay(strings.NewReader(log))))
+		Expect(sess.Container(ctx, "foo")).Error().To(And(
+			MatchError(errdefs.IsNotFound, "IsNotFound"),
+			MatchError(ContainSubstring("No such container: foo"))))
+		Expect((&morbyd.Container{ID: "bar", Session: sess}).Wait(ctx)).To(
+			MatchError(context.Canceled))
+		Expect(sess.Client().ContainerWait(ctx, "bar", client.ContainerWaitOptions{}).Error).To(
+			Receive(MatchError(ErrNotRecorded)))
+	})
+
+	It("rejects invalid logs", func(ctx context.Context) {
+		Expect(morbyd.NewSession(ctx, WithReplay(strings.NewReader("{")))).Error().To(
+			MatchError(ContainSubstring("cannot replay Docker API calls")))
+	})
+
+})
@@ -0,0 +1,391 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/internal/jsonmsgs"
+	"github.com/thediveo/morbyd/v2/moby"
+	"github.com/thediveo/morbyd/v2/session"
+)
+
+// ErrNotRecorded is returned (wrapped) by a Replayer when there is no further
+// recorded call to replay for a particular API method.
+var ErrNotRecorded = errors.New("no recorded call left to replay")
+
+// Replayer is a [moby.Client] that replays the calls from a log written by a
+// [Recorder], instead of talking to a Docker daemon.
+type Replayer struct {
+	mu      sync.Mutex
+	calls   map[string][]*Record // recorded calls in order, by method name.
+	follows map[uint64]*Record   // follow-up records, by the sequence number of their calls.
+}
+
+var (
+	_ moby.Client         = (*Replayer)(nil)
+	_ client.HijackDialer = (*Replayer)(nil)
+)
+
+// NewReplayer returns a new Replayer for the log read from the specified
+// reader, or an error if the log cannot be read.
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	rep := &Replayer{
+		calls:   map[string][]*Record{},
+		follows: map[uint64]*Record{},
+	}
+	dec := json.NewDecoder(r)
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return rep, nil
+			}
+			return nil, fmt.Errorf("invalid recording, reason: %w", err)
+		}
+		if rec.Follows != 0 {
+			rep.follows[rec.Follows] = &rec
+			continue
+		}
+		rep.calls[rec.Method] = append(rep.calls[rec.Method], &rec)
+	}
+}
+
+// WithReplay returns a session option that replaces the session's Docker
+// client with a [Replayer] for the log read from the specified reader.
+func WithReplay(r io.Reader) session.Opt {
+	return func(o *session.Options) error {
+		rep, err := NewReplayer(r)
+		if err != nil {
+			return fmt.Errorf("cannot replay Docker API calls, reason: %w", err)
+		}
+		o.Wrapper = rep.Wrapper
+		return nil
+	}
+}
+
+// Wrapper ignores the passed Docker client and instead returns the Replayer.
+// It is intended for use with the session.Options.Wrapper hook.
+func (r *Replayer) Wrapper(moby.Client) moby.Client { return r }
+
+// Remaining returns the number of recorded calls not yet replayed.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, calls := range r.calls {
+		n += len(calls)
+	}
+	return n
+}
+
+// next returns the next recorded call of the named method, together with its
+// follow-up record, if any.
+func (r *Replayer) next(method string) (call *Record, follow *Record, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls[method]
+	if len(calls) == 0 {
+		return nil, nil, fmt.Errorf("cannot replay %s call, reason: %w", method, ErrNotRecorded)
+	}
+	r.calls[method] = calls[1:]
+	return calls[0], r.follows[calls[0].Seq], nil
+}
+
+// replay the next recorded call of the named method, returning its recorded
+// result or error.
+func replay[R any](r *Replayer, method string) (R, error) {
+	var res R
+	call, _, err := r.next(method)
+	if err != nil {
+		return res, err
+	}
+	if call.Error != nil {
+		return res, call.Error.Err()
+	}
+	if len(call.Result) != 0 {
+		if err := json.Unmarshal(call.Result, &res); err != nil {
+			return res, fmt.Errorf("cannot replay %s call, reason: %w", method, err)
+		}
+	}
+	return res, nil
+}
+
+// stream returns the next recorded call of the named method, together with
+// its recorded stream data.
+func (r *Replayer) stream(method string) ([]byte, error) {
+	call, follow, err := r.next(method)
+	if err != nil {
+		return nil, err
+	}
+	if call.Error != nil {
+		return nil, call.Error.Err()
+	}
+	if follow == nil {
+		return nil, nil
+	}
+	return follow.Stream, nil
+}
+
+// hijacked returns a hijacked response serving the specified stream data.
+func hijacked(stream []byte) client.HijackedResponse {
+	return client.NewHijackedResponse(&replayConn{r: bytes.NewReader(stream)}, "")
+}
+
+// Close replays closing the client. If there is no recorded call, Close
+// simply succeeds.
+func (r *Replayer) Close() error {
+	_, err := replay[struct{}](r, "Close")
+	if errors.Is(err, ErrNotRecorded) {
+		return nil
+	}
+	return err
+}
+
+// ContainerAttach replays attaching to a container, serving the recorded
+// stream data.
+func (r *Replayer) ContainerAttach(ctx context.Context, containerID string, options client.ContainerAttachOptions) (client.ContainerAttachResult, error) {
+	stream, err := r.stream("ContainerAttach")
+	if err != nil {
+		return client.ContainerAttachResult{}, err
+	}
+	return client.ContainerAttachResult{HijackedResponse: hijacked(stream)}, nil
+}
+
+// ContainerCreate replays creating a container.
+func (r *Replayer) ContainerCreate(ctx context.Context, options client.ContainerCreateOptions) (client.ContainerCreateResult, error) {
+	return replay[client.ContainerCreateResult](r, "ContainerCreate")
+}
+
+// ContainerInspect replays inspecting a container.
+func (r *Replayer) ContainerInspect(ctx context.Context, containerID string, options client.ContainerInspectOptions) (client.ContainerInspectResult, error) {
+	return replay[client.ContainerInspectResult](r, "ContainerInspect")
+}
+
+// ContainerKill replays killing a container.
+func (r *Replayer) ContainerKill(ctx context.Context, containerID string, options client.ContainerKillOptions) (client.ContainerKillResult, error) {
+	return replay[client.ContainerKillResult](r, "ContainerKill")
+}
+
+// ContainerList replays listing containers.
+func (r *Replayer) ContainerList(ctx context.Context, options client.ContainerListOptions) (client.ContainerListResult, error) {
+	return replay[client.ContainerListResult](r, "ContainerList")
+}
+
+// ContainerLogs replays retrieving the logs of a container, serving the
+// recorded logs.
+func (r *Replayer) ContainerLogs(ctx context.Context, containerID string, options client.ContainerLogsOptions) (client.ContainerLogsResult, error) {
+	stream, err := r.stream("ContainerLogs")
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(stream)), nil
+}
+
+// ContainerPause replays pausing a container.
+func (r *Replayer) ContainerPause(ctx context.Context, containerID string, options client.ContainerPauseOptions) (client.ContainerPauseResult, error) {
+	return replay[client.ContainerPauseResult](r, "ContainerPause")
+}
+
+// ContainerRemove replays removing a container.
+func (r *Replayer) ContainerRemove(ctx context.Context, containerID string, options client.ContainerRemoveOptions) (client.ContainerRemoveResult, error) {
+	return replay[client.ContainerRemoveResult](r, "ContainerRemove")
+}
+
+// ContainerRename replays renaming a container.
+func (r *Replayer) ContainerRename(ctx context.Context, containerID string, options client.ContainerRenameOptions) (client.ContainerRenameResult, error) {
+	return replay[client.ContainerRenameResult](r, "ContainerRename")
+}
+
+// ContainerResize replays resizing the TTY of a container.
+func (r *Replayer) ContainerResize(ctx context.Context, containerID string, options client.ContainerResizeOptions) (client.ContainerResizeResult, error) {
+	return replay[client.ContainerResizeResult](r, "ContainerResize")
+}
+
+// ContainerRestart replays restarting a container.
+func (r *Replayer) ContainerRestart(ctx context.Context, containerID string, options client.ContainerRestartOptions) (client.ContainerRestartResult, error) {
+	return replay[client.ContainerRestartResult](r, "ContainerRestart")
+}
+
+// ContainerStart replays starting a container.
+func (r *Replayer) ContainerStart(ctx context.Context, containerID string, options client.ContainerStartOptions) (client.ContainerStartResult, error) {
+	return replay[client.ContainerStartResult](r, "ContainerStart")
+}
+
+// ContainerStop replays stopping a container.
+func (r *Replayer) ContainerStop(ctx context.Context, containerID string, options client.ContainerStopOptions) (client.ContainerStopResult, error) {
+	return replay[client.ContainerStopResult](r, "ContainerStop")
+}
+
+// ContainerUnpause replays unpausing a container.
+func (r *Replayer) ContainerUnpause(ctx context.Context, containerID string, options client.ContainerUnpauseOptions) (client.ContainerUnpauseResult, error) {
+	return replay[client.ContainerUnpauseResult](r, "ContainerUnpause")
+}
+
+// ContainerWait replays waiting for a container, immediately delivering the
+// recorded outcome. If the outcome has not been recorded, an error wrapping
+// [ErrNotRecorded] is delivered instead.
+func (r *Replayer) ContainerWait(ctx context.Context, containerID string, options client.ContainerWaitOptions) client.ContainerWaitResult {
+	resultCh := make(chan container.WaitResponse, 1)
+	errCh := make(chan error, 1)
+	result := client.ContainerWaitResult{Result: resultCh, Error: errCh}
+
+	_, follow, err := r.next("ContainerWait")
+	switch {
+	case err != nil:
+		errCh <- err
+	case follow == nil:
+		errCh <- fmt.Errorf("cannot replay ContainerWait outcome, reason: %w", ErrNotRecorded)
+	case follow.Error != nil:
+		errCh <- follow.Error.Err()
+	default:
+		var resp container.WaitResponse
+		if err := json.Unmarshal(follow.Result, &resp); err != nil {
+			errCh <- fmt.Errorf("cannot replay ContainerWait outcome, reason: %w", err)
+			break
+		}
+		resultCh <- resp
+	}
+	return result
+}
+
+// ExecAttach replays starting and attaching to a command execution, serving
+// the recorded stream data.
+func (r *Replayer) ExecAttach(ctx context.Context, execID string, options client.ExecAttachOptions) (client.ExecAttachResult, error) {
+	stream, err := r.stream("ExecAttach")
+	if err != nil {
+		return client.ExecAttachResult{}, err
+	}
+	return client.ExecAttachResult{HijackedResponse: hijacked(stream)}, nil
+}
+
+// ExecCreate replays creating a command execution.
+func (r *Replayer) ExecCreate(ctx context.Context, container string, options client.ExecCreateOptions) (client.ExecCreateResult, error) {
+	return replay[client.ExecCreateResult](r, "ExecCreate")
+}
+
+// ExecStart replays starting a command execution.
+func (r *Replayer) ExecStart(ctx context.Context, execID string, options client.ExecStartOptions) (client.ExecStartResult, error) {
+	return replay[client.ExecStartResult](r, "ExecStart")
+}
+
+// ExecInspect replays inspecting a command execution.
+func (r *Replayer) ExecInspect(ctx context.Context, execID string, options client.ExecInspectOptions) (client.ExecInspectResult, error) {
+	return replay[client.ExecInspectResult](r, "ExecInspect")
+}
+
+// ExecResize replays resizing the TTY of a command execution.
+func (r *Replayer) ExecResize(ctx context.Context, execID string, options client.ExecResizeOptions) (client.ExecResizeResult, error) {
+	return replay[client.ExecResizeResult](r, "ExecResize")
+}
+
+// ImageBuild replays building an image, serving the recorded build output.
+func (r *Replayer) ImageBuild(ctx context.Context, buildContext io.Reader, options client.ImageBuildOptions) (client.ImageBuildResult, error) {
+	stream, err := r.stream("ImageBuild")
+	if err != nil {
+		return client.ImageBuildResult{}, err
+	}
+	if buildContext != nil {
+		// As with a real Docker API client, the whole build context gets
+		// consumed.
+		_, _ = io.Copy(io.Discard, buildContext)
+	}
+	return client.ImageBuildResult{Body: io.NopCloser(bytes.NewReader(stream))}, nil
+}
+
+// ImageHistory replays retrieving the history of an image.
+func (r *Replayer) ImageHistory(ctx context.Context, imageID string, historyOpts ...client.ImageHistoryOption) (client.ImageHistoryResult, error) {
+	return replay[client.ImageHistoryResult](r, "ImageHistory")
+}
+
+// ImageInspect replays inspecting an image.
+func (r *Replayer) ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (client.ImageInspectResult, error) {
+	return replay[client.ImageInspectResult](r, "ImageInspect")
+}
+
+// ImageList replays listing images.
+func (r *Replayer) ImageList(ctx context.Context, options client.ImageListOptions) (client.ImageListResult, error) {
+	return replay[client.ImageListResult](r, "ImageList")
+}
+
+// ImagePull replays pulling an image, serving the recorded progress messages.
+func (r *Replayer) ImagePull(ctx context.Context, refStr string, options client.ImagePullOptions) (client.ImagePullResponse, error) {
+	stream, err := r.stream("ImagePull")
+	if err != nil {
+		return nil, err
+	}
+	return jsonmsgs.New(io.NopCloser(bytes.NewReader(stream))), nil
+}
+
+// ImagePush replays pushing an image, serving the recorded progress messages.
+func (r *Replayer) ImagePush(ctx context.Context, image string, options client.ImagePushOptions) (client.ImagePushResponse, error) {
+	stream, err := r.stream("ImagePush")
+	if err != nil {
+		return nil, err
+	}
+	return jsonmsgs.New(io.NopCloser(bytes.NewReader(stream))), nil
+}
+
+// ImageRemove replays removing an image.
+func (r *Replayer) ImageRemove(ctx context.Context, imageID string, options client.ImageRemoveOptions) (client.ImageRemoveResult, error) {
+	return replay[client.ImageRemoveResult](r, "ImageRemove")
+}
+
+// ImageTag replays tagging an image.
+func (r *Replayer) ImageTag(ctx context.Context, options client.ImageTagOptions) (client.ImageTagResult, error) {
+	return replay[client.ImageTagResult](r, "ImageTag")
+}
+
+// NetworkCreate replays creating a network.
+func (r *Replayer) NetworkCreate(ctx context.Context, name string, options client.NetworkCreateOptions) (client.NetworkCreateResult, error) {
+	return replay[client.NetworkCreateResult](r, "NetworkCreate")
+}
+
+// NetworkInspect replays inspecting a network.
+func (r *Replayer) NetworkInspect(ctx context.Context, networkID string, options client.NetworkInspectOptions) (client.NetworkInspectResult, error) {
+	return replay[client.NetworkInspectResult](r, "NetworkInspect")
+}
+
+// NetworkList replays listing networks.
+func (r *Replayer) NetworkList(ctx context.Context, options client.NetworkListOptions) (client.NetworkListResult, error) {
+	return replay[client.NetworkListResult](r, "NetworkList")
+}
+
+// NetworkRemove replays removing a network.
+func (r *Replayer) NetworkRemove(ctx context.Context, networkID string, options client.NetworkRemoveOptions) (client.NetworkRemoveResult, error) {
+	return replay[client.NetworkRemoveResult](r, "NetworkRemove")
+}
+
+// ServerVersion replays querying the server version information.
+func (r *Replayer) ServerVersion(ctx context.Context, options client.ServerVersionOptions) (client.ServerVersionResult, error) {
+	return replay[client.ServerVersionResult](r, "ServerVersion")
+}
+
+// DialHijack always fails with a “not implemented” error, as the data
+// exchanged over hijacked BuildKit session connections doesn't get recorded.
+func (r *Replayer) DialHijack(ctx context.Context, url, proto string, meta map[string][]string) (net.Conn, error) {
+	return nil, errdefs.ErrNotImplemented.WithMessage("replaying hijacked connections is not supported")
+}
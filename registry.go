@@ -0,0 +1,365 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	mobyregistry "github.com/moby/moby/api/types/registry"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/thediveo/morbyd/v2/internal/dockerconfig"
+	"github.com/thediveo/morbyd/v2/registry"
+	"github.com/thediveo/morbyd/v2/run"
+)
+
+// registryContainerPort is the port the registry listens on inside its
+// container.
+const registryContainerPort = "5000"
+
+// Maximum time to wait for a freshly started registry to serve its API, as well
+// as the interval for probing it.
+var (
+	registryReadyTimeout  = 10 * time.Second
+	registryProbeInterval = 100 * time.Millisecond
+)
+
+// Registry represents an ephemeral local container registry started using
+// [Session.RunRegistry].
+type Registry struct {
+	Container *Container
+	Host      string // host address with port, such as "127.0.0.1:32768".
+	Username  string // htpasswd user, if any.
+	Password  string // password of the htpasswd user, if any.
+	CACert    []byte // PEM-encoded self-signed certificate when serving HTTPS, otherwise nil.
+
+	auth     string
+	scheme   string
+	client   *http.Client
+	tmpdir   string
+	teardown sync.Once
+	err      error
+}
+
+// RunRegistry starts an ephemeral container registry, by default
+// [registry.DefaultImage], published on a random port on the host's IPv4
+// loopback address. It waits for the registry to serve its API before
+// returning. The registry is automatically removed when the session is closed,
+// or earlier using [Registry.Close].
+//
+// Please note that the Docker daemon by default treats registries on loopback
+// as insecure, so pushing to and pulling from a registry with a self-signed
+// certificate (see [registry.WithTLS]) works without any further daemon
+// configuration.
+//
+// When the registry requires authentication, see [registry.WithUser] and
+// [registry.WithAuth], use [Registry.Auth] with [push.WithRegistryAuth] and
+// [pull.WithRegistryAuth].
+func (s *Session) RunRegistry(ctx context.Context, opts ...registry.Opt) (reg *Registry, err error) {
+	ropts := registry.Options{
+		Image: registry.DefaultImage,
+	}
+	for _, opt := range opts {
+		if err := opt(&ropts); err != nil {
+			return nil, err
+		}
+	}
+	runner := caller(1, 0)
+	ctx, op := s.begin(ctx, "registry.run",
+		slog.String(AttrImage, ropts.Image),
+		slog.String(AttrCaller, runner))
+	defer func() { op.end(err) }()
+
+	reg = &Registry{
+		Username: ropts.Username,
+		Password: ropts.Password,
+		scheme:   "http",
+	}
+	tmpdir, err := os.MkdirTemp("", "morbyd-registry-*")
+	if err != nil {
+		return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(tmpdir)
+		}
+	}()
+	reg.tmpdir = tmpdir
+
+	runopts := []run.Opt{
+		run.WithPublishedPort(publishedRegistryPort(ropts.Port)),
+		run.WithEnvVars(
+			"REGISTRY_HTTP_SECRET="+rand.Text(),
+			"REGISTRY_STORAGE_DELETE_ENABLED=true"),
+		run.WithAutoRemove(),
+	}
+	if ropts.Name != "" {
+		runopts = append(runopts, run.WithName(ropts.Name))
+	}
+	if ropts.Out != nil {
+		runopts = append(runopts, run.WithCombinedOutput(ropts.Out))
+	}
+	if ropts.Username != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(ropts.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+		}
+		htpasswdPath := filepath.Join(reg.tmpdir, "htpasswd")
+		if err := os.WriteFile(htpasswdPath,
+			fmt.Appendf(nil, "%s:%s\n", ropts.Username, hash), 0o644); err != nil {
+			return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+		}
+		runopts = append(runopts,
+			run.WithVolume(htpasswdPath+":/auth/htpasswd:ro"),
+			run.WithEnvVars(
+				"REGISTRY_AUTH=htpasswd",
+				"REGISTRY_AUTH_HTPASSWD_REALM=morbyd-registry",
+				"REGISTRY_AUTH_HTPASSWD_PATH=/auth/htpasswd"))
+		reg.auth, err = dockerconfig.Encode(mobyregistry.AuthConfig{
+			Username: ropts.Username,
+			Password: ropts.Password,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if ropts.TLS {
+		certPEM, keyPEM, err := selfSignedCertificate()
+		if err != nil {
+			return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+		}
+		for name, data := range map[string][]byte{"tls.crt": certPEM, "tls.key": keyPEM} {
+			if err := os.WriteFile(filepath.Join(reg.tmpdir, name), data, 0o644); err != nil {
+				return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+			}
+		}
+		runopts = append(runopts,
+			run.WithVolume(filepath.Join(reg.tmpdir, "tls.crt")+":/certs/tls.crt:ro"),
+			run.WithVolume(filepath.Join(reg.tmpdir, "tls.key")+":/certs/tls.key:ro"),
+			run.WithEnvVars(
+				"REGISTRY_HTTP_TLS_CERTIFICATE=/certs/tls.crt",
+				"REGISTRY_HTTP_TLS_KEY=/certs/tls.key"))
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(certPEM)
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		reg.CACert = certPEM
+		reg.scheme = "https"
+	}
+	reg.client = &http.Client{Transport: transport}
+
+	reg.Container, err = s.run(ctx, ropts.Image, runner, runopts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+	}
+	reg.Host = reg.Container.PublishedPort(registryContainerPort).First().
+		UnspecifiedAsLoopback().String()
+	if reg.Host == "" {
+		reg.Container.Kill(ctx)
+		return nil, errors.New("cannot run registry, reason: registry port not published")
+	}
+	op.set(slog.String(AttrContainerID, reg.Container.ID))
+
+	if err := registryReady(ctx, reg); err != nil {
+		reg.Container.Kill(ctx)
+		return nil, fmt.Errorf("cannot run registry, reason: %w", err)
+	}
+	s.onClose(func(ctx context.Context) error {
+		return reg.CloseErr(ctx)
+	})
+	return reg, nil
+}
+
+// publishedRegistryPort returns the port publishing mapping of the registry on
+// the host's IPv4 loopback address, using either a fixed or random port.
+func publishedRegistryPort(port uint16) string {
+	if port == 0 {
+		return "127.0.0.1:" + registryContainerPort
+	}
+	return "127.0.0.1:" + strconv.FormatUint(uint64(port), 10) + ":" + registryContainerPort
+}
+
+// registryReady waits for the registry to serve its API, within
+// registryReadyTimeout.
+var registryReady = func(ctx context.Context, reg *Registry) error {
+	ctx, cancel := context.WithTimeout(ctx, registryReadyTimeout)
+	defer cancel()
+	ticker := time.NewTicker(registryProbeInterval)
+	defer ticker.Stop()
+	for {
+		err := reg.ping(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("registry not ready, reason: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// ping the registry's API base endpoint, expecting either an OK or an
+// Unauthorized response.
+func (r *Registry) ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL()+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("HTTP status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// URL returns the base URL of this registry, such as
+// "http://127.0.0.1:32768".
+func (r *Registry) URL() string {
+	return r.scheme + "://" + r.Host
+}
+
+// Ref returns the fully qualified image reference for the specified image name
+// (with optional tag or digest) in this registry. For instance, given
+// "busybox:latest" Ref returns "127.0.0.1:32768/busybox:latest".
+func (r *Registry) Ref(name string) string {
+	return r.Host + "/" + name
+}
+
+// Auth returns the base64-encoded credentials for this registry to be used with
+// [push.WithRegistryAuth] and [pull.WithRegistryAuth]. If the registry doesn't
+// require authentication, Auth returns an empty string.
+func (r *Registry) Auth() string {
+	return r.auth
+}
+
+// HTTPClient returns an HTTP client trusting this registry's self-signed
+// certificate, if any.
+func (r *Registry) HTTPClient() *http.Client {
+	return r.client
+}
+
+// Tags returns the tags of the specified repository, such as "busybox", using
+// the registry's HTTP API.
+func (r *Registry) Tags(ctx context.Context, repository string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		r.URL()+"/v2/"+(&url.URL{Path: repository}).EscapedPath()+"/tags/list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list registry tags, reason: %w", err)
+	}
+	if r.Username != "" {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list registry tags, reason: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot list registry tags of repository %q, reason: HTTP status code %d",
+			repository, resp.StatusCode)
+	}
+	var tags struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("cannot list registry tags, reason: %w", err)
+	}
+	return tags.Tags, nil
+}
+
+// Close removes the registry container with all its contents; it is safe to
+// call Close multiple times. Use [Registry.CloseErr] in order to learn about
+// any errors.
+func (r *Registry) Close(ctx context.Context) {
+	_ = r.CloseErr(ctx)
+}
+
+// CloseErr removes the registry container with all its contents, returning any
+// error; it is safe to call CloseErr multiple times.
+func (r *Registry) CloseErr(ctx context.Context) error {
+	r.teardown.Do(func() {
+		r.client.CloseIdleConnections()
+		err := r.Container.Session.removeContainer(ctx, r.Container.ID)
+		if err != nil {
+			err = fmt.Errorf("cannot remove registry container %s, reason: %w",
+				r.Container.AbbreviatedID(), err)
+		}
+		r.err = errors.Join(err, os.RemoveAll(r.tmpdir))
+	})
+	return r.err
+}
+
+// selfSignedCertificate returns a freshly generated self-signed certificate
+// and its private key, both PEM-encoded, for the loopback addresses as well as
+// "localhost".
+func selfSignedCertificate() (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "morbyd ephemeral registry"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultImage is the container image reference of the registry to run, unless
+// specified otherwise using [WithImage].
+const DefaultImage = "registry:2"
+
+// GeneratedUsername is the name of the registry user when using [WithAuth].
+const GeneratedUsername = "morbyd"
+
+// Opt is a configuration option to run an ephemeral local container registry
+// using [github.com/thediveo/morbyd.Session.RunRegistry].
+type Opt func(*Options) error
+
+// Options represent the configuration options when running an ephemeral local
+// container registry.
+type Options struct {
+	Image    string    // container image of the registry; defaults to [DefaultImage].
+	Name     string    // optional container name.
+	Port     uint16    // host port on loopback; zero picks a random port.
+	Username string    // optional htpasswd user.
+	Password string    // password of the htpasswd user.
+	TLS      bool      // serve HTTPS using a self-signed certificate.
+	Out      io.Writer // optional writer receiving the registry's log output.
+}
+
+// WithImage specifies the registry container image to run instead of
+// [DefaultImage].
+func WithImage(imageref string) Opt {
+	return func(o *Options) error {
+		if imageref == "" {
+			return errors.New("registry image reference must not be empty")
+		}
+		o.Image = imageref
+		return nil
+	}
+}
+
+// WithName sets the name of the registry container.
+func WithName(name string) Opt {
+	return func(o *Options) error {
+		o.Name = name
+		return nil
+	}
+}
+
+// WithPort publishes the registry on the specified fixed host port on loopback,
+// instead of a random available port.
+func WithPort(port uint16) Opt {
+	return func(o *Options) error {
+		o.Port = port
+		return nil
+	}
+}
+
+// WithUser requires clients to authenticate as the specified user with the
+// specified password, using HTTP basic authentication backed by an htpasswd
+// file.
+func WithUser(username, password string) Opt {
+	return func(o *Options) error {
+		if username == "" || strings.Contains(username, ":") {
+			return fmt.Errorf("registry user name must be non-empty and without colons, got %q", username)
+		}
+		if password == "" {
+			return errors.New("registry user password must not be empty")
+		}
+		o.Username = username
+		o.Password = password
+		return nil
+	}
+}
+
+// WithAuth requires clients to authenticate as [GeneratedUsername] with a
+// randomly generated password.
+func WithAuth() Opt {
+	return func(o *Options) error {
+		o.Username = GeneratedUsername
+		o.Password = rand.Text()
+		return nil
+	}
+}
+
+// WithTLS serves the registry API via HTTPS using a freshly generated
+// self-signed certificate for the loopback addresses and “localhost”.
+func WithTLS() Opt {
+	return func(o *Options) error {
+		o.TLS = true
+		return nil
+	}
+}
+
+// WithOutput specifies the writer to send the registry's log output to.
+func WithOutput(w io.Writer) Opt {
+	return func(o *Options) error {
+		o.Out = w
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("registry options", func() {
+
+	It("processes registry options", func() {
+		ropts := Options{}
+		for _, opt := range []Opt{
+			WithImage("registry:3"),
+			WithName("local-registry"),
+			WithPort(5999),
+			WithUser("silly-user", "silly-password"),
+			WithTLS(),
+			WithOutput(io.Discard),
+		} {
+			Expect(opt(&ropts)).To(Succeed())
+		}
+		Expect(ropts).To(And(
+			HaveField("Image", "registry:3"),
+			HaveField("Name", "local-registry"),
+			HaveField("Port", uint16(5999)),
+			HaveField("Username", "silly-user"),
+			HaveField("Password", "silly-password"),
+			HaveField("TLS", BeTrue()),
+			HaveField("Out", BeIdenticalTo(io.Discard)),
+		))
+	})
+
+	It("generates credentials", func() {
+		ropts := Options{}
+		Expect(WithAuth()(&ropts)).To(Succeed())
+		Expect(ropts.Username).To(Equal(GeneratedUsername))
+		Expect(ropts.Password).To(HaveLen(26))
+		pw := ropts.Password
+		Expect(WithAuth()(&ropts)).To(Succeed())
+		Expect(ropts.Password).NotTo(Equal(pw))
+	})
+
+	It("rejects invalid options", func() {
+		ropts := Options{}
+		Expect(WithImage("")(&ropts)).To(MatchError(
+			"registry image reference must not be empty"))
+		Expect(WithUser("", "foo")(&ropts)).To(MatchError(
+			`registry user name must be non-empty and without colons, got ""`))
+		Expect(WithUser("foo:bar", "foo")(&ropts)).To(HaveOccurred())
+		Expect(WithUser("foo", "")(&ropts)).To(MatchError(
+			"registry user password must not be empty"))
+	})
+
+})
@@ -16,20 +16,23 @@ package morbyd
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
+	"encoding/pem"
 	"net/http"
+	"net/http/httptest"
 	"os"
-	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/moby/moby/api/types/registry"
+	mobyregistry "github.com/moby/moby/api/types/registry"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/thediveo/morbyd/v2/fakeengine"
 	"github.com/thediveo/morbyd/v2/pull"
 	"github.com/thediveo/morbyd/v2/push"
-	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/registry"
 	"github.com/thediveo/morbyd/v2/session"
 	"github.com/thediveo/morbyd/v2/timestamper"
 
@@ -40,96 +43,189 @@ import (
 )
 
 const (
-	registryPort  = 5999                      // port where we expose our local registry on loopback.
 	originalImage = "busybox:latest"          // the upstream original image to get from the Docker registry.
 	canaryImage   = "morbyd-busybox:weirdest" // the tag we'll use in the tests
-
-	user     = "silly-user"
-	password = "silly-password"
 )
 
-// full image reference to our local registry-located testing image
-var localCanaryImage = fmt.Sprintf("127.0.0.1:%d/%s", registryPort, canaryImage)
+var _ = Describe("ephemeral registries", func() {
 
-var _ = Describe("given a (local) registry", Ordered, Serial, func() {
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
 
-	var magicauth string
+	When("using a fake engine", func() {
 
-	BeforeAll(func(ctx context.Context) {
-		sess := Successful(NewSession(ctx,
-			session.WithAutoCleaning("test=morbyd.registry")))
-		DeferCleanup(func(ctx context.Context) {
-			By("removing the local container registry")
-			sess.Close(ctx)
-		})
+		var engine *fakeengine.Engine
 
-		By("creating a silly htpasswd")
-		hash := Successful(bcrypt.GenerateFromPassword([]byte(password), 10))
-		tmpDir := Successful(os.MkdirTemp("", "registry-silly-*"))
-		DeferCleanup(os.RemoveAll, tmpDir)
-		htpasswdPath := filepath.Join(tmpDir, "htpasswd")
-		Expect(os.WriteFile(htpasswdPath,
-			fmt.Appendf(nil, "%s:%s\n", user, hash),
-			0644)).To(Succeed())
+		BeforeEach(func() {
+			engine = fakeengine.New()
+			engine.AddImage(fakeengine.Image{
+				Ref:        registry.DefaultImage,
+				Entrypoint: []string{"/entrypoint.sh"},
+				Cmd:        []string{"/etc/docker/registry/config.yml"},
+			})
+		})
 
-		By("starting a local container registry")
-		Expect(sess.Run(ctx, "registry:3",
-			run.WithName("local-registry"),
-			run.WithPublishedPort(fmt.Sprintf("127.0.0.1:%d:5000", registryPort)),
-			run.WithVolume(htpasswdPath+":/auth/htpasswd:ro"),
-			run.WithEnvVars("REGISTRY_LOG_LEVEL=info",
+		It("runs a registry with auth and TLS, and removes it with the session", func(ctx context.Context) {
+			defer func(old func(context.Context, *Registry) error) { registryReady = old }(registryReady)
+			registryReady = func(context.Context, *Registry) error { return nil }
+
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			reg := Successful(sess.RunRegistry(ctx,
+				registry.WithName("local-registry"),
+				registry.WithAuth(),
+				registry.WithTLS()))
+			Expect(reg.Container.Name).To(Equal("local-registry"))
+			Expect(reg.Host).To(MatchRegexp(`^127\.0\.0\.1:\d+$`))
+			Expect(reg.URL()).To(Equal("https://" + reg.Host))
+			Expect(reg.Ref("busybox:latest")).To(Equal(reg.Host + "/busybox:latest"))
+			Expect(reg.Container.Details.Container.Config.Labels).To(
+				HaveKeyWithValue(ContainerRunnerLabelName, ContainSubstring("registry_test.go")))
+			Expect(reg.Container.Details.Container.Config.Env).To(ContainElements(
 				"REGISTRY_AUTH=htpasswd",
-				"REGISTRY_AUTH_HTPASSWD_REALM=local-registry",
-				"REGISTRY_AUTH_HTPASSWD_PATH=/auth/htpasswd",
-				"REGISTRY_HTTP_SECRET=not-secret"),
-			run.WithAutoRemove(),
-			run.WithCombinedOutput(GinkgoWriter),
-		)).Error().NotTo(HaveOccurred())
-
-		By("waiting for the registry to become operational")
-		Eventually(func() error {
-			resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/v2/", registryPort))
-			if err != nil {
-				return err
-			}
-			defer resp.Body.Close() //nolint:errcheck // any error is irrelevant at this point
-			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
-				return fmt.Errorf("HTTP status code %d", resp.StatusCode)
+				"REGISTRY_HTTP_TLS_CERTIFICATE=/certs/tls.crt"))
+
+			By("generating credentials")
+			Expect(reg.Username).To(Equal(registry.GeneratedUsername))
+			var auth mobyregistry.AuthConfig
+			Expect(json.Unmarshal(Successful(base64.URLEncoding.DecodeString(reg.Auth())), &auth)).To(Succeed())
+			Expect(auth.Username).To(Equal(reg.Username))
+			Expect(auth.Password).To(Equal(reg.Password))
+			binds := reg.Container.Details.Container.HostConfig.Binds
+			Expect(binds).To(ContainElement(HaveSuffix(":/auth/htpasswd:ro")))
+			var htpasswdPath string
+			for _, bind := range binds {
+				if strings.HasSuffix(bind, ":/auth/htpasswd:ro") {
+					htpasswdPath, _, _ = strings.Cut(bind, ":")
+				}
 			}
-			return nil
-		}).Within(5 * time.Second).ProbeEvery(250 * time.Millisecond).
-			Should(Succeed())
+			user, hash, _ := strings.Cut(strings.TrimSpace(
+				string(Successful(os.ReadFile(htpasswdPath)))), ":")
+			Expect(user).To(Equal(reg.Username))
+			Expect(bcrypt.CompareHashAndPassword([]byte(hash), []byte(reg.Password))).To(Succeed())
+
+			By("generating a self-signed certificate")
+			block, _ := pem.Decode(reg.CACert)
+			Expect(block).NotTo(BeNil())
+			cert := Successful(x509.ParseCertificate(block.Bytes))
+			Expect(cert.IPAddresses).To(HaveLen(2))
+			Expect(cert.VerifyHostname("127.0.0.1")).To(Succeed())
+			Expect(reg.HTTPClient().Transport.(*http.Transport).TLSClientConfig.RootCAs).NotTo(BeNil())
+
+			By("tearing down the registry together with the session")
+			Expect(sess.CloseErr(ctx)).To(Succeed())
+			Expect(reg.Container.Refresh(ctx)).To(HaventFoundContainer())
+			Expect(htpasswdPath).NotTo(BeAnExistingFile())
+			Expect(reg.CloseErr(ctx)).To(Succeed())
+		})
 
-		magicauth = base64.URLEncoding.EncodeToString(
-			Successful(json.Marshal(registry.AuthConfig{
-				Username: user,
-				Password: password,
-			})))
+		It("runs an unauthenticated registry on a fixed port", func(ctx context.Context) {
+			defer func(old func(context.Context, *Registry) error) { registryReady = old }(registryReady)
+			registryReady = func(context.Context, *Registry) error { return nil }
 
-	})
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
 
-	BeforeEach(func(ctx context.Context) {
-		goodgos := Goroutines()
-		DeferCleanup(func() {
-			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(250 * time.Millisecond).
-				ShouldNot(HaveLeaked(goodgos))
+			reg := Successful(sess.RunRegistry(ctx, registry.WithPort(5999)))
+			Expect(reg.Host).To(Equal("127.0.0.1:5999"))
+			Expect(reg.URL()).To(Equal("http://127.0.0.1:5999"))
+			Expect(reg.Auth()).To(BeEmpty())
+			Expect(reg.CACert).To(BeNil())
+			reg.Close(ctx)
+			Expect(reg.Container.Refresh(ctx)).To(HaventFoundContainer())
+		})
+
+		It("reports a registry that doesn't become ready", func(ctx context.Context) {
+			defer func(old time.Duration) { registryReadyTimeout = old }(registryReadyTimeout)
+			registryReadyTimeout = 250 * time.Millisecond
+
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			Expect(sess.RunRegistry(ctx, registry.WithName("unready-registry"))).Error().To(
+				MatchError(ContainSubstring("registry not ready")))
+			Eventually(func() error {
+				_, err := sess.Container(ctx, "unready-registry")
+				return err
+			}).Within(2 * time.Second).ProbeEvery(50 * time.Millisecond).
+				Should(HaventFoundContainer())
 		})
+
+		It("rejects invalid options", func(ctx context.Context) {
+			sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+			defer sess.Close(ctx)
+
+			Expect(sess.RunRegistry(ctx, registry.WithImage(""))).Error().To(HaveOccurred())
+		})
+
+	})
+
+	It("lists tags using the registry HTTP API", func(ctx context.Context) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "silly-user" || password != "silly-password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch r.URL.Path {
+			case "/v2/":
+			case "/v2/morbyd/busybox/tags/list":
+				_, _ = w.Write([]byte(`{"name":"morbyd/busybox","tags":["latest","weirdest"]}`))
+			case "/v2/garbage/tags/list":
+				_, _ = w.Write([]byte(`{`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer srv.Close()
+
+		reg := &Registry{
+			Host:     strings.TrimPrefix(srv.URL, "http://"),
+			Username: "silly-user",
+			Password: "silly-password",
+			scheme:   "http",
+			client:   srv.Client(),
+		}
+		Expect(registryReady(ctx, reg)).To(Succeed())
+		Expect(reg.Tags(ctx, "morbyd/busybox")).To(ConsistOf("latest", "weirdest"))
+		Expect(reg.Tags(ctx, "nada")).Error().To(MatchError(ContainSubstring(
+			`repository "nada", reason: HTTP status code 404`)))
+		Expect(reg.Tags(ctx, "garbage")).Error().To(HaveOccurred())
+		reg.Username = ""
+		Expect(reg.Tags(ctx, "morbyd/busybox")).Error().To(MatchError(ContainSubstring(
+			"HTTP status code 401")))
+		srv.Close()
+		Expect(reg.Tags(ctx, "morbyd/busybox")).Error().To(HaveOccurred())
 	})
 
-	It("pushes an image, needing auth", func(ctx context.Context) {
-		sess := Successful(NewSession(ctx))
+	It("pushes and pulls an image, needing auth", Serial, func(ctx context.Context) {
+		sess := Successful(NewSession(ctx,
+			session.WithAutoCleaning("test=morbyd.registry")))
 		DeferCleanup(func(ctx context.Context) {
+			By("removing the local container registry")
 			sess.Close(ctx)
 		})
 
+		By("starting a local container registry")
+		reg := Successful(sess.RunRegistry(ctx,
+			registry.WithAuth(),
+			registry.WithTLS(),
+			registry.WithOutput(timestamper.New(GinkgoWriter))))
+		localCanaryImage := reg.Ref(canaryImage)
+
 		By("pulling the canary image into the local Docker, if not already available")
 		// normal PullImage will always first check instead of skipping
 		// immediately, so we need to check explicitly before pulling.
 		if !Successful(sess.HasImage(ctx, originalImage)) {
 			Expect(sess.PullImage(ctx,
 				originalImage,
-				//pull.WithRegistryAuth(magicauth),
-				pull.WithOutput(timestamper.New(GinkgoWriter)))).To(Succeed())
+				pull.WithOutput(timestamper.New(GinkgoWriter)))).Error().NotTo(HaveOccurred())
 		}
 		By("tagging the canary image for local registry")
 		Expect(sess.TagImage(ctx, originalImage, localCanaryImage)).To(Succeed())
@@ -138,23 +234,18 @@ var _ = Describe("given a (local) registry", Ordered, Serial, func() {
 		Expect(sess.PushImage(ctx, localCanaryImage,
 			push.WithOutput(timestamper.New(GinkgoWriter)))).NotTo(Succeed())
 
-		By("pushing the canary image into the local registry, with silly auth")
+		By("pushing the canary image into the local registry, with generated auth")
 		Expect(sess.PushImage(ctx, localCanaryImage,
-			push.WithRegistryAuth(magicauth),
+			push.WithRegistryAuth(reg.Auth()),
 			push.WithOutput(timestamper.New(GinkgoWriter)))).To(Succeed())
-	})
+		Expect(reg.Tags(ctx, "morbyd-busybox")).To(ConsistOf("weirdest"))
 
-	It("pulls an image without auth", func(ctx context.Context) {
-		sess := Successful(NewSession(ctx))
-		DeferCleanup(func(ctx context.Context) {
-			sess.Close(ctx)
-		})
 		By("ensuring the image isn't available locally (anymore)")
 		Expect(sess.RemoveImage(ctx, localCanaryImage)).Error().NotTo(HaveOccurred())
 		By("pulling the image")
 		Expect(sess.PullImage(ctx, localCanaryImage,
-			pull.WithRegistryAuth(magicauth),
-			pull.WithOutput(timestamper.New(GinkgoWriter)))).To(Succeed())
+			pull.WithRegistryAuth(reg.Auth()),
+			pull.WithOutput(timestamper.New(GinkgoWriter)))).Error().NotTo(HaveOccurred())
 		Expect(sess.HasImage(ctx, localCanaryImage)).To(BeTrue())
 	})
 
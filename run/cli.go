@@ -0,0 +1,402 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cliFlag describes a supported “docker run” CLI flag and how to map its
+// argument, if any, onto an [Opt].
+type cliFlag struct {
+	arg bool                      // flag takes an argument.
+	opt func(string) (Opt, error) // maps the (argument of the) flag.
+}
+
+// cliShorthands maps the single-letter “docker run” CLI flags onto their long
+// names.
+var cliShorthands = map[byte]string{
+	'd': "detach",
+	'e': "env",
+	'h': "hostname",
+	'i': "interactive",
+	'l': "label",
+	'P': "publish-all",
+	'p': "publish",
+	't': "tty",
+	'u': "user",
+	'v': "volume",
+	'w': "workdir",
+}
+
+// cliFlags maps the supported long “docker run” CLI flag names onto their
+// corresponding options.
+var cliFlags = map[string]cliFlag{
+	"cap-add":      cliArgFlag(WithCapAdd),
+	"cap-drop":     cliArgFlag(WithCapDrop),
+	"add-host":     cliArgFlag(WithExtraHost),
+	"annotation":   cliArgFlag(func(v string) Opt { return WithAnnotations(v) }),
+	"cgroupns":     cliArgFlag(WithCgroupnsMode),
+	"cpuset-cpus":  cliArgFlag(WithCPUSet),
+	"cpuset-mems":  cliArgFlag(WithMems),
+	"detach":       cliBoolFlag(nil),
+	"device":       cliArgFlag(WithDevice),
+	"dns":          cliArgFlag(WithDNS),
+	"dns-opt":      cliArgFlag(WithDNSOption),
+	"dns-option":   cliArgFlag(WithDNSOption),
+	"dns-search":   cliArgFlag(WithDNSSearch),
+	"domainname":   cliArgFlag(WithDomainname),
+	"entrypoint":   cliArgFlag(cliEntrypoint),
+	"env":          cliArgFlag(cliEnv),
+	"expose":       cliArgFlag(WithExposedPort),
+	"group-add":    cliArgFlag(WithGroupAdd[string]),
+	"hostname":     cliArgFlag(WithHostname),
+	"init":         cliBoolFlag(WithCustomInit()),
+	"interactive":  cliBoolFlag(WithStdinOpen()),
+	"ipc":          cliArgFlag(WithIPCMode),
+	"label":        cliArgFlag(WithLabel),
+	"mac-address":  cliArgFlag(WithMACAddress),
+	"mount":        cliArgFlag(WithMount),
+	"name":         cliArgFlag(WithName),
+	"net":          cliArgFlag(cliNetwork),
+	"network":      cliArgFlag(cliNetwork),
+	"pid":          cliArgFlag(WithPIDMode),
+	"privileged":   cliBoolFlag(WithPrivileged()),
+	"publish":      cliArgFlag(WithPublishedPort),
+	"publish-all":  cliBoolFlag(WithAllPortsPublished()),
+	"pull":         cliArgFlag(func(v string) Opt { return WithPullPolicy(PullPolicy(v)) }),
+	"read-only":    cliBoolFlag(WithReadOnlyRootfs()),
+	"restart":      {arg: true, opt: cliRestart},
+	"rm":           cliBoolFlag(WithAutoRemove()),
+	"security-opt": cliArgFlag(cliSecurityOpt),
+	"stop-signal":  cliArgFlag(WithStopSignal),
+	"stop-timeout": {arg: true, opt: cliStopTimeout},
+	"tmpfs":        cliArgFlag(cliTmpfs),
+	"tty":          cliBoolFlag(WithTTY()),
+	"user":         cliArgFlag(WithUser[string]),
+	"userns":       cliArgFlag(WithUsernsMode),
+	"volume":       cliArgFlag(WithVolume),
+	"workdir":      cliArgFlag(WithWorkingDir),
+}
+
+// cliArgFlag returns a CLI flag taking an argument that maps onto the option
+// returned by fn.
+func cliArgFlag(fn func(string) Opt) cliFlag {
+	return cliFlag{arg: true, opt: func(v string) (Opt, error) { return fn(v), nil }}
+}
+
+// cliBoolFlag returns a boolean CLI flag that maps onto the specified option.
+func cliBoolFlag(opt Opt) cliFlag {
+	return cliFlag{opt: func(string) (Opt, error) { return opt, nil }}
+}
+
+// FromCLI translates the arguments of a “docker run” command line into the
+// corresponding options for [github.com/thediveo/morbyd/v2.Session.Run],
+// returning the options together with the image reference and the optional
+// command to run. The arguments may start with “docker run”, “docker container
+// run”, or just “run”, but they don't need to.
+//
+// FromCLI supports both the “--flag value” and “--flag=value” forms, as well
+// as combined short flags such as “-it”. The first non-flag argument (or the
+// argument following “--”) is the image reference, all remaining arguments
+// form the command. If there is a command, the returned options already
+// include the corresponding [WithCommand].
+//
+// The “-i” (“--interactive”) flag only opens the container's stdin; use
+// [WithInput] to actually feed it. The “-d” (“--detach”) flag is accepted but
+// ignored, as Session.Run never blocks on the container. Environment variables
+// without a value, such as “-e FOO”, take their value from the current process
+// environment and are skipped if not set, just like the Docker CLI does.
+//
+// FromCLI reports flags without a corresponding option as unsupported,
+// naming the flag as given on the command line. Invalid flag arguments are
+// reported immediately instead of when running the container.
+func FromCLI(args []string) (opts []Opt, imageRef string, cmd []string, err error) {
+	args = trimCLIPrefix(args)
+	scratch := Options{}
+	add := func(flag string, f cliFlag, value string) error {
+		opt, err := f.opt(value)
+		if err != nil {
+			return fmt.Errorf("invalid docker run flag %q argument %q, reason: %w",
+				flag, value, err)
+		}
+		if opt == nil {
+			return nil
+		}
+		// Dry run the option in order to report invalid arguments early and
+		// in the context of their CLI flag.
+		if err := opt(&scratch); err != nil {
+			if f.arg {
+				return fmt.Errorf("invalid docker run flag %q argument %q, reason: %w",
+					flag, value, err)
+			}
+			return fmt.Errorf("invalid docker run flag %q, reason: %w", flag, err)
+		}
+		opts = append(opts, opt)
+		return nil
+	}
+
+	idx := 0
+	for ; idx < len(args); idx++ {
+		arg := args[idx]
+		if arg == "--" {
+			idx++
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			break
+		}
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, value, hasValue := strings.Cut(name, "=")
+			f, ok := cliFlags[name]
+			if !ok {
+				return nil, "", nil, fmt.Errorf("unsupported docker run flag %q", "--"+name)
+			}
+			flag := "--" + name
+			switch {
+			case f.arg && !hasValue:
+				if idx+1 >= len(args) {
+					return nil, "", nil, fmt.Errorf("docker run flag %q needs an argument", flag)
+				}
+				idx++
+				value = args[idx]
+			case !f.arg && hasValue:
+				set, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, "", nil, fmt.Errorf("invalid docker run flag %q boolean value %q",
+						flag, value)
+				}
+				if !set {
+					continue
+				}
+			}
+			if err := add(flag, f, value); err != nil {
+				return nil, "", nil, err
+			}
+			continue
+		}
+		// One or more short flags, where only the last one may take an
+		// argument, unless the argument is directly attached, as in “-eFOO=BAR”.
+		shorts := arg[1:]
+		for pos := 0; pos < len(shorts); pos++ {
+			flag := "-" + string(shorts[pos])
+			name, ok := cliShorthands[shorts[pos]]
+			if !ok {
+				return nil, "", nil, fmt.Errorf("unsupported docker run flag %q", flag)
+			}
+			f := cliFlags[name]
+			if !f.arg {
+				if err := add(flag, f, ""); err != nil {
+					return nil, "", nil, err
+				}
+				continue
+			}
+			value := strings.TrimPrefix(shorts[pos+1:], "=")
+			if pos+1 == len(shorts) {
+				if idx+1 >= len(args) {
+					return nil, "", nil, fmt.Errorf("docker run flag %q needs an argument", flag)
+				}
+				idx++
+				value = args[idx]
+			}
+			if err := add(flag, f, value); err != nil {
+				return nil, "", nil, err
+			}
+			break
+		}
+	}
+	if idx >= len(args) {
+		return nil, "", nil, errors.New("docker run command line lacks an image reference")
+	}
+	imageRef = args[idx]
+	if idx+1 < len(args) {
+		cmd = args[idx+1:]
+		opts = append(opts, WithCommand(cmd...))
+	}
+	return opts, imageRef, cmd, nil
+}
+
+// FromShell works like [FromCLI], but takes a single “docker run” command line
+// string, splitting it into arguments following shell quoting rules. Single
+// quotes, double quotes, backslash escapes, and backslash line continuations are
+// supported, but no variable and command substitutions.
+func FromShell(cmdline string) (opts []Opt, imageRef string, cmd []string, err error) {
+	args, err := splitShell(cmdline)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("malformed docker run command line, reason: %w", err)
+	}
+	return FromCLI(args)
+}
+
+// trimCLIPrefix removes a leading “docker run”, “docker container run”, or
+// “run” from the specified arguments.
+func trimCLIPrefix(args []string) []string {
+	if len(args) > 0 && args[0] == "docker" {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "container" {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "run" {
+		args = args[1:]
+	}
+	return args
+}
+
+// splitShell splits a command line into its arguments, honoring single and
+// double quotes as well as backslash escapes.
+func splitShell(cmdline string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	for pos := 0; pos < len(cmdline); pos++ {
+		ch := cmdline[pos]
+		switch ch {
+		case ' ', '\t', '\n', '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case '\\':
+			pos++
+			if pos >= len(cmdline) {
+				return nil, errors.New("dangling backslash")
+			}
+			if cmdline[pos] == '\n' {
+				continue // line continuation
+			}
+			arg.WriteByte(cmdline[pos])
+			inArg = true
+		case '\'':
+			end := strings.IndexByte(cmdline[pos+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			arg.WriteString(cmdline[pos+1 : pos+1+end])
+			pos += end + 1
+			inArg = true
+		case '"':
+			pos++
+			for ; pos < len(cmdline) && cmdline[pos] != '"'; pos++ {
+				if cmdline[pos] == '\\' && pos+1 < len(cmdline) {
+					switch next := cmdline[pos+1]; next {
+					case '\\', '"', '$', '`':
+						arg.WriteByte(next)
+						pos++
+						continue
+					case '\n':
+						pos++
+						continue
+					}
+				}
+				arg.WriteByte(cmdline[pos])
+			}
+			if pos >= len(cmdline) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inArg = true
+		default:
+			arg.WriteByte(ch)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// cliSecurityOpt maps “--security-opt”, where a seccomp profile file gets
+// loaded and inlined, as the Docker CLI does; see [WithSeccompProfile].
+func cliSecurityOpt(secopt string) Opt {
+	if profile, ok := strings.CutPrefix(secopt, "seccomp="); ok &&
+		profile != "unconfined" && profile != "builtin" {
+		return WithSeccompProfile(profile)
+	}
+	return WithSecurityOpt(secopt)
+}
+
+// cliEntrypoint maps “--entrypoint”, where an empty entrypoint resets the
+// image's default entrypoint.
+func cliEntrypoint(entrypoint string) Opt {
+	if entrypoint == "" {
+		return WithEntrypoint()
+	}
+	return WithEntrypoint(entrypoint)
+}
+
+// cliEnv maps “-e” and “--env”, taking the value of a variable without “=”
+// from the current process environment.
+func cliEnv(env string) Opt {
+	if strings.Contains(env, "=") {
+		return WithEnvVars(env)
+	}
+	value, ok := os.LookupEnv(env)
+	if !ok {
+		return nil
+	}
+	return WithEnvVars(env + "=" + value)
+}
+
+// cliNetwork maps “--network” and “--net”, where “host”, “none”, and
+// “container:NAMEID” configure the network namespace, and all others attach the
+// container to a network.
+func cliNetwork(netw string) Opt {
+	if netw == "host" || netw == "none" || strings.HasPrefix(netw, "container:") {
+		return WithNetworkMode(netw)
+	}
+	return WithNetwork(netw)
+}
+
+// cliRestart maps “--restart” in “POLICY[:MAXRETRIES]” format.
+func cliRestart(restart string) (Opt, error) {
+	policy, retries, ok := strings.Cut(restart, ":")
+	maxretry := 0
+	if ok {
+		var err error
+		if maxretry, err = strconv.Atoi(retries); err != nil || maxretry < 0 {
+			return nil, fmt.Errorf("invalid maximum restart count %q", retries)
+		}
+	}
+	switch policy {
+	case "no", "always", "on-failure", "unless-stopped":
+	default:
+		return nil, fmt.Errorf("unsupported restart policy %q", policy)
+	}
+	return WithRestartPolicy(policy, maxretry), nil
+}
+
+// cliStopTimeout maps “--stop-timeout” in seconds.
+func cliStopTimeout(secs string) (Opt, error) {
+	timeout, err := strconv.Atoi(secs)
+	if err != nil {
+		return nil, err
+	}
+	return WithStopTimeout(timeout), nil
+}
+
+// cliTmpfs maps “--tmpfs” in “PATH[:OPTIONS]” format.
+func cliTmpfs(tmpfs string) Opt {
+	path, opts, ok := strings.Cut(tmpfs, ":")
+	if !ok {
+		return WithTmpfs(path)
+	}
+	return WithTmpfsOpts(path, opts)
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("docker run command lines", func() {
+
+	It("maps docker run CLI flags onto options", func() {
+		GinkgoT().Setenv("MORBYD_CLI_TEST", "foobar")
+		cliopts, ref, cmd, err := FromCLI([]string{
+			"docker", "run", "-it", "--rm", "--name=foo",
+			"-e", "FOO=BAR", "-eBAZ=1", "-e", "MORBYD_CLI_TEST", "-e", "MORBYD_CLI_TEST_UNSET",
+			"-p", "127.0.0.1:1234", "--publish=[::1]:666:80/udp",
+			"-v", "/foo:/bar:ro",
+			"--mount", "type=tmpfs,target=/tmp",
+			"--tmpfs", "/run:size=64m", "--tmpfs", "/var/run",
+			"--network", "host",
+			"--cap-add", "SYS_ADMIN", "--cap-drop", "ALL",
+			"--device", "/dev/fuse",
+			"-u", "1000:1000",
+			"--restart", "on-failure:3",
+			"--entrypoint", "/bin/sh",
+			"-w", "/work",
+			"--label", "foo=bar",
+			"--read-only", "--init=true", "--privileged=false",
+			"--stop-timeout", "42",
+			"--pull", "never",
+			"busybox:latest", "-c", "echo hello",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref).To(Equal("busybox:latest"))
+		Expect(cmd).To(ConsistOf("-c", "echo hello"))
+
+		o := opts(cliopts...)
+		Expect(o.Opts.Name).To(Equal("foo"))
+		Expect(o.PullPolicy).To(Equal(PullNever))
+		Expect(o.Opts.Config).To(And(
+			HaveField("Tty", BeTrue()),
+			HaveField("OpenStdin", BeTrue()),
+			HaveField("Env", ConsistOf("FOO=BAR", "BAZ=1", "MORBYD_CLI_TEST=foobar")),
+			HaveField("User", "1000:1000"),
+			HaveField("Entrypoint", ConsistOf("/bin/sh")),
+			HaveField("Cmd", ConsistOf("-c", "echo hello")),
+			HaveField("WorkingDir", "/work"),
+			HaveField("Labels", HaveKeyWithValue("foo", "bar")),
+			HaveField("StopTimeout", HaveValue(Equal(42))),
+		))
+		Expect(o.Opts.HostConfig).To(And(
+			HaveField("AutoRemove", BeTrue()),
+			HaveField("Privileged", BeFalse()),
+			HaveField("ReadonlyRootfs", BeTrue()),
+			HaveField("Init", HaveValue(BeTrue())),
+			HaveField("PortBindings", HaveLen(2)),
+			HaveField("Binds", ConsistOf("/foo:/bar:ro")),
+			HaveField("Mounts", ConsistOf(HaveField("Type", mount.TypeTmpfs))),
+			HaveField("Tmpfs", And(
+				HaveKeyWithValue("/run", "size=64m"),
+				HaveKeyWithValue("/var/run", ""))),
+			HaveField("NetworkMode", container.NetworkMode("host")),
+			HaveField("CapAdd", ConsistOf("CAP_SYS_ADMIN")),
+			HaveField("CapDrop", ConsistOf("ALL")),
+			HaveField("Devices", ConsistOf(HaveField("PathOnHost", "/dev/fuse"))),
+			HaveField("RestartPolicy", container.RestartPolicy{
+				Name:              "on-failure",
+				MaximumRetryCount: 3,
+			}),
+		))
+	})
+
+	It("attaches to networks", func() {
+		cliopts, ref, cmd := Successful3R(FromCLI([]string{
+			"run", "--net", "name=foo,alias=bar", "--", "-busybox"}))
+		Expect(ref).To(Equal("-busybox"))
+		Expect(cmd).To(BeEmpty())
+		Expect(opts(cliopts...).Opts.NetworkingConfig.EndpointsConfig).To(
+			HaveKeyWithValue("foo", HaveField("Aliases", ConsistOf("bar"))))
+	})
+
+	It("clears the entrypoint", func() {
+		cliopts, _, _ := Successful3R(FromCLI([]string{"--entrypoint=", "busybox"}))
+		Expect(opts(cliopts...).Opts.Config.Entrypoint).To(ConsistOf(""))
+	})
+
+	It("maps security flags", func() {
+		profile := filepath.Join(GinkgoT().TempDir(), "seccomp.json")
+		Expect(os.WriteFile(profile, []byte(`{
+  "defaultAction": "SCMP_ACT_ALLOW"
+}`), 0o644)).To(Succeed())
+		cliopts, _, _ := Successful3R(FromCLI([]string{
+			"--cap-drop", "net_raw", "--group-add", "wheel", "--userns=host",
+			"--security-opt", "seccomp=" + profile,
+			"--security-opt", "seccomp=unconfined",
+			"--security-opt", "no-new-privileges",
+			"busybox"}))
+		Expect(opts(cliopts...).Opts.HostConfig).To(And(
+			HaveField("CapDrop", ConsistOf("CAP_NET_RAW")),
+			HaveField("GroupAdd", ConsistOf("wheel")),
+			HaveField("UsernsMode", container.UsernsMode("host")),
+			HaveField("SecurityOpt", HaveExactElements(
+				`seccomp={"defaultAction":"SCMP_ACT_ALLOW"}`,
+				"seccomp=unconfined",
+				"no-new-privileges",
+			)),
+		))
+	})
+
+	DescribeTable("rejecting invalid command lines",
+		func(args []string, expectedErr string) {
+			_, _, _, err := FromCLI(args)
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("unsupported long flag", []string{"--memory", "1g", "busybox"},
+			`unsupported docker run flag "--memory"`),
+		Entry("unsupported long flag with value", []string{"--memory=1g", "busybox"},
+			`unsupported docker run flag "--memory"`),
+		Entry("unsupported short flag", []string{"-itm", "1g", "busybox"},
+			`unsupported docker run flag "-m"`),
+		Entry("missing argument", []string{"--name"},
+			`docker run flag "--name" needs an argument`),
+		Entry("missing short argument", []string{"-e"},
+			`docker run flag "-e" needs an argument`),
+		Entry("missing image", []string{"docker", "run", "--rm"},
+			"lacks an image reference"),
+		Entry("invalid boolean", []string{"--rm=maybe", "busybox"},
+			`invalid docker run flag "--rm" boolean value "maybe"`),
+		Entry("invalid port", []string{"-p", "abcd", "busybox"},
+			`invalid docker run flag "-p" argument "abcd"`),
+		Entry("invalid restart policy", []string{"--restart", "sometimes", "busybox"},
+			`unsupported restart policy "sometimes"`),
+		Entry("invalid restart count", []string{"--restart", "always:x", "busybox"},
+			`invalid maximum restart count "x"`),
+		Entry("invalid stop timeout", []string{"--stop-timeout", "soon", "busybox"},
+			`invalid docker run flag "--stop-timeout" argument "soon"`),
+		Entry("unknown cap drop", []string{"--cap-drop", "NET_FOO", "busybox"},
+			`unknown capability "NET_FOO"`),
+		Entry("invalid user namespace mode", []string{"--userns", "private", "busybox"},
+			`invalid user namespace mode "private"`),
+		Entry("invalid pull policy", []string{"--pull=sometimes", "busybox"},
+			`unsupported pull policy "sometimes"`),
+	)
+
+	It("parses shell command lines", func() {
+		cliopts, ref, cmd := Successful3R(FromShell(
+			`docker run --rm -e "GREETING=hello, world" -l 'foo=bar baz' \
+			  busybox /bin/sh -c "echo \"\$GREETING\""`))
+		Expect(ref).To(Equal("busybox"))
+		Expect(cmd).To(HaveExactElements("/bin/sh", "-c", `echo "$GREETING"`))
+		o := opts(cliopts...)
+		Expect(o.Opts.Config.Env).To(ConsistOf("GREETING=hello, world"))
+		Expect(o.Opts.Config.Labels).To(HaveKeyWithValue("foo", "bar baz"))
+	})
+
+	DescribeTable("splitting shell command lines",
+		func(cmdline string, expected []string) {
+			Expect(splitShell(cmdline)).To(Equal(expected))
+		},
+		Entry("empty", "", nil),
+		Entry("whitespace", " \t ", nil),
+		Entry("plain", "a b  c", []string{"a", "b", "c"}),
+		Entry("empty quotes", `a '' ""`, []string{"a", "", ""}),
+		Entry("concatenated", `a'b'"c"\ d`, []string{"abc d"}),
+		Entry("literal single quotes", `'a\b "c"'`, []string{`a\b "c"`}),
+		Entry("double quote escapes", `"a\b \\ \$"`, []string{`a\b \ $`}),
+	)
+
+	DescribeTable("rejecting malformed shell command lines",
+		func(cmdline string, expectedErr string) {
+			_, _, _, err := FromShell(cmdline)
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("single quote", `docker run 'busybox`, "unterminated single quote"),
+		Entry("double quote", `docker run "busybox`, "unterminated double quote"),
+		Entry("backslash", `docker run busybox\`, "dangling backslash"),
+	)
+
+})
@@ -0,0 +1,278 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/platforms"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/thediveo/morbyd/v2/internal/dockercmd"
+)
+
+// DockerCommand returns a copy-pasteable “docker run” command line that
+// creates and starts a container from the specified image with the same
+// effective configuration as these options, such as for manually reproducing
+// a container of a failed test. Settings without a corresponding Docker CLI
+// flag, such as the console size, as well as the input and output options are
+// not rendered.
+func (o *Options) DockerCommand(image string) string {
+	cmd := dockercmd.New("docker", "run")
+	cmd.Flag("name", o.Opts.Name)
+	if o.Opts.Platform != nil {
+		cmd.Flag("platform", platforms.Format(*o.Opts.Platform))
+	}
+	if o.PullPolicy != "" && o.PullPolicy != PullMissing {
+		cmd.Flag("pull", string(o.PullPolicy))
+	}
+	var args []string
+	if config := o.Opts.Config; config != nil {
+		cmd.Bool("interactive", config.OpenStdin)
+		cmd.Bool("tty", config.Tty)
+		cmd.Flag("hostname", config.Hostname)
+		cmd.Flag("domainname", config.Domainname)
+		cmd.Flag("user", config.User)
+		cmd.Flag("workdir", config.WorkingDir)
+		cmd.Flags("env", config.Env)
+		cmd.Map("label", config.Labels)
+		for _, port := range slices.SortedFunc(maps.Keys(config.ExposedPorts), comparePorts) {
+			if o.Opts.HostConfig != nil && o.Opts.HostConfig.PortBindings[port] != nil {
+				continue // implicitly exposed by publishing it.
+			}
+			cmd.Flag("expose", port.String())
+		}
+		cmd.Flags("volume", slices.Sorted(maps.Keys(config.Volumes)))
+		cmd.Flag("stop-signal", config.StopSignal)
+		if config.StopTimeout != nil {
+			cmd.Flag("stop-timeout", strconv.Itoa(*config.StopTimeout))
+		}
+		switch {
+		case len(config.Entrypoint) == 1 && config.Entrypoint[0] == "":
+			cmd.Add("--entrypoint=")
+		case len(config.Entrypoint) > 0:
+			// The Docker CLI accepts only a single entrypoint element, so any
+			// further elements become leading command arguments.
+			cmd.Flag("entrypoint", config.Entrypoint[0])
+			args = append(args, config.Entrypoint[1:]...)
+		}
+		args = append(args, config.Cmd...)
+	}
+	if host := o.Opts.HostConfig; host != nil {
+		dockerHostCommand(cmd, host)
+	}
+	if netw := o.Opts.NetworkingConfig; netw != nil {
+		for _, name := range slices.Sorted(maps.Keys(netw.EndpointsConfig)) {
+			cmd.Flag("network", endpointFlag(name, netw.EndpointsConfig[name]))
+		}
+	}
+	if o.MACAddress != nil {
+		cmd.Flag("mac-address", o.MACAddress.String())
+	}
+	cmd.Add(image)
+	cmd.Add(args...)
+	return cmd.String()
+}
+
+// dockerHostCommand adds the flags corresponding with the host configuration
+// to the specified command.
+func dockerHostCommand(cmd *dockercmd.Command, host *container.HostConfig) {
+	cmd.Bool("rm", host.AutoRemove)
+	cmd.Bool("privileged", host.Privileged)
+	cmd.Bool("read-only", host.ReadonlyRootfs)
+	cmd.Bool("init", host.Init != nil && *host.Init)
+	cmd.Flags("cap-add", host.CapAdd)
+	cmd.Flags("cap-drop", host.CapDrop)
+	cmd.Flags("security-opt", host.SecurityOpt)
+	cmd.Flags("group-add", host.GroupAdd)
+	cmd.Flag("userns", string(host.UsernsMode))
+	cmd.Flag("cgroupns", string(host.CgroupnsMode))
+	cmd.Flag("ipc", string(host.IpcMode))
+	cmd.Flag("pid", string(host.PidMode))
+	if mode := host.NetworkMode; mode != "" && mode != network.NetworkDefault {
+		cmd.Flag("network", string(mode))
+	}
+	for _, addr := range host.DNS {
+		cmd.Flag("dns", addr.String())
+	}
+	cmd.Flags("dns-search", host.DNSSearch)
+	cmd.Flags("dns-option", host.DNSOptions)
+	cmd.Flags("add-host", host.ExtraHosts)
+	cmd.Map("annotation", host.Annotations)
+	cmd.Flag("cpuset-cpus", host.CpusetCpus)
+	cmd.Flag("cpuset-mems", host.CpusetMems)
+	if policy := host.RestartPolicy; policy.Name != "" && policy.Name != container.RestartPolicyDisabled {
+		restart := string(policy.Name)
+		if policy.MaximumRetryCount > 0 {
+			restart += ":" + strconv.Itoa(policy.MaximumRetryCount)
+		}
+		cmd.Flag("restart", restart)
+	}
+	cmd.Bool("publish-all", host.PublishAllPorts)
+	for _, port := range slices.SortedFunc(maps.Keys(host.PortBindings), comparePorts) {
+		for _, binding := range host.PortBindings[port] {
+			cmd.Flag("publish", publishFlag(port, binding))
+		}
+	}
+	cmd.Flags("volume", host.Binds)
+	for _, mnt := range host.Mounts {
+		cmd.Flag("mount", mountFlag(mnt))
+	}
+	for _, path := range slices.Sorted(maps.Keys(host.Tmpfs)) {
+		tmpfs := path
+		if opts := host.Tmpfs[path]; opts != "" {
+			tmpfs += ":" + opts
+		}
+		cmd.Flag("tmpfs", tmpfs)
+	}
+	for _, dev := range host.Devices {
+		cmd.Flag("device", dev.PathOnHost+":"+dev.PathInContainer+":"+dev.CgroupPermissions)
+	}
+}
+
+// comparePorts orders ports by their number and then by their protocol.
+func comparePorts(a, b network.Port) int {
+	if a.Num() != b.Num() {
+		return int(a.Num()) - int(b.Num())
+	}
+	return strings.Compare(string(a.Proto()), string(b.Proto()))
+}
+
+// publishFlag returns the “--publish” flag value for the specified container
+// port and host binding.
+func publishFlag(port network.Port, binding network.PortBinding) string {
+	hostPort := binding.HostPort
+	if hostPort == "0" {
+		hostPort = "" // random host port
+	}
+	var publish string
+	switch {
+	case binding.HostIP.Is6():
+		publish = "[" + binding.HostIP.String() + "]:" + hostPort + ":"
+	case binding.HostIP.IsValid():
+		publish = binding.HostIP.String() + ":" + hostPort + ":"
+	case hostPort != "":
+		publish = hostPort + ":"
+	}
+	return publish + port.String()
+}
+
+// mountFlag returns the “--mount” flag value for the specified mount.
+func mountFlag(mnt mount.Mount) string {
+	fields := []string{"type=" + string(mnt.Type)}
+	if mnt.Source != "" {
+		fields = append(fields, "source="+mnt.Source)
+	}
+	fields = append(fields, "target="+mnt.Target)
+	if mnt.ReadOnly {
+		fields = append(fields, "readonly")
+	}
+	if mnt.Consistency != "" {
+		fields = append(fields, "consistency="+string(mnt.Consistency))
+	}
+	if opts := mnt.BindOptions; opts != nil {
+		if opts.Propagation != "" {
+			fields = append(fields, "bind-propagation="+string(opts.Propagation))
+		}
+		if opts.NonRecursive {
+			fields = append(fields, "bind-recursive=disabled")
+		}
+		if opts.CreateMountpoint {
+			fields = append(fields, "bind-create-src")
+		}
+	}
+	if opts := mnt.VolumeOptions; opts != nil {
+		if opts.NoCopy {
+			fields = append(fields, "volume-nocopy")
+		}
+		if opts.Subpath != "" {
+			fields = append(fields, "volume-subpath="+opts.Subpath)
+		}
+		for _, key := range slices.Sorted(maps.Keys(opts.Labels)) {
+			fields = append(fields, "volume-label="+key+"="+opts.Labels[key])
+		}
+		if driver := opts.DriverConfig; driver != nil {
+			if driver.Name != "" {
+				fields = append(fields, "volume-driver="+driver.Name)
+			}
+			for _, key := range slices.Sorted(maps.Keys(driver.Options)) {
+				fields = append(fields, "volume-opt="+key+"="+driver.Options[key])
+			}
+		}
+	}
+	if opts := mnt.TmpfsOptions; opts != nil {
+		if opts.SizeBytes != 0 {
+			fields = append(fields, "tmpfs-size="+strconv.FormatInt(opts.SizeBytes, 10))
+		}
+		if opts.Mode != 0 {
+			fields = append(fields, fmt.Sprintf("tmpfs-mode=%o", opts.Mode))
+		}
+	}
+	return csvFields(fields)
+}
+
+// endpointFlag returns the “--network” flag value for the specified network
+// and endpoint settings, using the long form only when necessary.
+func endpointFlag(name string, ep *network.EndpointSettings) string {
+	if ep == nil {
+		return name
+	}
+	fields := []string{"name=" + name}
+	for _, alias := range ep.Aliases {
+		fields = append(fields, "alias="+alias)
+	}
+	if ep.IPAddress.IsValid() {
+		fields = append(fields, "ip="+ep.IPAddress.String())
+	}
+	if ep.GlobalIPv6Address.IsValid() {
+		fields = append(fields, "ip6="+ep.GlobalIPv6Address.String())
+	}
+	if ipam := ep.IPAMConfig; ipam != nil {
+		if ipam.IPv4Address.IsValid() && ipam.IPv4Address != ep.IPAddress {
+			fields = append(fields, "ip="+ipam.IPv4Address.String())
+		}
+		if ipam.IPv6Address.IsValid() && ipam.IPv6Address != ep.GlobalIPv6Address {
+			fields = append(fields, "ip6="+ipam.IPv6Address.String())
+		}
+	}
+	if len(ep.MacAddress) > 0 {
+		fields = append(fields, "mac-address="+ep.MacAddress.String())
+	}
+	for _, link := range ep.Links {
+		fields = append(fields, "link="+link)
+	}
+	for _, key := range slices.Sorted(maps.Keys(ep.DriverOpts)) {
+		fields = append(fields, "driver-opt="+key+"="+ep.DriverOpts[key])
+	}
+	if len(fields) == 1 {
+		return name
+	}
+	return csvFields(fields)
+}
+
+// csvFields joins the fields into comma-separated values, quoting them where
+// necessary.
+func csvFields(fields []string) string {
+	for idx, field := range fields {
+		fields[idx] = dockercmd.CSV(field)
+	}
+	return strings.Join(fields, ",")
+}
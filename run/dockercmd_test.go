@@ -0,0 +1,117 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("rendering docker run command lines", func() {
+
+	It("renders the effective options", func() {
+		o := opts(
+			WithName("foo"),
+			WithAutoRemove(),
+			WithEnvVars("GREETING=hello, world"),
+			WithLabel("foo=bar"),
+			WithPublishedPort("127.0.0.1:1234"),
+			WithPublishedPort("[::1]:666:80/udp"),
+			WithMount("type=bind,source=/foo,target=/bar,readonly"),
+			WithTmpfsOpts("/run", "size=64m"),
+			WithNetwork("name=bar,alias=baz"),
+			WithCommand("/bin/sh", "-c", "echo $GREETING"),
+		)
+		Expect(o.DockerCommand("busybox")).To(Equal(
+			"docker run --name=foo '--env=GREETING=hello, world' --label=foo=bar --rm " +
+				"'--publish=[::1]:666:80/udp' --publish=127.0.0.1::1234/tcp " +
+				"--mount=type=bind,source=/foo,target=/bar,readonly " +
+				"--tmpfs=/run:size=64m --network=name=bar,alias=baz " +
+				"busybox /bin/sh -c 'echo $GREETING'"))
+	})
+
+	It("renders a MAC address regardless of it being applied", func() {
+		o := opts(WithMACAddress("02:42:ac:11:00:02"))
+		Expect(o.DockerCommand("busybox")).To(Equal(
+			"docker run --mac-address=02:42:ac:11:00:02 busybox"))
+		Expect(o.ApplyMACAddress()).To(Succeed())
+		Expect(o.DockerCommand("busybox")).To(Equal(
+			"docker run --network=name=bridge,mac-address=02:42:ac:11:00:02 busybox"))
+	})
+
+	It("renders an empty and a multi-element entrypoint", func() {
+		o := opts(WithEntrypoint())
+		Expect(o.DockerCommand("busybox")).To(Equal("docker run --entrypoint= busybox"))
+
+		o = opts(WithEntrypoint("/bin/sh", "-e"), WithCommand("-c", "true"))
+		Expect(o.DockerCommand("busybox")).To(Equal(
+			"docker run --entrypoint=/bin/sh busybox -e -c true"))
+	})
+
+	It("round-trips through the command line parser", func() {
+		original := []Opt{
+			WithName("foo"),
+			WithTTY(),
+			WithHostname("bar"),
+			WithDomainname("example.org"),
+			WithWorkingDir("/work"),
+			WithStdinOpen(),
+			WithExposedPort("53/udp"),
+			WithDNS("10.0.0.53"),
+			WithDNSSearch("example.org"),
+			WithDNSOption("ndots:2"),
+			WithExtraHost("foo.example:[fe80::1]"),
+			WithExtraHost("host.docker.internal:host-gateway"),
+			WithAnnotations("foo=bar"),
+			WithUser("1000:1000"),
+			WithEnvVars("FOO=BAR", "GREETING=it's me"),
+			WithLabels("foo=bar", "bar=baz"),
+			WithStopSignal("SIGKILL"),
+			WithStopTimeout(42),
+			WithAutoRemove(),
+			WithPrivileged(),
+			WithReadOnlyRootfs(),
+			WithCustomInit(),
+			WithCapAdd("SYS_ADMIN"),
+			WithCapDropAll(),
+			WithSecurityOpt("seccomp=unconfined"),
+			WithIPCMode("private"),
+			WithPIDMode("host"),
+			WithCgroupnsMode("host"),
+			WithCPUSet("0-1"),
+			WithMems("0"),
+			WithRestartPolicy("on-failure", 3),
+			WithAllPortsPublished(),
+			WithPublishedPort("1234"),
+			WithPublishedPort("127.0.0.1:666:80/udp"),
+			WithVolume("/foo:/bar:ro"),
+			WithMount("type=tmpfs,target=/tmp,tmpfs-size=1024,tmpfs-mode=0700"),
+			WithMount(`type=volume,source=vol,target=/vol,volume-nocopy,"volume-opt=o=a,b"`),
+			WithTmpfs("/run"),
+			WithDevice("/dev/fuse"),
+			WithNetwork("name=bar,alias=baz,ip=10.0.0.2"),
+			WithMACAddress("02:42:ac:11:00:02"),
+			WithPullPolicy(PullNever),
+			WithCommand("/bin/sh", "-c", "echo \"$GREETING\""),
+		}
+		expected := opts(original...)
+
+		cliopts, ref, _ := Successful3R(FromShell(expected.DockerCommand("busybox:latest")))
+		Expect(ref).To(Equal("busybox:latest"))
+		Expect(opts(cliopts...)).To(Equal(expected))
+	})
+
+})
@@ -15,13 +15,17 @@
 package run
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/netip"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	dockercliopts "github.com/docker/cli/opts"
 	"github.com/moby/moby/api/types/container"
@@ -29,11 +33,12 @@ import (
 	"github.com/moby/moby/api/types/network"
 	"github.com/moby/moby/client"
 
+	"github.com/thediveo/morbyd/v2/caps"
 	"github.com/thediveo/morbyd/v2/identity"
 	"github.com/thediveo/morbyd/v2/internal/ensure"
 	lbls "github.com/thediveo/morbyd/v2/labels"
+	"github.com/thediveo/morbyd/v2/pull"
 	"github.com/thediveo/morbyd/v2/run/internal/volumespec"
-	"github.com/thediveo/morbyd/v2/strukt"
 )
 
 // Opt is a configuration option to run a container using
@@ -60,8 +65,38 @@ type Options struct {
 	In   io.Reader
 	Out  io.Writer
 	Err  io.Writer
+
+	// If not "", then ReuseKey identifies a container to be reused across
+	// test runs, see [WithReuse].
+	ReuseKey string
+	// If non-zero, ReuseTTL specifies how long a reusable container lives
+	// before it expires and becomes eligible for auto-cleaning, see
+	// [WithReuseTTL].
+	ReuseTTL time.Duration
+
+	// Determines when to pull the container image, see [WithPullPolicy];
+	// defaults to [PullMissing].
+	PullPolicy PullPolicy
+	// Options applied when pulling the container image, see
+	// [WithPullOptions].
+	PullOpts []pull.Opt
+
+	// If non-nil, the MAC address to apply to the container's network
+	// endpoint after all options have been applied, see [WithMACAddress].
+	MACAddress net.HardwareAddr
 }
 
+// PullPolicy determines when to pull a container image before creating a
+// container; see [WithPullPolicy].
+type PullPolicy string
+
+// Supported pull policies, following the Docker CLI's “--pull” flag.
+const (
+	PullMissing PullPolicy = "missing" // pull only if not locally available (default).
+	PullAlways  PullPolicy = "always"  // always pull, such as for moving tags.
+	PullNever   PullPolicy = "never"   // never pull, failing if not locally available.
+)
+
 // WithCombinedOutput sends the container's stdout and stderr to the specified
 // io.Writer. This also automatically attaches the container's stdout and stderr
 // after the container has been created.
@@ -115,6 +150,69 @@ func WithName(name string) Opt {
 	}
 }
 
+// WithReuse marks the container as reusable under the specified key across
+// test sessions and even test runs: when there is already a running container
+// with the same key that was created from the same image and with the same
+// effective options (as identified by a hash of them), then Session.Run
+// returns this container instead of creating a new one. Reusable containers
+// are not removed by auto-cleaning, unless they have expired, see
+// [WithReuseTTL].
+//
+// As reused containers outlive test sessions, Session.Run does not attach any
+// input reader and output writers to an already running container that gets
+// reused; use Container.Attach instead. The labels inherited from the session,
+// such as the auto-cleaning label, don't count towards the effective options,
+// so that tests with different session labels still reuse the same container.
+func WithReuse(key string) Opt {
+	return func(o *Options) error {
+		if key == "" {
+			return errors.New("reuse key must not be empty")
+		}
+		o.ReuseKey = key
+		return nil
+	}
+}
+
+// WithReuseTTL sets the time-to-live of a reusable container, after which
+// auto-cleaning removes the container as usual. The time-to-live is measured
+// from creating the reusable container and not extended when reusing it. See
+// also [WithReuse].
+func WithReuseTTL(ttl time.Duration) Opt {
+	return func(o *Options) error {
+		if ttl <= 0 {
+			return fmt.Errorf("reuse time-to-live must be positive, got %s", ttl)
+		}
+		o.ReuseTTL = ttl
+		return nil
+	}
+}
+
+// WithPullPolicy sets when to pull the container image before creating the
+// container: [PullMissing] pulls only when the image isn't locally available,
+// [PullAlways] always pulls in order to pick up updated moving tags, and
+// [PullNever] never pulls, such as in offline CI.
+func WithPullPolicy(policy PullPolicy) Opt {
+	return func(o *Options) error {
+		switch policy {
+		case PullMissing, PullAlways, PullNever:
+		default:
+			return fmt.Errorf("unsupported pull policy %q, expected %q, %q, or %q",
+				policy, PullMissing, PullAlways, PullNever)
+		}
+		o.PullPolicy = policy
+		return nil
+	}
+}
+
+// WithPullOptions adds options for pulling the container image, such as
+// [pull.WithPlatform] or [pull.WithRegistryAuth].
+func WithPullOptions(opts ...pull.Opt) Opt {
+	return func(o *Options) error {
+		o.PullOpts = append(o.PullOpts, opts...)
+		return nil
+	}
+}
+
 // WithCommand sets the optional command to execute at container start.
 func WithCommand(cmd ...string) Opt {
 	return func(o *Options) error {
@@ -124,6 +222,50 @@ func WithCommand(cmd ...string) Opt {
 	}
 }
 
+// WithEntrypoint sets the entrypoint to execute at container start, overriding
+// the image's default entrypoint. Without any arguments, WithEntrypoint clears
+// the image's default entrypoint, so that the command specified by
+// [WithCommand] gets executed directly, similar to “--entrypoint ""”.
+func WithEntrypoint(entrypoint ...string) Opt {
+	return func(o *Options) error {
+		if len(entrypoint) > 0 && entrypoint[0] == "" {
+			return errors.New("entrypoint executable must not be empty")
+		}
+		ensure.Value(&o.Opts.Config)
+		if len(entrypoint) == 0 {
+			o.Opts.Config.Entrypoint = []string{""}
+			return nil
+		}
+		o.Opts.Config.Entrypoint = entrypoint
+		return nil
+	}
+}
+
+// WithWorkingDir sets the (current) working directory of the initial process
+// in the container, which must be an absolute path. If the directory doesn't
+// exist in the container, it gets created.
+func WithWorkingDir(dir string) Opt {
+	return func(o *Options) error {
+		if !path.IsAbs(dir) {
+			return fmt.Errorf("working directory must be an absolute path, got: %q", dir)
+		}
+		ensure.Value(&o.Opts.Config)
+		o.Opts.Config.WorkingDir = dir
+		return nil
+	}
+}
+
+// WithStdinOpen keeps the container's stdin open even if not attached, similar
+// to the “-i” CLI flag for detached containers. In contrast to [WithInput],
+// WithStdinOpen doesn't feed any input to the container.
+func WithStdinOpen() Opt {
+	return func(o *Options) error {
+		ensure.Value(&o.Opts.Config)
+		o.Opts.Config.OpenStdin = true
+		return nil
+	}
+}
+
 // WithEnvVars adds multiple environment variables to the container to be
 // started.
 func WithEnvVars(vars ...string) Opt {
@@ -166,6 +308,21 @@ func WithLabels(labels ...string) Opt {
 	}
 }
 
+// WithAnnotations adds annotations in “key=value” format that are passed to the
+// OCI runtime, in contrast to labels.
+func WithAnnotations(annotations ...string) Opt {
+	return func(o *Options) error {
+		ensure.Value(&o.Opts.HostConfig)
+		ensure.Map(&o.Opts.HostConfig.Annotations)
+		for _, annotation := range annotations {
+			if err := lbls.Labels(o.Opts.HostConfig.Annotations).Add(annotation); err != nil {
+				return fmt.Errorf("invalid annotation, reason: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
 func ensureLabelsMap(o *Options) {
 	ensure.Value(&o.Opts.Config)
 	ensure.Map(&o.Opts.Config.Labels)
@@ -181,9 +338,21 @@ func WithStopSignal(s string) Opt {
 	}
 }
 
-// WithStopTimeout sets the timeout (in seconds) to stop the container.
-func WithStopTimeout(secs int) Opt {
+// WithStopTimeout sets the timeout to stop the container, either in seconds or
+// as a [time.Duration]. Durations get rounded up to full seconds and must not
+// be negative.
+func WithStopTimeout[T int | time.Duration](timeout T) Opt {
 	return func(o *Options) error {
+		var secs int
+		switch timeout := any(timeout).(type) {
+		case int:
+			secs = timeout
+		case time.Duration:
+			if timeout < 0 {
+				return fmt.Errorf("stop timeout must not be negative, got %s", timeout)
+			}
+			secs = int((timeout + time.Second - 1) / time.Second)
+		}
 		ensure.Value(&o.Opts.Config)
 		o.Opts.Config.StopTimeout = &secs
 		return nil
@@ -227,23 +396,25 @@ func WithPrivileged() Opt {
 	}
 }
 
-// WithCapAdd adds an individual kernel capabilities to the initial process in
-// the container.
-func WithCapAdd(cap string) Opt {
+// WithCapAdd adds an individual kernel capability to the initial process in
+// the container. The capability name is case-insensitive and may or may not
+// have the “CAP_” prefix; it also can be “ALL”.
+func WithCapAdd(capability string) Opt {
 	return func(o *Options) error {
+		name, err := caps.Normalize(capability)
+		if err != nil {
+			return fmt.Errorf("invalid capability to add, reason: %w", err)
+		}
 		ensure.Value(&o.Opts.HostConfig)
-		o.Opts.HostConfig.CapAdd = append(o.Opts.HostConfig.CapAdd, cap)
+		o.Opts.HostConfig.CapAdd = append(o.Opts.HostConfig.CapAdd, name)
 		return nil
 	}
 }
 
 // WithCapDropAll drops all kernel capabilities for the initial process in the
-// container.
-//
-// Please note that we don't provide dropping individual capabilities on
-// purpose: the default set of Docker container capabilities depend on the
-// Docker engine and Linux kernel, so this would result in non-deterministic
-// behavior. Drop them all, or drop none.
+// container. Use [WithCapAdd] afterwards to add back only those capabilities
+// actually needed; see also [WithCapDrop] for dropping individual
+// capabilities.
 func WithCapDropAll() Opt {
 	return func(o *Options) error {
 		ensure.Value(&o.Opts.HostConfig)
@@ -321,9 +492,21 @@ func WithPIDMode(mode string) Opt {
 //     TCP port 666, bound to the IPv4 loopback address 127.0.0.1.
 //   - "[::1]:1234" publishes the container's TCP port 1234 on a random, available
 //     host TCP port, bound the the host's IPv6 loopback address ::1.
-func WithPublishedPort(mapping string) Opt {
+//
+// Alternatively, WithPublishedPort takes a typed [Port].
+func WithPublishedPort[P string | Port](port P) Opt {
 	return func(o *Options) error {
-		bindIP, hostPort, cntrPort, l4proto, err := parsePortMapping(mapping)
+		var p Port
+		switch port := any(port).(type) {
+		case string:
+			var err error
+			if p, err = ParsePort(port); err != nil {
+				return err
+			}
+		case Port:
+			p = port
+		}
+		portProto, err := p.portProto()
 		if err != nil {
 			return err
 		}
@@ -331,22 +514,42 @@ func WithPublishedPort(mapping string) Opt {
 		ensure.Value(&o.Opts.HostConfig)
 		// ouch, we need to set also ExposedPorts, otherwise the PortBindings
 		// get ignored.
-		if o.Opts.Config.ExposedPorts == nil {
-			o.Opts.Config.ExposedPorts = network.PortSet{}
+		ensure.Map(&o.Opts.Config.ExposedPorts)
+		ensure.Map(&o.Opts.HostConfig.PortBindings)
+		o.Opts.Config.ExposedPorts[portProto] = struct{}{}
+		o.Opts.HostConfig.PortBindings[portProto] = append(o.Opts.HostConfig.PortBindings[portProto], network.PortBinding{
+			HostIP:   p.Host.Addr(),
+			HostPort: strconv.FormatUint(uint64(p.Host.Port()), 10),
+		})
+		return nil
+	}
+}
+
+// WithExposedPort exposes a container's port without publishing it on the
+// host, similar to the “--expose” CLI flag, in “PORT[/L4PROTO]” format, where
+// the transport protocol defaults to “tcp”. Exposed ports get published only
+// when using [WithAllPortsPublished].
+func WithExposedPort(port string) Opt {
+	return func(o *Options) error {
+		portnum, l4proto, _ := strings.Cut(port, "/")
+		switch l4proto {
+		case "":
+			l4proto = "tcp"
+		case "tcp", "udp", "sctp":
+		default:
+			return fmt.Errorf("invalid exposed port, expected PORT[/L4PROTO], got: %s", port)
 		}
-		if o.Opts.HostConfig.PortBindings == nil {
-			o.Opts.HostConfig.PortBindings = network.PortMap{}
+		num, err := strconv.ParseUint(portnum, 10, 16)
+		if err != nil || num == 0 {
+			return fmt.Errorf("invalid exposed port, expected PORT[/L4PROTO], got: %s", port)
 		}
-		portProtoText := fmt.Sprintf("%d/%s", cntrPort, l4proto)
-		portProto, err := network.ParsePort(portProtoText)
-		if err != nil {
-			return err
+		portProto, ok := network.PortFrom(uint16(num), network.IPProtocol(l4proto))
+		if !ok {
+			return fmt.Errorf("invalid exposed port, expected PORT[/L4PROTO], got: %s", port)
 		}
+		ensure.Value(&o.Opts.Config)
+		ensure.Map(&o.Opts.Config.ExposedPorts)
 		o.Opts.Config.ExposedPorts[portProto] = struct{}{}
-		o.Opts.HostConfig.PortBindings[portProto] = append(o.Opts.HostConfig.PortBindings[portProto], network.PortBinding{
-			HostIP:   bindIP,
-			HostPort: strconv.FormatUint(uint64(hostPort), 10),
-		})
 		return nil
 	}
 }
@@ -419,86 +622,133 @@ func reportPortMappingError(mapping string) (bindIP netip.Addr, hostPort uint16,
 //   - “z” (sharing content among multiple containers) or “Z” (content
 //     is private and unshared).
 //
+// Alternatively, WithVolume takes a typed [Bind]. Bind volumes in string form
+// get parsed into a [Bind], see [ParseBind]. WithVolume passes bind volumes on
+// as such instead of as bind mounts, as Docker creates missing host paths only
+// for bind volumes; use [WithMount] with a [Bind] for bind mounts instead.
+//
 // [Volumes]: https://docs.docker.com/storage/volumes/
-func WithVolume(vol string) Opt {
-	parsedVol, err := volumespec.Parse(vol)
-	if err != nil {
-		return func(o *Options) error {
-			return fmt.Errorf("malformed WithVolume parameter %q, reason: %w",
-				vol, err)
+func WithVolume[V string | Bind](vol V) Opt {
+	var bind Bind
+	switch vol := any(vol).(type) {
+	case string:
+		parsedVol, err := volumespec.Parse(vol)
+		if err != nil {
+			return func(o *Options) error {
+				return fmt.Errorf("malformed WithVolume parameter %q, reason: %w",
+					vol, err)
+			}
 		}
-	}
 
-	if parsedVol.Source == "" {
-		// Anonymous volumes are still handled via the Volumes configuration API
-		// field.
-		return func(o *Options) error {
-			ensure.Value(&o.Opts.Config)
-			if o.Opts.Config.Volumes == nil {
-				o.Opts.Config.Volumes = map[string]struct{}{}
+		if parsedVol.Source == "" {
+			// Anonymous volumes are still handled via the Volumes configuration
+			// API field.
+			return func(o *Options) error {
+				ensure.Value(&o.Opts.Config)
+				if o.Opts.Config.Volumes == nil {
+					o.Opts.Config.Volumes = map[string]struct{}{}
+				}
+				o.Opts.Config.Volumes[vol] = struct{}{}
+				return nil
 			}
-			o.Opts.Config.Volumes[vol] = struct{}{}
-			return nil
 		}
-	}
 
-	// All other volume specs are now handled via the host configuration bind
-	// (mounts).
-	if parsedVol.Type == string(mount.TypeBind) {
-		vol = bindVolumeToBind(vol)
+		// All other volume specs are now handled via the host configuration
+		// bind (mounts); named volumes are passed on as is.
+		if parsedVol.Type != string(mount.TypeBind) {
+			return func(o *Options) error {
+				ensure.Value(&o.Opts.HostConfig)
+				o.Opts.HostConfig.Binds = append(o.Opts.HostConfig.Binds, vol)
+				return nil
+			}
+		}
+		if bind, err = ParseBind(vol); err != nil {
+			return func(o *Options) error {
+				return fmt.Errorf("malformed WithVolume parameter %q, reason: %w",
+					vol, err)
+			}
+		}
+	case Bind:
+		bind = vol
 	}
 	return func(o *Options) error {
+		bindvol, err := bind.volume()
+		if err != nil {
+			return err
+		}
 		ensure.Value(&o.Opts.HostConfig)
-		o.Opts.HostConfig.Binds = append(o.Opts.HostConfig.Binds, vol)
+		o.Opts.HostConfig.Binds = append(o.Opts.HostConfig.Binds, bindvol)
 		return nil
 	}
 }
 
-// bindVolumeToBind converts a volume string known to actually be of type bind
-// to its corresponding bind type string.
-func bindVolumeToBind(vol string) string {
-	source, target, ok := strings.Cut(vol, ":")
-	if !ok {
-		return vol
-	}
+// absSource returns the absolute path of a bind source relative to the current
+// working directory, that is, “.” or starting with “./”. Otherwise, it returns
+// the source unmodified.
+func absSource(source string) string {
 	if source != "." && !strings.HasPrefix(source, "./") {
-		return vol
+		return source
 	}
 	abssrc, err := filepath.Abs(source)
 	if err != nil {
-		return vol
+		return source
 	}
-	return abssrc + ":" + target
+	return abssrc
 }
 
-// WithMount adds a (bind) mount. Please see also Docker's [Bind mounts]
-// documentation.
+// WithMount adds a mount, such as a bind mount, in the comma-separated
+// “key=value” format of the “--mount” CLI flag. Please see also Docker's [Bind
+// mounts] documentation.
+//
+// Alternatively, WithMount takes a typed [Bind]. The string form isn't parsed
+// into a [Bind], as it supports further mount types and options.
 //
 // [Bind mounts]: https://docs.docker.com/storage/bind-mounts/
-func WithMount(mnt string) Opt {
+func WithMount[M string | Bind](mnt M) Opt {
 	return func(o *Options) error {
-		// Let's do a dry run on this single parameter first...
-		dry := dockercliopts.MountOpt{}
-		if err := dry.Set(mnt); err != nil { // ...actually an "add"
-			return fmt.Errorf("invalid WithMount parameter, reason: %w",
-				err)
+		var m mount.Mount
+		switch mnt := any(mnt).(type) {
+		case string:
+			// Let's do a dry run on this single parameter first...
+			dry := dockercliopts.MountOpt{}
+			if err := dry.Set(mnt); err != nil { // ...actually an "add"
+				return fmt.Errorf("invalid WithMount parameter, reason: %w",
+					err)
+			}
+			m = dry.Value()[0]
+		case Bind:
+			var err error
+			if m, err = mnt.mount(); err != nil {
+				return err
+			}
 		}
-		mount := dry.Value()[0]
 		ensure.Value(&o.Opts.HostConfig)
-		o.Opts.HostConfig.Mounts = append(o.Opts.HostConfig.Mounts, mount)
+		o.Opts.HostConfig.Mounts = append(o.Opts.HostConfig.Mounts, m)
 		return nil
 	}
 }
 
 // WithTmpfs specifies the path inside the container to mount a new tmpfs
 // instance on, using default options (unlimited size, world-writable).
-func WithTmpfs(path string) Opt {
+//
+// Alternatively, WithTmpfs takes a typed [Tmpfs] in order to additionally
+// specify the size and mode.
+func WithTmpfs[T string | Tmpfs](tmpfs T) Opt {
 	return func(o *Options) error {
-		ensure.Value(&o.Opts.HostConfig)
-		if o.Opts.HostConfig.Tmpfs == nil {
-			o.Opts.HostConfig.Tmpfs = map[string]string{}
+		var path, opts string
+		switch tmpfs := any(tmpfs).(type) {
+		case string:
+			path = tmpfs
+		case Tmpfs:
+			var err error
+			if opts, err = tmpfs.options(); err != nil {
+				return err
+			}
+			path = tmpfs.Target
 		}
-		o.Opts.HostConfig.Tmpfs[path] = ""
+		ensure.Value(&o.Opts.HostConfig)
+		ensure.Map(&o.Opts.HostConfig.Tmpfs)
+		o.Opts.HostConfig.Tmpfs[path] = opts
 		return nil
 	}
 }
@@ -527,31 +777,29 @@ func WithTmpfsOpts(path string, opts string) Opt {
 //   - /dev/foo:/dev/bar
 //   - /dev/foo:/dev/bar:rwm
 //
-// WithDevice will panic if the first (host path) element is empty. If the
-// container path is empty, the same path as in the host is assumed.The cgroup
-// permissions default to “rwm”.
+// WithDevice returns an error if the first (host path) element is empty. If
+// the container path is empty, the same path as in the host is assumed. The
+// cgroup permissions default to “rwm”.
+//
+// Alternatively, WithDevice takes a typed [Device].
 //
 // See also:
 // https://docs.docker.com/engine/reference/commandline/container_run/#device
-func WithDevice(dev string) Opt {
+func WithDevice[D string | Device](dev D) Opt {
 	return func(o *Options) error {
-		var device struct {
-			HostPath      string
-			ContainerPath string
-			CgroupPerms   string
-		}
-		if err := strukt.Unmarshal(dev, ":", &device); err != nil {
-			return fmt.Errorf("malformed WithDevice parameter %q, reason: %w",
-				dev, err)
-		}
-		if device.HostPath == "" {
-			return fmt.Errorf("WithDevice host path parameter must not be empty")
-		}
-		if device.ContainerPath == "" {
-			device.ContainerPath = device.HostPath
+		var device Device
+		switch dev := any(dev).(type) {
+		case string:
+			var err error
+			if device, err = ParseDevice(dev); err != nil {
+				return err
+			}
+		case Device:
+			device = dev
 		}
-		if device.CgroupPerms == "" {
-			device.CgroupPerms = "rwm" // read-write-mknod
+		device, err := device.normalized()
+		if err != nil {
+			return err
 		}
 		ensure.Value(&o.Opts.HostConfig)
 		o.Opts.HostConfig.Devices = append(o.Opts.HostConfig.Devices, container.DeviceMapping{
@@ -602,32 +850,27 @@ func WithConsoleSize(width, height uint) Opt {
 //
 // Please do not confuse with [WithNetworkMode] mode, where the latter
 // configures the Linux kernel net namespace to use.
-func WithNetwork(netw string) Opt {
+//
+// Alternatively, WithNetwork takes a typed [Endpoint].
+func WithNetwork[E string | Endpoint](netw E) Opt {
 	return func(o *Options) error {
-		dry := dockercliopts.NetworkOpt{}
-		if err := dry.Set(netw); err != nil {
-			return fmt.Errorf("malformed WithNetwork parameter %q, reason: %s",
-				netw, err)
-		}
-		ep := dry.Value()[0]
-		ensure.Value(&o.Opts.NetworkingConfig)
-		ensure.Map(&o.Opts.NetworkingConfig.EndpointsConfig)
-		var err error
-		var mac net.HardwareAddr
-		if ep.MacAddress != "" {
-			if mac, err = net.ParseMAC(ep.MacAddress); err != nil {
-				return fmt.Errorf("invalid MAC address, reason: %s", err)
+		var ep Endpoint
+		switch netw := any(netw).(type) {
+		case string:
+			var err error
+			if ep, err = ParseEndpoint(netw); err != nil {
+				return err
 			}
+		case Endpoint:
+			ep = netw
 		}
-		o.Opts.NetworkingConfig.EndpointsConfig[ep.Target] = &network.EndpointSettings{
-			NetworkID:         ep.Target,
-			Aliases:           ep.Aliases,
-			DriverOpts:        ep.DriverOpts,
-			Links:             ep.Links,
-			IPAddress:         ep.IPv4Address,
-			GlobalIPv6Address: ep.IPv6Address,
-			MacAddress:        network.HardwareAddr(mac),
+		settings, err := ep.settings()
+		if err != nil {
+			return err
 		}
+		ensure.Value(&o.Opts.NetworkingConfig)
+		ensure.Map(&o.Opts.NetworkingConfig.EndpointsConfig)
+		o.Opts.NetworkingConfig.EndpointsConfig[ep.Network] = settings
 		return nil
 	}
 }
@@ -641,6 +884,148 @@ func WithHostname(host string) Opt {
 	}
 }
 
+// WithDomainname configures the domain name to use inside the container.
+func WithDomainname(domain string) Opt {
+	return func(o *Options) error {
+		ensure.Value(&o.Opts.Config)
+		o.Opts.Config.Domainname = domain
+		return nil
+	}
+}
+
+// WithDNS adds the IP address of a DNS server to use inside the container
+// instead of the DNS servers of the Docker host.
+func WithDNS(ip string) Opt {
+	return func(o *Options) error {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return fmt.Errorf("invalid DNS server IP address, reason: %w", err)
+		}
+		ensure.Value(&o.Opts.HostConfig)
+		o.Opts.HostConfig.DNS = append(o.Opts.HostConfig.DNS, addr)
+		return nil
+	}
+}
+
+// WithDNSSearch adds a DNS search domain to use inside the container.
+func WithDNSSearch(domain string) Opt {
+	return func(o *Options) error {
+		if domain == "" || strings.ContainsFunc(domain, unicode.IsSpace) {
+			return fmt.Errorf("invalid DNS search domain %q", domain)
+		}
+		ensure.Value(&o.Opts.HostConfig)
+		o.Opts.HostConfig.DNSSearch = append(o.Opts.HostConfig.DNSSearch, domain)
+		return nil
+	}
+}
+
+// WithDNSOption adds a DNS resolver option to use inside the container, such as
+// “ndots:2”. Please see also [resolv.conf(5)].
+//
+// [resolv.conf(5)]: https://man7.org/linux/man-pages/man5/resolv.conf.5.html
+func WithDNSOption(opt string) Opt {
+	return func(o *Options) error {
+		if opt == "" || strings.ContainsFunc(opt, unicode.IsSpace) {
+			return fmt.Errorf("invalid DNS option %q", opt)
+		}
+		ensure.Value(&o.Opts.HostConfig)
+		o.Opts.HostConfig.DNSOptions = append(o.Opts.HostConfig.DNSOptions, opt)
+		return nil
+	}
+}
+
+// HostGateway is the magic IP address value for [WithExtraHost] that resolves
+// to the IP address of the Docker host.
+const HostGateway = "host-gateway"
+
+// WithExtraHost adds a custom host-to-IP mapping to the container's
+// “/etc/hosts”, in either “NAME:IP” or “NAME=IP” format, similar to the
+// “--add-host” CLI flag. IPv6 addresses can optionally be enclosed in square
+// brackets. The IP address can also be the magic value [HostGateway] in order
+// to map the name to the IP address of the Docker host.
+//
+// Illustrative examples:
+//
+//   - "foo.example:10.0.0.1"
+//   - "foo.example:[fe80::1]"
+//   - "host.docker.internal:host-gateway"
+func WithExtraHost(hostip string) Opt {
+	return func(o *Options) error {
+		name, ip, ok := strings.Cut(hostip, "=")
+		if !ok {
+			name, ip, ok = strings.Cut(hostip, ":")
+		}
+		if !ok || name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+			return fmt.Errorf("invalid extra host, expected NAME:IP, got: %s", hostip)
+		}
+		if strings.HasPrefix(ip, "[") && strings.HasSuffix(ip, "]") {
+			ip = ip[1 : len(ip)-1]
+		}
+		if ip != HostGateway {
+			if _, err := netip.ParseAddr(ip); err != nil {
+				return fmt.Errorf("invalid extra host, expected NAME:IP, got: %s", hostip)
+			}
+		}
+		ensure.Value(&o.Opts.HostConfig)
+		o.Opts.HostConfig.ExtraHosts = append(o.Opts.HostConfig.ExtraHosts, name+":"+ip)
+		return nil
+	}
+}
+
+// WithMACAddress configures the MAC address of the container's network
+// interface, in a format such as “02:42:ac:11:00:02”. If the container gets
+// attached to a single network using [WithNetwork], the MAC address applies to
+// this network. Otherwise, it applies to the network specified using
+// [WithNetworkMode], defaulting to the “bridge” network. As the MAC address is
+// applied only after all options have been applied, the order of options
+// doesn't matter; see also [Options.ApplyMACAddress].
+func WithMACAddress(mac string) Opt {
+	return func(o *Options) error {
+		hwaddr, err := net.ParseMAC(mac)
+		if err != nil {
+			return fmt.Errorf("invalid MAC address, reason: %w", err)
+		}
+		o.MACAddress = hwaddr
+		return nil
+	}
+}
+
+// ApplyMACAddress applies the MAC address configured using [WithMACAddress]
+// to the container's network endpoint, returning an error if the MAC address
+// is ambiguous or not supported in the configured network mode. Session.Run
+// automatically calls ApplyMACAddress after applying all options.
+func (o *Options) ApplyMACAddress() error {
+	if o.MACAddress == nil {
+		return nil
+	}
+	if hc := o.Opts.HostConfig; hc != nil &&
+		(hc.NetworkMode.IsHost() || hc.NetworkMode.IsNone() || hc.NetworkMode.IsContainer()) {
+		return fmt.Errorf("MAC address not supported in network mode %q", hc.NetworkMode)
+	}
+	ensure.Value(&o.Opts.NetworkingConfig)
+	ensure.Map(&o.Opts.NetworkingConfig.EndpointsConfig)
+	endpoints := o.Opts.NetworkingConfig.EndpointsConfig
+	if len(endpoints) > 1 {
+		return errors.New("MAC address is ambiguous for multiple networks, " +
+			"use WithNetwork with mac-address instead")
+	}
+	hwaddr := network.HardwareAddr(o.MACAddress)
+	o.MACAddress = nil
+	for _, ep := range endpoints {
+		ep.MacAddress = hwaddr
+		return nil
+	}
+	netw := "bridge"
+	if hc := o.Opts.HostConfig; hc != nil && hc.NetworkMode != "" && hc.NetworkMode.IsUserDefined() {
+		netw = string(o.Opts.HostConfig.NetworkMode)
+	}
+	endpoints[netw] = &network.EndpointSettings{
+		NetworkID:  netw,
+		MacAddress: hwaddr,
+	}
+	return nil
+}
+
 // WithRestartPolicy configures the restart policy (“no”, “always”,
 // “on-failure”, “unless-stopped”) as well as the maximum attempts at restarting
 // the container.
@@ -19,11 +19,14 @@ import (
 	"net/netip"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/api/types/mount"
 	"github.com/moby/moby/api/types/network"
 
+	"github.com/thediveo/morbyd/v2/pull"
+
 	gs "github.com/onsi/gomega/gstruct"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -90,7 +93,7 @@ var _ = Describe("run (container) options", func() {
 			WithTTY(),
 			WithAutoRemove(),
 			WithPrivileged(),
-			WithCapAdd("CAP_SUCCESS"),
+			WithCapAdd("sys_admin"),
 			WithCapDropAll(),
 			WithCgroupnsMode("c-host"),
 			WithIPCMode("i-host"),
@@ -129,7 +132,7 @@ var _ = Describe("run (container) options", func() {
 		Expect(*o.Opts.Config.StopTimeout).To(Equal(42))
 		Expect(o.Opts.Config.Tty).To(BeTrue())
 		Expect(o.Opts.HostConfig.Privileged).To(BeTrue())
-		Expect(o.Opts.HostConfig.CapAdd).To(ConsistOf("CAP_SUCCESS"))
+		Expect(o.Opts.HostConfig.CapAdd).To(ConsistOf("CAP_SYS_ADMIN"))
 		Expect(o.Opts.HostConfig.CapDrop).To(ConsistOf("ALL"))
 		Expect(o.Opts.HostConfig.CgroupnsMode).To(Equal(container.CgroupnsMode("c-host")))
 		Expect(o.Opts.HostConfig.IpcMode).To(Equal(container.IpcMode("i-host")))
@@ -183,6 +186,126 @@ var _ = Describe("run (container) options", func() {
 		Expect(o.Opts.HostConfig.Tmpfs).To(HaveKeyWithValue("/temp", "tmpfs-size=42"))
 	})
 
+	It("processes entrypoint, DNS, and other everyday options", func() {
+		o := opts(
+			WithEntrypoint("/bin/sh", "-c"),
+			WithWorkingDir("/srv"),
+			WithStdinOpen(),
+			WithStopTimeout(1500*time.Millisecond),
+			WithExposedPort("8080"),
+			WithExposedPort("53/udp"),
+			WithDomainname("example.org"),
+			WithDNS("10.0.0.53"),
+			WithDNS("fe80::53"),
+			WithDNSSearch("example.org"),
+			WithDNSOption("ndots:2"),
+			WithExtraHost("foo.example:10.0.0.1"),
+			WithExtraHost("bar.example=[fe80::1]"),
+			WithExtraHost("host.docker.internal:"+HostGateway),
+			WithAnnotations("foo=bar", "baz="),
+			WithMACAddress("02:42:ac:11:00:02"),
+		)
+		Expect(o.Opts.Config).To(And(
+			HaveField("Entrypoint", HaveExactElements("/bin/sh", "-c")),
+			HaveField("WorkingDir", "/srv"),
+			HaveField("OpenStdin", BeTrue()),
+			HaveField("StdinOnce", BeFalse()),
+			HaveField("StopTimeout", gs.PointTo(Equal(2))),
+			HaveField("ExposedPorts", And(
+				HaveLen(2),
+				HaveKey(network.MustParsePort("8080/tcp")),
+				HaveKey(network.MustParsePort("53/udp")))),
+			HaveField("Domainname", "example.org"),
+		))
+		Expect(o.Opts.HostConfig).To(And(
+			HaveField("PortBindings", BeEmpty()),
+			HaveField("DNS", HaveExactElements(
+				netip.MustParseAddr("10.0.0.53"), netip.MustParseAddr("fe80::53"))),
+			HaveField("DNSSearch", HaveExactElements("example.org")),
+			HaveField("DNSOptions", HaveExactElements("ndots:2")),
+			HaveField("ExtraHosts", HaveExactElements(
+				"foo.example:10.0.0.1", "bar.example:fe80::1", "host.docker.internal:host-gateway")),
+			HaveField("Annotations", And(
+				HaveKeyWithValue("foo", "bar"),
+				HaveKeyWithValue("baz", ""))),
+		))
+		Expect(o.MACAddress.String()).To(Equal("02:42:ac:11:00:02"))
+		Expect(o.ApplyMACAddress()).To(Succeed())
+		Expect(o.MACAddress).To(BeNil())
+		Expect(o.Opts.NetworkingConfig.EndpointsConfig).To(HaveKeyWithValue("bridge",
+			HaveField("MacAddress", WithTransform(network.HardwareAddr.String, Equal("02:42:ac:11:00:02")))))
+
+		Expect(opts(WithEntrypoint()).Opts.Config.Entrypoint).To(HaveExactElements(""))
+		Expect(opts(WithStopTimeout(0 * time.Second)).Opts.Config.StopTimeout).To(gs.PointTo(Equal(0)))
+
+		for _, o := range []Options{
+			opts(WithNetwork("foo"), WithMACAddress("02:42:ac:11:00:03")),
+			opts(WithMACAddress("02:42:ac:11:00:03"), WithNetwork("foo")),
+		} {
+			Expect(o.ApplyMACAddress()).To(Succeed())
+			Expect(o.Opts.NetworkingConfig.EndpointsConfig).To(And(
+				HaveLen(1),
+				HaveKeyWithValue("foo", HaveField("MacAddress", WithTransform(network.HardwareAddr.String, Equal("02:42:ac:11:00:03"))))))
+		}
+		o = opts(WithMACAddress("02:42:ac:11:00:04"), WithNetworkMode("foo"))
+		Expect(o.ApplyMACAddress()).To(Succeed())
+		Expect(o.Opts.NetworkingConfig.EndpointsConfig).To(
+			HaveKeyWithValue("foo", HaveField("MacAddress", WithTransform(network.HardwareAddr.String, Equal("02:42:ac:11:00:04")))))
+	})
+
+	DescribeTable("rejecting invalid everyday options",
+		func(opt Opt, expectedErr string) {
+			o := Options{}
+			Expect(opt(&o)).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("empty entrypoint executable", WithEntrypoint("", "foo"), "entrypoint executable must not be empty"),
+		Entry("relative working dir", WithWorkingDir("srv"), "working directory must be an absolute path"),
+		Entry("negative stop timeout", WithStopTimeout(-time.Second), "stop timeout must not be negative"),
+		Entry("exposed port protocol", WithExposedPort("80/http"), "invalid exposed port"),
+		Entry("exposed port number", WithExposedPort("http"), "invalid exposed port"),
+		Entry("exposed port zero", WithExposedPort("0"), "invalid exposed port"),
+		Entry("DNS server", WithDNS("10.0.0"), "invalid DNS server IP address"),
+		Entry("DNS search", WithDNSSearch("example org"), "invalid DNS search domain"),
+		Entry("DNS option", WithDNSOption(""), "invalid DNS option"),
+		Entry("extra host without IP", WithExtraHost("foo.example"), "invalid extra host"),
+		Entry("extra host without name", WithExtraHost(":10.0.0.1"), "invalid extra host"),
+		Entry("extra host IP", WithExtraHost("foo.example:gateway"), "invalid extra host"),
+		Entry("annotation", WithAnnotations("="), "invalid annotation"),
+		Entry("MAC address", WithMACAddress("02:42"), "invalid MAC address"),
+	)
+
+	It("rejects MAC addresses for ambiguous or non-private networks", func() {
+		o := opts(WithMACAddress("02:42:ac:11:00:02"), WithNetwork("foo"), WithNetwork("bar"))
+		Expect(o.ApplyMACAddress()).To(MatchError(ContainSubstring("ambiguous")))
+		o = opts(WithMACAddress("02:42:ac:11:00:02"), WithNetworkMode("host"))
+		Expect(o.ApplyMACAddress()).To(MatchError(
+			`MAC address not supported in network mode "host"`))
+	})
+
+	It("processes and validates reuse options", func() {
+		Expect(opts(WithReuse("db"), WithReuseTTL(time.Hour))).To(And(
+			HaveField("ReuseKey", "db"),
+			HaveField("ReuseTTL", time.Hour),
+		))
+
+		var o Options
+		Expect(WithReuse("")(&o)).To(MatchError("reuse key must not be empty"))
+		Expect(WithReuseTTL(0)(&o)).To(MatchError("reuse time-to-live must be positive, got 0s"))
+	})
+
+	It("processes and validates pull options", func() {
+		o := opts(WithPullPolicy(PullAlways),
+			WithPullOptions(pull.WithRegistryAuth("deadbeef")),
+			WithPullOptions(pull.WithPlatform("linux/arm64")))
+		Expect(o.PullPolicy).To(Equal(PullAlways))
+		Expect(o.PullOpts).To(HaveLen(2))
+		Expect(opts(WithPullPolicy(PullNever)).PullPolicy).To(Equal(PullNever))
+		Expect(opts(WithPullPolicy(PullMissing)).PullPolicy).To(Equal(PullMissing))
+
+		Expect(WithPullPolicy("sometimes")(&o)).To(MatchError(
+			`unsupported pull policy "sometimes", expected "missing", "always", or "never"`))
+	})
+
 	It("rejects invalid published port mappings", func() {
 		var o Options
 		Expect(WithPublishedPort("abcd")(&o)).To(HaveOccurred())
@@ -236,8 +359,8 @@ var _ = Describe("run (container) options", func() {
 		))
 	})
 
-	It("returns invalid volume string when converting to binds unmodified", Serial, func() {
-		Expect(bindVolumeToBind("")).To(BeEmpty())
+	It("returns bind sources unmodified when they cannot be made absolute", Serial, func() {
+		Expect(absSource("")).To(BeEmpty())
 
 		cwd := Successful(os.Getwd())
 		defer os.Chdir(cwd) //nolint:errcheck // any error is irrelevant at this point
@@ -245,7 +368,7 @@ var _ = Describe("run (container) options", func() {
 		defer os.RemoveAll(tmpdir) //nolint:errcheck // any error is irrelevant at this point
 		Expect(os.Chdir(tmpdir)).To(Succeed())
 		Expect(os.RemoveAll(tmpdir)).To(Succeed())
-		Expect(bindVolumeToBind("./relative:/absolute")).To(Equal("./relative:/absolute"))
+		Expect(absSource("./relative")).To(Equal("./relative"))
 	})
 
 	It("rejects when given a network in invalid long form", func() {
@@ -0,0 +1,89 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// SeccompAction is the action a seccomp profile takes for a syscall.
+type SeccompAction string
+
+// Seccomp actions supported in Docker seccomp profiles.
+const (
+	SeccompActAllow       SeccompAction = "SCMP_ACT_ALLOW"        // allow the syscall.
+	SeccompActErrno       SeccompAction = "SCMP_ACT_ERRNO"        // fail the syscall with an errno.
+	SeccompActKill        SeccompAction = "SCMP_ACT_KILL"         // kill the thread.
+	SeccompActKillProcess SeccompAction = "SCMP_ACT_KILL_PROCESS" // kill the process.
+	SeccompActTrap        SeccompAction = "SCMP_ACT_TRAP"         // send a SIGSYS signal.
+	SeccompActLog         SeccompAction = "SCMP_ACT_LOG"          // allow and log the syscall.
+)
+
+// SeccompProfile is a minimal seccomp profile in Docker's JSON profile format,
+// consisting of a default action and the actions for individual syscalls. Use
+// [NewSeccompProfile] to build profiles programmatically, and
+// [WithSeccompProfile] to apply them.
+type SeccompProfile struct {
+	DefaultAction   SeccompAction    `json:"defaultAction"`
+	DefaultErrnoRet *uint            `json:"defaultErrnoRet,omitempty"`
+	Syscalls        []SeccompSyscall `json:"syscalls,omitempty"`
+}
+
+// SeccompSyscall specifies the action for a set of syscalls.
+type SeccompSyscall struct {
+	Names    []string      `json:"names"`
+	Action   SeccompAction `json:"action"`
+	ErrnoRet *uint         `json:"errnoRet,omitempty"`
+}
+
+// NewSeccompProfile returns a new seccomp profile with the specified default
+// action for all syscalls not explicitly listed, such as [SeccompActAllow] for
+// denylisting, or [SeccompActErrno] for allowlisting syscalls.
+func NewSeccompProfile(defaultAction SeccompAction) *SeccompProfile {
+	return &SeccompProfile{DefaultAction: defaultAction}
+}
+
+// Allow allows the named syscalls, returning the profile for chaining.
+func (p *SeccompProfile) Allow(names ...string) *SeccompProfile {
+	return p.Action(SeccompActAllow, names...)
+}
+
+// Deny fails the named syscalls with the specified errno, such as 1 for
+// EPERM, returning the profile for chaining.
+func (p *SeccompProfile) Deny(errno uint, names ...string) *SeccompProfile {
+	p.Syscalls = append(p.Syscalls, SeccompSyscall{
+		Names:    slices.Clone(names),
+		Action:   SeccompActErrno,
+		ErrnoRet: &errno,
+	})
+	return p
+}
+
+// Action sets the action for the named syscalls, returning the profile for
+// chaining.
+func (p *SeccompProfile) Action(action SeccompAction, names ...string) *SeccompProfile {
+	p.Syscalls = append(p.Syscalls, SeccompSyscall{
+		Names:  slices.Clone(names),
+		Action: action,
+	})
+	return p
+}
+
+// JSON returns the profile in Docker's JSON profile format.
+func (p *SeccompProfile) JSON() string {
+	b, _ := json.Marshal(p) // cannot fail
+	return string(b)
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("seccomp profiles", func() {
+
+	It("builds profiles", func() {
+		p := NewSeccompProfile(SeccompActErrno).
+			Allow("read", "write").
+			Deny(1, "mount").
+			Action(SeccompActLog, "ptrace")
+		Expect(p.JSON()).To(MatchJSON(`{
+  "defaultAction": "SCMP_ACT_ERRNO",
+  "syscalls": [
+    {"names": ["read", "write"], "action": "SCMP_ACT_ALLOW"},
+    {"names": ["mount"], "action": "SCMP_ACT_ERRNO", "errnoRet": 1},
+    {"names": ["ptrace"], "action": "SCMP_ACT_LOG"}
+  ]
+}`))
+		Expect(NewSeccompProfile(SeccompActAllow).JSON()).To(
+			Equal(`{"defaultAction":"SCMP_ACT_ALLOW"}`))
+	})
+
+})
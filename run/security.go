@@ -0,0 +1,170 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+
+	"github.com/thediveo/morbyd/v2/caps"
+	"github.com/thediveo/morbyd/v2/identity"
+	"github.com/thediveo/morbyd/v2/internal/ensure"
+)
+
+// WithSeccompProfile applies a seccomp profile to the container, either in
+// form of a profile built using [NewSeccompProfile], or a string with either
+// the profile's JSON or the path of a JSON profile file. Similar to the
+// “--security-opt seccomp=PATH” CLI flag, WithSeccompProfile loads the
+// profile and then inlines it into the container configuration.
+func WithSeccompProfile[P string | *SeccompProfile](profile P) Opt {
+	return func(o *Options) error {
+		var profjson []byte
+		switch profile := any(profile).(type) {
+		case string:
+			profjson = []byte(profile)
+			if !strings.HasPrefix(strings.TrimSpace(profile), "{") {
+				var err error
+				if profjson, err = os.ReadFile(profile); err != nil {
+					return fmt.Errorf("cannot load seccomp profile %q, reason: %w", profile, err)
+				}
+			}
+		case *SeccompProfile:
+			if profile == nil {
+				return errors.New("seccomp profile must not be nil")
+			}
+			profjson = []byte(profile.JSON())
+		}
+		var prof SeccompProfile
+		if err := json.Unmarshal(profjson, &prof); err != nil {
+			return fmt.Errorf("invalid seccomp profile, reason: %w", err)
+		}
+		if prof.DefaultAction == "" {
+			return errors.New("invalid seccomp profile, missing defaultAction")
+		}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, profjson); err != nil {
+			return fmt.Errorf("invalid seccomp profile, reason: %w", err)
+		}
+		return WithSecurityOpt("seccomp=" + compacted.String())(o)
+	}
+}
+
+// WithSeccompUnconfined runs the container without the default seccomp
+// profile.
+func WithSeccompUnconfined() Opt {
+	return WithSecurityOpt("seccomp=unconfined")
+}
+
+// WithAppArmorProfile runs the container with the named AppArmor profile,
+// which must already be loaded on the Docker host, or “unconfined” for
+// running the container without any AppArmor profile.
+func WithAppArmorProfile(profile string) Opt {
+	return func(o *Options) error {
+		if profile == "" {
+			return errors.New("AppArmor profile name must not be empty")
+		}
+		return WithSecurityOpt("apparmor=" + profile)(o)
+	}
+}
+
+// WithSELinuxLabel applies an SELinux label to the container, in form of
+// either “user:USER”, “role:ROLE”, “type:TYPE”, “level:LEVEL”, or
+// “filetype:TYPE”, or “disable” for turning off SELinux labelling. This
+// option can be specified multiple times.
+func WithSELinuxLabel(label string) Opt {
+	return func(o *Options) error {
+		if label != "disable" {
+			field, value, ok := strings.Cut(label, ":")
+			switch field {
+			case "user", "role", "type", "level", "filetype":
+			default:
+				ok = false
+			}
+			if !ok || value == "" {
+				return fmt.Errorf("invalid SELinux label, expected FIELD:VALUE or \"disable\", got: %s", label)
+			}
+		}
+		return WithSecurityOpt("label=" + label)(o)
+	}
+}
+
+// WithNoNewPrivileges prevents the processes in the container from gaining
+// new privileges, such as through setuid binaries or file capabilities.
+func WithNoNewPrivileges() Opt {
+	return WithSecurityOpt("no-new-privileges=true")
+}
+
+// WithCapDrop drops an individual kernel capability from the initial process
+// in the container. The capability name is case-insensitive and may or may
+// not have the “CAP_” prefix; it also can be “ALL”, see also
+// [WithCapDropAll].
+//
+// Please note that the default set of Docker container capabilities depends
+// on the Docker engine and Linux kernel, so that dropping individual
+// capabilities is mainly useful in combination with checking for them.
+func WithCapDrop(capability string) Opt {
+	return func(o *Options) error {
+		name, err := caps.Normalize(capability)
+		if err != nil {
+			return fmt.Errorf("invalid capability to drop, reason: %w", err)
+		}
+		ensure.Value(&o.Opts.HostConfig)
+		o.Opts.HostConfig.CapDrop = append(o.Opts.HostConfig.CapDrop, name)
+		return nil
+	}
+}
+
+// WithUsernsMode configures the user namespace to use when creating the new
+// container; it can be either “” (empty, use the daemon's default), or “host”.
+func WithUsernsMode(mode string) Opt {
+	return func(o *Options) error {
+		if !container.UsernsMode(mode).Valid() {
+			return fmt.Errorf("invalid user namespace mode %q, expected \"\" or \"host\"", mode)
+		}
+		ensure.Value(&o.Opts.HostConfig)
+		o.Opts.HostConfig.UsernsMode = container.UsernsMode(mode)
+		return nil
+	}
+}
+
+// WithGroupAdd adds a supplementary group, by name or ID, the processes in the
+// container additionally run as.
+func WithGroupAdd[P identity.Principal](group P) Opt {
+	return func(o *Options) error {
+		var name string
+		switch group := any(group).(type) {
+		case string:
+			name = group
+		case int:
+			if group < 0 {
+				return fmt.Errorf("invalid supplementary group ID %d", group)
+			}
+			name = strconv.Itoa(group)
+		}
+		if name == "" {
+			return errors.New("supplementary group must not be empty")
+		}
+		ensure.Value(&o.Opts.HostConfig)
+		o.Opts.HostConfig.GroupAdd = append(o.Opts.HostConfig.GroupAdd, name)
+		return nil
+	}
+}
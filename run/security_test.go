@@ -0,0 +1,100 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/moby/moby/api/types/container"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("security run options", func() {
+
+	It("applies security options", func() {
+		o := opts(
+			WithSeccompProfile(NewSeccompProfile(SeccompActAllow).Deny(1, "mount")),
+			WithSeccompProfile(` { "defaultAction": "SCMP_ACT_LOG" } `),
+			WithSeccompUnconfined(),
+			WithAppArmorProfile("morbyd-default"),
+			WithSELinuxLabel("level:s0:c100,c200"),
+			WithSELinuxLabel("disable"),
+			WithNoNewPrivileges(),
+			WithCapDropAll(),
+			WithCapDrop("cap_net_raw"),
+			WithUsernsMode("host"),
+			WithGroupAdd("wheel"),
+			WithGroupAdd(42),
+		)
+		Expect(o.Opts.HostConfig.SecurityOpt).To(HaveExactElements(
+			`seccomp={"defaultAction":"SCMP_ACT_ALLOW","syscalls":[{"names":["mount"],"action":"SCMP_ACT_ERRNO","errnoRet":1}]}`,
+			`seccomp={"defaultAction":"SCMP_ACT_LOG"}`,
+			"seccomp=unconfined",
+			"apparmor=morbyd-default",
+			"label=level:s0:c100,c200",
+			"label=disable",
+			"no-new-privileges=true",
+		))
+		Expect(o.Opts.HostConfig).To(And(
+			HaveField("CapDrop", HaveExactElements("ALL", "CAP_NET_RAW")),
+			HaveField("UsernsMode", container.UsernsMode("host")),
+			HaveField("GroupAdd", HaveExactElements("wheel", "42")),
+		))
+	})
+
+	It("loads seccomp profiles from files", func() {
+		profile := filepath.Join(GinkgoT().TempDir(), "seccomp.json")
+		Expect(os.WriteFile(profile, []byte(`{
+  "defaultAction": "SCMP_ACT_ERRNO",
+  "syscalls": [ { "names": [ "read" ], "action": "SCMP_ACT_ALLOW" } ]
+}`), 0o644)).To(Succeed())
+		Expect(opts(WithSeccompProfile(profile)).Opts.HostConfig.SecurityOpt).To(ConsistOf(
+			`seccomp={"defaultAction":"SCMP_ACT_ERRNO","syscalls":[{"names":["read"],"action":"SCMP_ACT_ALLOW"}]}`))
+	})
+
+	DescribeTable("rejecting invalid security options",
+		func(opt Opt, expectedErr string) {
+			Expect(opt(&Options{})).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("missing profile file", WithSeccompProfile("/nada/seccomp.json"),
+			`cannot load seccomp profile "/nada/seccomp.json"`),
+		Entry("nil profile", WithSeccompProfile[*SeccompProfile](nil),
+			"seccomp profile must not be nil"),
+		Entry("malformed profile", WithSeccompProfile("{"),
+			"invalid seccomp profile"),
+		Entry("profile without default action", WithSeccompProfile(`{"syscalls":[]}`),
+			"missing defaultAction"),
+		Entry("empty AppArmor profile", WithAppArmorProfile(""),
+			"AppArmor profile name must not be empty"),
+		Entry("invalid SELinux label", WithSELinuxLabel("color:blue"),
+			"invalid SELinux label"),
+		Entry("empty SELinux label value", WithSELinuxLabel("type:"),
+			"invalid SELinux label"),
+		Entry("unknown capability to drop", WithCapDrop("CAP_FOO"),
+			`unknown capability "CAP_FOO"`),
+		Entry("unknown capability to add", WithCapAdd("FOO"),
+			`unknown capability "FOO"`),
+		Entry("invalid user namespace mode", WithUsernsMode("private"),
+			"invalid user namespace mode"),
+		Entry("empty group", WithGroupAdd(""),
+			"supplementary group must not be empty"),
+		Entry("negative group ID", WithGroupAdd(-1),
+			"invalid supplementary group ID -1"),
+	)
+
+})
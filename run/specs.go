@@ -0,0 +1,341 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	dockercliopts "github.com/docker/cli/opts"
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/thediveo/morbyd/v2/run/internal/volumespec"
+	"github.com/thediveo/morbyd/v2/strukt"
+)
+
+// Port describes publishing a container's port on the host, as a typed
+// alternative to the mapping strings of [WithPublishedPort].
+type Port struct {
+	// Host IP address and port to bind to. A zero (invalid) address binds to
+	// the host's unspecified IP address(es), a zero port to a random,
+	// available host port.
+	Host netip.AddrPort
+	// Container port to publish; must not be zero.
+	Container uint16
+	// Transport protocol “tcp”, “udp”, or “sctp”; defaults to “tcp”.
+	Proto string
+}
+
+// ParsePort parses a port publishing mapping in
+// “[HOSTIP:][HOSTPORT:]CONTAINERPORT[/L4PROTO]” format, see also
+// [WithPublishedPort].
+func ParsePort(mapping string) (Port, error) {
+	bindIP, hostPort, cntrPort, l4proto, err := parsePortMapping(mapping)
+	if err != nil {
+		return Port{}, err
+	}
+	return Port{
+		Host:      netip.AddrPortFrom(bindIP, hostPort),
+		Container: cntrPort,
+		Proto:     l4proto,
+	}, nil
+}
+
+// portProto validates the port and returns the container's port with its
+// transport protocol.
+func (p Port) portProto() (network.Port, error) {
+	l4proto := p.Proto
+	if l4proto == "" {
+		l4proto = "tcp"
+	}
+	if p.Container == 0 {
+		return network.Port{}, errors.New("invalid published port, container port must not be zero")
+	}
+	switch l4proto {
+	case "tcp", "udp", "sctp":
+	default:
+		return network.Port{}, fmt.Errorf("invalid published port, unsupported protocol %q", p.Proto)
+	}
+	portProto, _ := network.PortFrom(p.Container, network.IPProtocol(l4proto))
+	return portProto, nil
+}
+
+// Bind describes bind-mounting a host path into the container, as a typed
+// alternative to the strings of [WithMount] and [WithVolume].
+//
+// The bind volume strings of [WithVolume] parse into Bind, see [ParseBind].
+// [WithVolume] keeps Docker's bind volume semantics, such as creating missing
+// host paths and SELinux relabeling, while [WithMount] creates a bind mount
+// instead. The mount strings of [WithMount] don't parse into Bind, as they
+// support further mount types and options.
+type Bind struct {
+	// Host path to bind-mount; relative paths starting with “./” are relative
+	// to the current working directory.
+	Source string
+	// Absolute path inside the container to mount the host path onto.
+	Target string
+	// Mount read-only instead of read-write.
+	ReadOnly bool
+	// Optional mount propagation, such as “rslave”.
+	Propagation mount.Propagation
+	// Optional SELinux relabeling, “z” for content shared among multiple
+	// containers, or “Z” for private, unshared content. Only supported by
+	// [WithVolume], but not by [WithMount].
+	Relabel string
+}
+
+// ParseBind parses a bind volume in “SOURCE:TARGET[:OPTIONS]” format, see also
+// [WithVolume]. The source must be a host path.
+func ParseBind(vol string) (Bind, error) {
+	parsedVol, err := volumespec.Parse(vol)
+	if err != nil {
+		return Bind{}, fmt.Errorf("malformed bind %q, reason: %w", vol, err)
+	}
+	if parsedVol.Type != string(mount.TypeBind) {
+		return Bind{}, fmt.Errorf("malformed bind %q, source must be a host path", vol)
+	}
+	bind := Bind{
+		Source:   parsedVol.Source,
+		Target:   parsedVol.Target,
+		ReadOnly: parsedVol.ReadOnly,
+	}
+	if parsedVol.Bind != nil {
+		bind.Propagation = mount.Propagation(parsedVol.Bind.Propagation)
+	}
+	// The volume spec parser ignores SELinux relabeling options, so we need to
+	// pick them up ourselves.
+	if opts, ok := strings.CutPrefix(vol, parsedVol.Source+":"+parsedVol.Target+":"); ok {
+		for opt := range strings.SplitSeq(opts, ",") {
+			if opt == "z" || opt == "Z" {
+				bind.Relabel = opt
+			}
+		}
+	}
+	return bind, nil
+}
+
+// validate the bind, returning an error if invalid.
+func (b Bind) validate() error {
+	if b.Source == "" {
+		return errors.New("invalid bind, source must not be empty")
+	}
+	if !path.IsAbs(b.Target) {
+		return fmt.Errorf("invalid bind, target must be an absolute path, got: %q", b.Target)
+	}
+	if b.Propagation != "" && !slices.Contains(mount.Propagations, b.Propagation) {
+		return fmt.Errorf("invalid bind, unsupported propagation %q", b.Propagation)
+	}
+	switch b.Relabel {
+	case "", "z", "Z":
+	default:
+		return fmt.Errorf("invalid bind, unsupported SELinux relabeling %q, expected \"z\" or \"Z\"", b.Relabel)
+	}
+	return nil
+}
+
+// mount validates the bind and returns the corresponding bind mount.
+func (b Bind) mount() (mount.Mount, error) {
+	if err := b.validate(); err != nil {
+		return mount.Mount{}, err
+	}
+	if b.Relabel != "" {
+		return mount.Mount{}, errors.New("invalid bind, bind mounts don't support SELinux relabeling, use WithVolume instead")
+	}
+	mnt := mount.Mount{
+		Type:     mount.TypeBind,
+		Source:   absSource(b.Source),
+		Target:   b.Target,
+		ReadOnly: b.ReadOnly,
+	}
+	if b.Propagation != "" {
+		mnt.BindOptions = &mount.BindOptions{Propagation: b.Propagation}
+	}
+	return mnt, nil
+}
+
+// volume validates the bind and returns the corresponding bind volume in
+// “SOURCE:TARGET[:OPTIONS]” format.
+func (b Bind) volume() (string, error) {
+	if err := b.validate(); err != nil {
+		return "", err
+	}
+	var opts []string
+	if b.ReadOnly {
+		opts = append(opts, "ro")
+	}
+	if b.Propagation != "" {
+		opts = append(opts, string(b.Propagation))
+	}
+	if b.Relabel != "" {
+		opts = append(opts, b.Relabel)
+	}
+	vol := absSource(b.Source) + ":" + b.Target
+	if len(opts) > 0 {
+		vol += ":" + strings.Join(opts, ",")
+	}
+	return vol, nil
+}
+
+// Tmpfs describes a new tmpfs instance to mount inside the container, as a
+// typed alternative to the options string of [WithTmpfsOpts].
+type Tmpfs struct {
+	// Absolute path inside the container to mount the tmpfs instance onto.
+	Target string
+	// Size in bytes; defaults to unlimited.
+	Size int64
+	// Permissions of the tmpfs root directory; defaults to world-writable
+	// “1777”. The sticky bit can be set either using [os.ModeSticky] or the
+	// octal Unix mode bit, as in 0o1777.
+	Mode os.FileMode
+}
+
+// options validates the tmpfs and returns its mount options.
+func (t Tmpfs) options() (string, error) {
+	if !path.IsAbs(t.Target) {
+		return "", fmt.Errorf("invalid tmpfs, target must be an absolute path, got: %q", t.Target)
+	}
+	if t.Size < 0 {
+		return "", fmt.Errorf("invalid tmpfs, size must not be negative, got %d", t.Size)
+	}
+	var opts []string
+	if t.Size > 0 {
+		opts = append(opts, "size="+strconv.FormatInt(t.Size, 10))
+	}
+	if t.Mode != 0 {
+		mode := uint64(t.Mode.Perm())
+		if t.Mode&(os.ModeSticky|0o1000) != 0 {
+			mode |= 0o1000
+		}
+		opts = append(opts, "mode="+strconv.FormatUint(mode, 8))
+	}
+	return strings.Join(opts, ","), nil
+}
+
+// Endpoint describes attaching the container to a network, as a typed
+// alternative to the strings of [WithNetwork].
+type Endpoint struct {
+	// Name or ID of the network; must not be empty.
+	Network string
+	// Optional static IPv4 and IPv6 addresses.
+	IPv4 netip.Addr
+	IPv6 netip.Addr
+	// Optional additional DNS names of the container on this network.
+	Aliases []string
+	// Optional MAC address of the container's network interface.
+	MACAddress net.HardwareAddr
+	// Optional links to other containers, in “NAME:ALIAS” format.
+	Links []string
+	// Optional network driver-specific options.
+	DriverOpts map[string]string
+}
+
+// ParseEndpoint parses a network attachment, either in form of a network
+// name or ID, or in long format of comma-separated key-value pairs, see also
+// [WithNetwork].
+func ParseEndpoint(netw string) (Endpoint, error) {
+	dry := dockercliopts.NetworkOpt{}
+	if err := dry.Set(netw); err != nil {
+		return Endpoint{}, fmt.Errorf("malformed WithNetwork parameter %q, reason: %s",
+			netw, err)
+	}
+	ep := dry.Value()[0]
+	var mac net.HardwareAddr
+	if ep.MacAddress != "" {
+		var err error
+		if mac, err = net.ParseMAC(ep.MacAddress); err != nil {
+			return Endpoint{}, fmt.Errorf("invalid MAC address, reason: %s", err)
+		}
+	}
+	return Endpoint{
+		Network:    ep.Target,
+		IPv4:       ep.IPv4Address,
+		IPv6:       ep.IPv6Address,
+		Aliases:    ep.Aliases,
+		MACAddress: mac,
+		Links:      ep.Links,
+		DriverOpts: ep.DriverOpts,
+	}, nil
+}
+
+// settings validates the endpoint and returns its endpoint settings.
+func (e Endpoint) settings() (*network.EndpointSettings, error) {
+	if e.Network == "" {
+		return nil, errors.New("invalid endpoint, network must not be empty")
+	}
+	if e.IPv4.IsValid() && !e.IPv4.Is4() {
+		return nil, fmt.Errorf("invalid endpoint, not an IPv4 address: %s", e.IPv4)
+	}
+	if e.IPv6.IsValid() && (!e.IPv6.Is6() || e.IPv6.Is4In6()) {
+		return nil, fmt.Errorf("invalid endpoint, not an IPv6 address: %s", e.IPv6)
+	}
+	return &network.EndpointSettings{
+		NetworkID:         e.Network,
+		Aliases:           e.Aliases,
+		DriverOpts:        e.DriverOpts,
+		Links:             e.Links,
+		IPAddress:         e.IPv4,
+		GlobalIPv6Address: e.IPv6,
+		MacAddress:        network.HardwareAddr(e.MACAddress),
+	}, nil
+}
+
+// Device describes a host device to add to the container, as a typed
+// alternative to the strings of [WithDevice].
+type Device struct {
+	// Path of the device on the host; must not be empty.
+	HostPath string
+	// Path of the device inside the container; defaults to the host path.
+	ContainerPath string
+	// cgroup permissions, a combination of “r”, “w”, and “m”; defaults to
+	// “rwm”.
+	CgroupPerms string
+}
+
+// ParseDevice parses a device in “HOSTPATH[:CONTAINERPATH[:PERMISSIONS]]”
+// format, see also [WithDevice].
+func ParseDevice(dev string) (Device, error) {
+	var device Device
+	if err := strukt.Unmarshal(dev, ":", &device); err != nil {
+		return Device{}, fmt.Errorf("malformed WithDevice parameter %q, reason: %w",
+			dev, err)
+	}
+	return device, nil
+}
+
+// normalized validates the device and returns it with defaults applied.
+func (d Device) normalized() (Device, error) {
+	if d.HostPath == "" {
+		return Device{}, fmt.Errorf("WithDevice host path parameter must not be empty")
+	}
+	if d.ContainerPath == "" {
+		d.ContainerPath = d.HostPath
+	}
+	if d.CgroupPerms == "" {
+		d.CgroupPerms = "rwm" // read-write-mknod
+	}
+	if strings.Trim(d.CgroupPerms, "rwm") != "" {
+		return Device{}, fmt.Errorf("invalid device cgroup permissions %q, expected combination of \"rwm\"",
+			d.CgroupPerms)
+	}
+	return d, nil
+}
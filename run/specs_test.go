@@ -0,0 +1,177 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package run
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/moby/api/types/mount"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("typed run options", func() {
+
+	It("parses port mappings into ports", func() {
+		Expect(ParsePort("127.0.0.1:666:1234/udp")).To(Equal(Port{
+			Host:      netip.MustParseAddrPort("127.0.0.1:666"),
+			Container: 1234,
+			Proto:     "udp",
+		}))
+		Expect(ParsePort("[::1]:1234")).To(Equal(Port{
+			Host:      netip.AddrPortFrom(netip.MustParseAddr("::1"), 0),
+			Container: 1234,
+			Proto:     "tcp",
+		}))
+		Expect(ParsePort("1234/tcp")).To(Equal(Port{Container: 1234, Proto: "tcp"}))
+		Expect(ParsePort("abcd")).Error().To(HaveOccurred())
+	})
+
+	It("publishes typed ports the same as port mappings", func() {
+		Expect(opts(
+			WithPublishedPort(Port{Container: 1234}),
+			WithPublishedPort(Port{
+				Host:      netip.MustParseAddrPort("[fe80::dead:beef]:2345"),
+				Container: 53,
+				Proto:     "udp",
+			}),
+		)).To(Equal(opts(
+			WithPublishedPort("1234"),
+			WithPublishedPort("[fe80::dead:beef]:2345:53/udp"),
+		)))
+	})
+
+	It("parses volumes into binds", func() {
+		Expect(ParseBind("/foo:/bar:ro,rslave")).To(Equal(Bind{
+			Source:      "/foo",
+			Target:      "/bar",
+			ReadOnly:    true,
+			Propagation: mount.PropagationRSlave,
+		}))
+		Expect(ParseBind("./foo:/bar")).To(Equal(Bind{Source: "./foo", Target: "/bar"}))
+		Expect(ParseBind("/foo:/bar:Z,ro")).To(Equal(Bind{
+			Source:   "/foo",
+			Target:   "/bar",
+			ReadOnly: true,
+			Relabel:  "Z",
+		}))
+		Expect(ParseBind("foo:/bar")).Error().To(MatchError(ContainSubstring("source must be a host path")))
+		Expect(ParseBind("/foo:/bar:baz:ro")).Error().To(MatchError(ContainSubstring("malformed bind")))
+	})
+
+	It("mounts typed binds the same as mount specs", func() {
+		Expect(opts(
+			WithMount(Bind{Source: "/foo", Target: "/bar", ReadOnly: true, Propagation: mount.PropagationRPrivate}),
+			WithMount(Bind{Source: "/foo", Target: "/baz"}),
+		).Opts.HostConfig.Mounts).To(Equal(opts(
+			WithMount("type=bind,source=/foo,target=/bar,readonly,bind-propagation=rprivate"),
+			WithMount("type=bind,source=/foo,target=/baz"),
+		).Opts.HostConfig.Mounts))
+
+		cwd := Successful(os.Getwd())
+		Expect(opts(WithMount(Bind{Source: "./foo", Target: "/bar"})).Opts.HostConfig.Mounts).To(
+			ConsistOf(HaveField("Source", filepath.Join(cwd, "foo"))))
+	})
+
+	It("adds typed binds the same as bind volume specs", func() {
+		Expect(opts(
+			WithVolume(Bind{Source: "/foo", Target: "/bar", ReadOnly: true, Propagation: mount.PropagationRSlave, Relabel: "z"}),
+			WithVolume(Bind{Source: "./foo", Target: "/baz"}),
+		).Opts.HostConfig.Binds).To(Equal(opts(
+			WithVolume("/foo:/bar:z,rslave,ro"),
+			WithVolume("./foo:/baz"),
+		).Opts.HostConfig.Binds))
+
+		cwd := Successful(os.Getwd())
+		Expect(opts(WithVolume("./foo:/bar:ro")).Opts.HostConfig.Binds).To(
+			ConsistOf(filepath.Join(cwd, "foo") + ":/bar:ro"))
+		Expect(opts(WithVolume("foo:/bar:ro")).Opts.HostConfig.Binds).To(
+			ConsistOf("foo:/bar:ro"))
+	})
+
+	It("mounts typed tmpfs instances", func() {
+		Expect(opts(
+			WithTmpfs(Tmpfs{Target: "/run", Size: 64 << 20, Mode: 0o700}),
+			WithTmpfs(Tmpfs{Target: "/tmp", Mode: os.ModeSticky | 0o777}),
+			WithTmpfs(Tmpfs{Target: "/var/tmp", Mode: 0o1777}),
+			WithTmpfs(Tmpfs{Target: "/var/run"}),
+		).Opts.HostConfig.Tmpfs).To(Equal(map[string]string{
+			"/run":     "size=67108864,mode=700",
+			"/tmp":     "mode=1777",
+			"/var/tmp": "mode=1777",
+			"/var/run": "",
+		}))
+	})
+
+	It("parses and attaches typed endpoints the same as network specs", func() {
+		Expect(ParseEndpoint("name=foo,alias=bar,ip=10.0.0.2,mac-address=02:42:ac:11:00:02")).To(Equal(Endpoint{
+			Network:    "foo",
+			IPv4:       netip.MustParseAddr("10.0.0.2"),
+			Aliases:    []string{"bar"},
+			MACAddress: net.HardwareAddr{0x02, 0x42, 0xac, 0x11, 0x00, 0x02},
+		}))
+		Expect(opts(WithNetwork(Endpoint{
+			Network: "foo",
+			IPv4:    netip.MustParseAddr("10.0.0.2"),
+			IPv6:    netip.MustParseAddr("fd00::2"),
+			Aliases: []string{"bar"},
+		}))).To(Equal(opts(WithNetwork("name=foo,alias=bar,ip=10.0.0.2,ip6=fd00::2"))))
+	})
+
+	It("parses and adds typed devices the same as device specs", func() {
+		Expect(ParseDevice("/dev/foo::r")).To(Equal(Device{HostPath: "/dev/foo", CgroupPerms: "r"}))
+		Expect(opts(
+			WithDevice(Device{HostPath: "/dev/foo"}),
+			WithDevice(Device{HostPath: "/dev/foo", ContainerPath: "/dev/bar", CgroupPerms: "rw"}),
+		)).To(Equal(opts(
+			WithDevice("/dev/foo"),
+			WithDevice("/dev/foo:/dev/bar:rw"),
+		)))
+	})
+
+	DescribeTable("rejecting invalid typed options",
+		func(opt Opt, expectedErr string) {
+			o := Options{}
+			Expect(opt(&o)).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("zero container port", WithPublishedPort(Port{}), "container port must not be zero"),
+		Entry("port protocol", WithPublishedPort(Port{Container: 80, Proto: "http"}), "unsupported protocol"),
+		Entry("bind source", WithMount(Bind{Target: "/bar"}), "source must not be empty"),
+		Entry("bind target", WithMount(Bind{Source: "/foo", Target: "bar"}), "target must be an absolute path"),
+		Entry("bind relabeling", WithVolume(Bind{Source: "/foo", Target: "/bar", Relabel: "y"}),
+			"unsupported SELinux relabeling"),
+		Entry("bind mount relabeling", WithMount(Bind{Source: "/foo", Target: "/bar", Relabel: "z"}),
+			"don't support SELinux relabeling"),
+		Entry("bind volume target", WithVolume(Bind{Source: "/foo", Target: "bar"}), "target must be an absolute path"),
+		Entry("bind propagation", WithMount(Bind{Source: "/foo", Target: "/bar", Propagation: "public"}),
+			"unsupported propagation"),
+		Entry("tmpfs target", WithTmpfs(Tmpfs{Target: "run"}), "target must be an absolute path"),
+		Entry("tmpfs size", WithTmpfs(Tmpfs{Target: "/run", Size: -1}), "size must not be negative"),
+		Entry("endpoint network", WithNetwork(Endpoint{}), "network must not be empty"),
+		Entry("endpoint IPv4", WithNetwork(Endpoint{Network: "foo", IPv4: netip.MustParseAddr("fd00::1")}),
+			"not an IPv4 address"),
+		Entry("endpoint IPv6", WithNetwork(Endpoint{Network: "foo", IPv6: netip.MustParseAddr("10.0.0.1")}),
+			"not an IPv6 address"),
+		Entry("device host path", WithDevice(Device{ContainerPath: "/dev/foo"}), "host path parameter must not be empty"),
+		Entry("device permissions", WithDevice(Device{HostPath: "/dev/foo", CgroupPerms: "rx"}),
+			"invalid device cgroup permissions"),
+	)
+
+})
@@ -16,12 +16,18 @@ package morbyd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/containerd/errdefs"
 	"github.com/moby/moby/client"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/thediveo/morbyd/v2/moby"
 	"github.com/thediveo/morbyd/v2/session"
@@ -31,8 +37,12 @@ import (
 // configuration options that are inherited to newly created images, containers,
 // and networks.
 type Session struct {
-	opts session.Options
-	moby moby.Client
+	opts   session.Options
+	moby   moby.Client
+	shared bool // Docker client is owned by a parent session.
+
+	teardownsMu sync.Mutex
+	teardowns   []func(context.Context) error // run when closing this session.
 }
 
 // NewSession creates a new Docker client and test session, returning a Session
@@ -72,24 +82,92 @@ func NewSession(ctx context.Context, opts ...session.Opt) (*Session, error) {
 func (s *Session) Client() moby.Client { return s.moby }
 
 // Close removes left-over containers and networks if auto-cleaning has been
-// enabled, and then closes idle HTTP connections to the Docker daemon.
+// enabled, and then closes idle HTTP connections to the Docker daemon. Use
+// [Session.CloseErr] instead in order to learn about any left-overs that could
+// not be removed.
 func (s *Session) Close(ctx context.Context) {
-	s.AutoClean(ctx)
-	s.moby.Close() //nolint:errcheck // any error is irrelevant at this point
+	_ = s.CloseErr(ctx)
+}
+
+// CloseErr tears down session fixtures, such as registries started using
+// [Session.RunRegistry], removes left-over containers and networks if
+// auto-cleaning has been enabled, and then closes idle HTTP connections to the
+// Docker daemon, unless this is a child session sharing its parent's Docker
+// client. It returns an aggregated error listing the fixtures, containers and
+// networks that could not be removed, as well as any error closing the Docker
+// client.
+func (s *Session) CloseErr(ctx context.Context) error {
+	err := s.teardown(ctx)
+	err = errors.Join(err, s.AutoCleanErr(ctx))
+	if s.shared {
+		return err
+	}
+	if clerr := s.moby.Close(); clerr != nil {
+		err = errors.Join(err, fmt.Errorf("cannot close Docker client, reason: %w", clerr))
+	}
+	return err
+}
+
+// onClose registers a teardown function to be run when closing this session.
+func (s *Session) onClose(fn func(context.Context) error) {
+	s.teardownsMu.Lock()
+	defer s.teardownsMu.Unlock()
+	s.teardowns = append(s.teardowns, fn)
+}
+
+// teardown runs the registered teardown functions in reverse order of their
+// registration, returning an aggregated error.
+func (s *Session) teardown(ctx context.Context) error {
+	s.teardownsMu.Lock()
+	teardowns := s.teardowns
+	s.teardowns = nil
+	s.teardownsMu.Unlock()
+	var errs []error
+	for _, fn := range slices.Backward(teardowns) {
+		errs = append(errs, fn(ctx))
+	}
+	return errors.Join(errs...)
 }
 
 // AutoClean forcefully removes all left-over containers and networks that are
 // labelled with the auto-cleaning label specified when creating this session.
 // If no auto-cleaning label was specified, AutoClean simply returns, doing
 // nothing. (Well, it does something: it returns ... but that is now too meta).
+//
+// Use [Session.AutoCleanErr] instead in order to learn about any left-overs
+// that could not be removed.
 func (s *Session) AutoClean(ctx context.Context) {
+	_ = s.AutoCleanErr(ctx)
+}
+
+// AutoCleanErr forcefully removes all left-over containers and networks that
+// are labelled with the auto-cleaning label specified when creating this
+// session, returning an aggregated error listing the containers and networks
+// that could not be removed. Auto-cleaning does not stop at the first failure,
+// but instead tries to remove as much as possible. If no auto-cleaning label
+// was specified, AutoCleanErr simply returns nil.
+//
+// Reusable containers that haven't expired yet are kept, see
+// [github.com/thediveo/morbyd/v2/run.WithReuse].
+func (s *Session) AutoCleanErr(ctx context.Context) error {
 	if s.opts.AutoCleaningLabel == "" {
-		return
+		return nil
 	}
-	s.autoClean(ctx, s.opts.AutoCleaningLabel)
+	return s.autoClean(ctx, s.opts.AutoCleaningLabel)
 }
 
-func (s *Session) autoClean(ctx context.Context, aclabel string) {
+// Removing a container that Docker is already in the process of removing
+// fails with a conflict; in this case, auto-cleaning retries removal in the
+// specified interval for the specified number of times.
+var (
+	autoCleanRetryInterval = 100 * time.Millisecond
+	autoCleanRetries       = 50
+)
+
+// autoClean removes all containers and then all networks matching the
+// specified auto-cleaning label, continuing past individual failures and
+// returning an aggregated error.
+func (s *Session) autoClean(ctx context.Context, aclabel string) (err error) {
 	// Assemble a filter based on the auto-cleaning label.
 	key, value, _ := strings.Cut(aclabel, "=")
 	if value == "" {
@@ -97,22 +175,49 @@ func (s *Session) autoClean(ctx context.Context, aclabel string) {
 	}
 	f := make(client.Filters).Add("label", aclabel)
 
-	// List all matching containers and then kill them.
+	ctx, op := s.begin(ctx, "autoclean", slog.String(AttrLabel, aclabel))
+	defer func() { op.end(err) }()
+
+	concurrency := s.opts.AutoCleanConcurrency
+	if concurrency <= 0 {
+		concurrency = session.DefaultAutoCleanConcurrency
+	}
+	var errs []error
+
+	// List all matching containers, including terminated ones, and then
+	// remove them; in case of listing errors we still try to remove the
+	// networks.
 	cntrs, err := s.moby.ContainerList(ctx, client.ContainerListOptions{
+		All:     true,
 		Filters: f,
 	})
 	if err != nil {
-		return
+		errs = append(errs, fmt.Errorf("cannot list containers, reason: %w", err))
 	}
-	for _, cntr := range cntrs.Items {
-		_, err := s.moby.ContainerRemove(ctx, cntr.ID, client.ContainerRemoveOptions{
-			Force:         true,
-			RemoveVolumes: true,
-		})
-		if err != nil {
-			return
+	cntrErrs := make([]error, len(cntrs.Items))
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for idx, cntr := range cntrs.Items {
+		// Reusable containers that haven't expired yet are to be kept.
+		if isReused(cntr.Labels) {
+			continue
 		}
+		g.Go(func() error {
+			name := strings.TrimPrefix(firstOf(cntr.Names), "/")
+			rmctx, rmop := s.begin(ctx, "autoclean.container",
+				slog.String(AttrContainerID, cntr.ID),
+				slog.String(AttrContainerName, name))
+			err := s.removeContainer(rmctx, cntr.ID)
+			rmop.end(err)
+			if err != nil {
+				cntrErrs[idx] = fmt.Errorf("cannot remove container %s (%s), reason: %w",
+					name, cntr.ID, err)
+			}
+			return nil
+		})
 	}
+	_ = g.Wait()
+	errs = append(errs, cntrErrs...)
 
 	// List all matching networks (which by now should not have any test
 	// containers attached to them anymore) and then remove them.
@@ -120,11 +225,72 @@ func (s *Session) autoClean(ctx context.Context, aclabel string) {
 		Filters: f,
 	})
 	if err != nil {
-		return
+		errs = append(errs, fmt.Errorf("cannot list networks, reason: %w", err))
 	}
-	for _, net := range nets.Items {
-		_, _ = s.moby.NetworkRemove(ctx, net.ID, client.NetworkRemoveOptions{})
+	netErrs := make([]error, len(nets.Items))
+	for idx, net := range nets.Items {
+		g.Go(func() error {
+			rmctx, rmop := s.begin(ctx, "autoclean.network",
+				slog.String(AttrNetworkID, net.ID),
+				slog.String(AttrNetworkName, net.Name))
+			_, err := s.moby.NetworkRemove(rmctx, net.ID, client.NetworkRemoveOptions{})
+			if errdefs.IsNotFound(err) {
+				err = nil
+			}
+			rmop.end(err)
+			if err != nil {
+				netErrs[idx] = fmt.Errorf("cannot remove network %s (%s), reason: %w",
+					net.Name, net.ID, err)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	errs = append(errs, netErrs...)
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("incomplete auto-cleaning of %q, reason: %w", aclabel, err)
+	}
+	return nil
+}
+
+// removeContainer forcefully removes the container with the specified ID,
+// retrying while Docker reports the container's removal to be already in
+// progress. Containers that have vanished in the meantime are considered to
+// have been removed successfully.
+func (s *Session) removeContainer(ctx context.Context, id string) error {
+	for attempt := 0; ; attempt++ {
+		_, err := s.moby.ContainerRemove(ctx, id, client.ContainerRemoveOptions{
+			Force:         true,
+			RemoveVolumes: true,
+		})
+		if err == nil || errdefs.IsNotFound(err) {
+			return nil
+		}
+		if !isRemovalInProgress(err) || attempt >= autoCleanRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(autoCleanRetryInterval):
+		}
+	}
+}
+
+// isRemovalInProgress returns true if the specified error indicates that
+// Docker is already in the process of removing a container.
+func isRemovalInProgress(err error) bool {
+	return errdefs.IsConflict(err) && strings.Contains(err.Error(), "already in progress")
+}
+
+// firstOf returns the first element of the specified slice, or the zero value
+// if the slice is empty.
+func firstOf[T any](s []T) (v T) {
+	if len(s) == 0 {
+		return
 	}
+	return s[0]
 }
 
 // Container returns a *Container object for the specified name or ID if it
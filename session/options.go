@@ -16,14 +16,25 @@ package session
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"strings"
 
 	"github.com/moby/moby/client"
+	"go.opentelemetry.io/otel/trace"
 
 	lbls "github.com/thediveo/morbyd/v2/labels"
 	"github.com/thediveo/morbyd/v2/moby"
 )
 
+// DefaultAutoCleanConcurrency is the default maximum number of containers,
+// respectively networks, that get removed concurrently when auto-cleaning.
+const DefaultAutoCleanConcurrency = 8
+
+// DefaultPullConcurrency is the default maximum number of images that
+// [github.com/thediveo/morbyd.Session.EnsureImages] pulls concurrently.
+const DefaultPullConcurrency = 4
+
 // Opt is a configuration option for creating sessions using
 // [github.com/thediveo/morbyd.NewSession].
 type Opt func(*Options) error
@@ -41,9 +52,39 @@ type Options struct {
 	// created containers and networks.
 	AutoCleaningLabel string
 
+	// The maximum number of containers, respectively networks, to remove
+	// concurrently when auto-cleaning. If zero, DefaultAutoCleanConcurrency
+	// applies.
+	AutoCleanConcurrency int
+
 	// A function supplied by a test option to wrap the Docker client with
 	// something else, such as a mock, double, or whatever you wanna call it.
 	Wrapper func(moby.Client) moby.Client
+
+	// If non-nil, Logger receives structured log records about the session
+	// operations, such as running containers and auto-cleaning.
+	Logger *slog.Logger
+
+	// If non-nil, TracerProvider supplies the tracer for creating a span per
+	// session operation.
+	TracerProvider trace.TracerProvider
+
+	// If true, registry credentials are taken from the Docker client
+	// configuration when pulling, pushing, and building images without
+	// explicit credentials.
+	RegistryAuthFromDockerConfig bool
+
+	// The maximum number of images to pull concurrently when ensuring images.
+	// If zero, DefaultPullConcurrency applies.
+	PullConcurrency int
+
+	// If non-nil, PullOutput receives the aggregated progress of ensuring
+	// images, one line per image and state change.
+	PullOutput io.Writer
+
+	// If true, containers get labelled with the equivalent “docker run”
+	// command line.
+	DockerCommandLabel bool
 }
 
 // WithAutoCleaning enables autocleaning containers and networks before and
@@ -63,6 +104,52 @@ func WithAutoCleaning(label string) Opt {
 	}
 }
 
+// WithAutoCleanConcurrency specifies the maximum number of containers,
+// respectively networks, to remove concurrently when auto-cleaning. It
+// defaults to [DefaultAutoCleanConcurrency].
+func WithAutoCleanConcurrency(n int) Opt {
+	return func(o *Options) error {
+		if n < 1 {
+			return fmt.Errorf("auto cleaning concurrency must be at least 1, got %d", n)
+		}
+		o.AutoCleanConcurrency = n
+		return nil
+	}
+}
+
+// WithPullConcurrency specifies the maximum number of images to pull
+// concurrently when ensuring images. It defaults to [DefaultPullConcurrency].
+func WithPullConcurrency(n int) Opt {
+	return func(o *Options) error {
+		if n < 1 {
+			return fmt.Errorf("pull concurrency must be at least 1, got %d", n)
+		}
+		o.PullConcurrency = n
+		return nil
+	}
+}
+
+// WithPullOutput specifies the writer to send the aggregated progress of
+// ensuring images to, such as “busybox:latest: pulled 3 layers, 1 already
+// present (sha256:...)”.
+func WithPullOutput(w io.Writer) Opt {
+	return func(o *Options) error {
+		o.PullOutput = w
+		return nil
+	}
+}
+
+// WithDockerCommandLabel specifies to label containers created in this session
+// with the equivalent copy-pasteable “docker run” command line, so that “docker
+// inspect” shows how to manually recreate a container, such as after a failed
+// test.
+func WithDockerCommandLabel() Opt {
+	return func(o *Options) error {
+		o.DockerCommandLabel = true
+		return nil
+	}
+}
+
 // WithLabel specifies a single key-value label to be automatically attached to
 // container images, containers, and networks created in this session. These
 // labels can be used, for instance, to automatically clean up any left-over
@@ -107,3 +194,39 @@ func WithDockerOpts(opts ...client.Opt) Opt {
 		return nil
 	}
 }
+
+// WithLogger specifies a structured logger to receive log records about
+// session operations, such as running containers, executing commands,
+// building and pulling images, creating networks, and auto-cleaning. The log
+// records include container, network, and image IDs, caller locations, and
+// durations.
+func WithLogger(logger *slog.Logger) Opt {
+	return func(o *Options) error {
+		o.Logger = logger
+		return nil
+	}
+}
+
+// WithRegistryAuthFromDockerConfig specifies to take the registry credentials
+// from the Docker client configuration in “config.json” when pulling, pushing,
+// and building images without explicitly specified credentials. The
+// configuration is read from the directory specified by the DOCKER_CONFIG
+// environment variable, defaulting to “~/.docker”. Credential stores
+// (“credsStore”) and per-registry credential helpers (“credHelpers”) are
+// supported by running the corresponding “docker-credential-*” helper
+// binaries, which must be in the PATH.
+func WithRegistryAuthFromDockerConfig() Opt {
+	return func(o *Options) error {
+		o.RegistryAuthFromDockerConfig = true
+		return nil
+	}
+}
+
+// WithTracerProvider specifies an OpenTelemetry tracer provider for creating a
+// span per session operation, so that slow test setups can be profiled.
+func WithTracerProvider(tp trace.TracerProvider) Opt {
+	return func(o *Options) error {
+		o.TracerProvider = tp
+		return nil
+	}
+}
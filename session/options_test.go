@@ -15,7 +15,11 @@
 package session
 
 import (
+	"io"
+	"log/slog"
+
 	"github.com/moby/moby/client"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -24,12 +28,20 @@ import (
 var _ = Describe("test session options", func() {
 
 	It("processes session options", func() {
+		logger := slog.New(slog.DiscardHandler)
+		tp := noop.NewTracerProvider()
 		sessos := Options{}
 		for _, opt := range []Opt{
 			WithAutoCleaning("test=morbyd-session"),
 			WithLabel("foo=bar"),
 			WithLabels("fool=bar", "jekyll=hyde"),
 			WithDockerOpts(client.WithHost("unix:///doh/run/docker.sock")),
+			WithAutoCleanConcurrency(42),
+			WithLogger(logger),
+			WithTracerProvider(tp),
+			WithRegistryAuthFromDockerConfig(),
+			WithPullConcurrency(2),
+			WithPullOutput(io.Discard),
 		} {
 			Expect(opt(&sessos)).To(Succeed())
 		}
@@ -42,6 +54,12 @@ var _ = Describe("test session options", func() {
 			HaveKeyWithValue("jekyll", "hyde"),
 		))
 		Expect(sessos.DockerClientOpts).To(HaveLen(1))
+		Expect(sessos.AutoCleanConcurrency).To(Equal(42))
+		Expect(sessos.Logger).To(BeIdenticalTo(logger))
+		Expect(sessos.TracerProvider).To(Equal(tp))
+		Expect(sessos.RegistryAuthFromDockerConfig).To(BeTrue())
+		Expect(sessos.PullConcurrency).To(Equal(2))
+		Expect(sessos.PullOutput).To(BeIdenticalTo(io.Discard))
 	})
 
 	It("reports errors when rejecting invalid session options", func() {
@@ -52,6 +70,10 @@ var _ = Describe("test session options", func() {
 			`auto cleaning label must be in format .*, got "="`)))
 		Expect(WithLabels("foo=bar", "=")(&sessos)).To(MatchError(MatchRegexp(
 			`label must be in format .*, got "="`)))
+		Expect(WithAutoCleanConcurrency(0)(&sessos)).To(MatchError(
+			"auto cleaning concurrency must be at least 1, got 0"))
+		Expect(WithPullConcurrency(0)(&sessos)).To(MatchError(
+			"pull concurrency must be at least 1, got 0"))
 	})
 
 })
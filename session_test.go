@@ -21,11 +21,14 @@ import (
 	"os"
 	"time"
 
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/api/types/network"
 	"github.com/moby/moby/client"
 	"github.com/thediveo/safe"
 	mock "go.uber.org/mock/gomock"
 
+	"github.com/thediveo/morbyd/v2/fakeengine"
 	"github.com/thediveo/morbyd/v2/run"
 	"github.com/thediveo/morbyd/v2/session"
 	"github.com/thediveo/morbyd/v2/timestamper"
@@ -36,6 +39,19 @@ import (
 	. "github.com/thediveo/success"
 )
 
+// containers returns the containers of the specified fake engine, regardless
+// of their state.
+func containers(ctx context.Context, engine *fakeengine.Engine) []container.Summary {
+	GinkgoHelper()
+	return Successful(engine.ContainerList(ctx, client.ContainerListOptions{All: true})).Items
+}
+
+// networks returns the networks of the specified fake engine.
+func networks(ctx context.Context, engine *fakeengine.Engine) []network.Summary {
+	GinkgoHelper()
+	return Successful(engine.NetworkList(ctx, client.NetworkListOptions{})).Items
+}
+
 var _ = Describe("test sessions", func() {
 
 	BeforeEach(func() {
@@ -97,10 +113,11 @@ var _ = Describe("test sessions", func() {
 
 			rec.NetworkList(Any, Any).
 				Return(client.NetworkListResult{}, errors.New("error IJK305I")) // ...real programmers ;)
-			sess.autoClean(ctx, "test.foo=bar")
+			Expect(sess.autoClean(ctx, "test.foo=bar")).To(MatchError(
+				ContainSubstring("cannot list networks, reason: error IJK305I")))
 		})
 
-		It("silently handles API network removal errors", func(ctx context.Context) {
+		It("reports API network removal errors", func(ctx context.Context) {
 			ctrl := mock.NewController(GinkgoT())
 			sess := Successful(NewSession(ctx,
 				WithMockController(ctrl, "NetworkList", "NetworkRemove")))
@@ -122,7 +139,65 @@ var _ = Describe("test sessions", func() {
 			rec.NetworkRemove(Any, mock.Eq("666"), Any).
 				Times(1).
 				Return(client.NetworkRemoveResult{}, errors.New("error IJK305I"))
-			sess.autoClean(ctx, "test.foo=bar")
+			Expect(sess.autoClean(ctx, "test.foo=bar")).To(MatchError(
+				ContainSubstring("cannot remove network  (666), reason: error IJK305I")))
+		})
+
+		Context("using a fake engine", func() {
+
+			var engine *fakeengine.Engine
+
+			BeforeEach(func() {
+				engine = fakeengine.New()
+				engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+			})
+
+			It("continues past failures and reports them", func(ctx context.Context) {
+				sess := Successful(NewSession(ctx,
+					fakeengine.WithEngine(engine),
+					session.WithAutoCleaning("test.morbyd=autoclean"),
+					session.WithAutoCleanConcurrency(2)))
+				defer sess.Close(ctx)
+
+				cntrs := []*Container{}
+				for _, name := range []string{"morbyd-ac-1", "morbyd-ac-2", "morbyd-ac-3"} {
+					cntrs = append(cntrs, Successful(sess.Run(ctx, "busybox", run.WithName(name))))
+				}
+				_ = Successful(sess.CreateNetwork(ctx, "morbyd-ac"))
+
+				engine.FailNext("ContainerRemove", errors.New("error DEAD-BEEF"))
+				err := sess.AutoCleanErr(ctx)
+				Expect(err).To(MatchError(And(
+					ContainSubstring(`incomplete auto-cleaning of "test.morbyd=autoclean"`),
+					ContainSubstring("reason: error DEAD-BEEF"))))
+				Expect(containers(ctx, engine)).To(HaveLen(1))
+				Expect(networks(ctx, engine)).NotTo(ContainElement(HaveField("Name", "morbyd-ac")))
+
+				Expect(sess.CloseErr(ctx)).To(Succeed())
+				Expect(containers(ctx, engine)).To(BeEmpty())
+				for _, cntr := range cntrs {
+					Expect(cntr.Refresh(ctx)).To(HaventFoundContainer())
+				}
+			})
+
+			It("retries removing containers with removal in progress", func(ctx context.Context) {
+				defer func(interval time.Duration) { autoCleanRetryInterval = interval }(autoCleanRetryInterval)
+				autoCleanRetryInterval = 10 * time.Millisecond
+
+				sess := Successful(NewSession(ctx,
+					fakeengine.WithEngine(engine),
+					session.WithAutoCleaning("test.morbyd=autoclean")))
+				defer sess.Close(ctx)
+
+				cntr := Successful(sess.Run(ctx, "busybox"))
+				inprogress := errdefs.ErrConflict.WithMessage(
+					"removal of container " + cntr.ID + " is already in progress")
+				engine.FailNext("ContainerRemove", inprogress)
+				engine.FailNext("ContainerRemove", inprogress)
+				Expect(sess.AutoCleanErr(ctx)).To(Succeed())
+				Expect(containers(ctx, engine)).To(BeEmpty())
+			})
+
 		})
 
 	})
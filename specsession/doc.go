@@ -0,0 +1,12 @@
+/*
+Package specsession creates morbyd test sessions scoped to the current Ginkgo
+spec, with automatic cleanup after the spec. This is the Ginkgo equivalent of
+[github.com/thediveo/morbyd/v2.NewTestSession].
+
+	It("runs a container", func(ctx context.Context) {
+		sess := specsession.New(ctx)
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		// ...
+	})
+*/
+package specsession
@@ -0,0 +1,76 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package specsession
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+
+	"github.com/thediveo/morbyd/v2"
+	"github.com/thediveo/morbyd/v2/session"
+)
+
+// New creates a new test session scoped to the current spec, failing the
+// spec if the session cannot be created. New must be called from inside a
+// setup or subject node, such as BeforeEach and It. The test session uses an
+// auto-cleaning label derived from the full text of the current spec, see
+// [morbyd.TestSessionLabel], unless overridden using
+// [session.WithAutoCleaning].
+//
+// New registers a cleanup using [ginkgo.DeferCleanup] that closes the session
+// after the spec. When the spec has failed, the cleanup first writes the
+// output of containers that are still running or that have terminated with a
+// non-zero exit code to the [ginkgo.GinkgoWriter]. Any left-overs that cannot
+// be removed fail the spec.
+func New(ctx context.Context, opts ...session.Opt) *morbyd.Session {
+	ginkgo.GinkgoHelper()
+	sess, err := morbyd.NewSession(ctx,
+		append([]session.Opt{
+			session.WithAutoCleaning(morbyd.TestSessionLabel(ginkgo.CurrentSpecReport().FullText())),
+		}, opts...)...)
+	if err != nil {
+		ginkgo.Fail("cannot create test session, reason: " + err.Error())
+	}
+	deferClose(sess)
+	return sess
+}
+
+// Child returns a new child session of the specified session, closing the
+// child session after the current spec. See [morbyd.Session.Child] for
+// details.
+func Child(ctx context.Context, sess *morbyd.Session, name string, opts ...session.Opt) *morbyd.Session {
+	ginkgo.GinkgoHelper()
+	child, err := sess.Child(ctx, name, opts...)
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	deferClose(child)
+	return child
+}
+
+// deferClose registers a cleanup closing the specified session, dumping the
+// logs of failed and still running containers when the current spec has
+// failed.
+func deferClose(sess *morbyd.Session) {
+	ginkgo.DeferCleanup(func(ctx context.Context) {
+		if ginkgo.CurrentSpecReport().Failed() {
+			sess.DumpContainerLogs(ctx, ginkgo.GinkgoWriter.Printf)
+		}
+		if err := sess.CloseErr(ctx); err != nil {
+			ginkgo.Fail("cannot close test session, reason: " + err.Error())
+		}
+	})
+}
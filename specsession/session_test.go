@@ -0,0 +1,49 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package specsession
+
+import (
+	"context"
+
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2"
+	"github.com/thediveo/morbyd/v2/fakeengine"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("spec sessions", Ordered, func() {
+
+	engine := fakeengine.New()
+	engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+
+	It("creates a session scoped to the current spec", func(ctx context.Context) {
+		sess := New(ctx, fakeengine.WithEngine(engine))
+		child := Child(ctx, sess, "child")
+		cntr := Successful(sess.Run(ctx, "busybox"))
+		Expect(cntr.Details.Container.Config.Labels).To(HaveKeyWithValue(
+			morbyd.TestSessionLabelName, HavePrefix(CurrentSpecReport().FullText()+".")))
+		_ = Successful(child.Run(ctx, "busybox"))
+	})
+
+	It("has cleaned up after the previous spec", func(ctx context.Context) {
+		Expect(Successful(engine.ContainerList(ctx, client.ContainerListOptions{All: true})).Items).
+			To(BeEmpty())
+	})
+
+})
@@ -0,0 +1,69 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"io"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+)
+
+// attachStreams copies the output of an attached container or command from
+// the hijacked connection to the specified writers in the background, as well
+// as the optional input to the connection. When not using a TTY, attachStreams
+// demultiplexes the output into stdout and stderr; otherwise, the single
+// combined output goes to stdout. Missing writers discard their output.
+//
+// The returned channel gets closed after the output has ended and the input
+// (if any) has been fully copied, and the connection has been closed.
+func attachStreams(conn client.HijackedResponse, tty bool, in io.Reader, stdout, stderr io.Writer) chan struct{} {
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	stdinDone := make(chan struct{})
+	allDone := make(chan struct{})
+	// Deal with the output stream...
+	go func() {
+		defer func() {
+			<-stdinDone
+			conn.Close()
+			close(allDone)
+		}()
+
+		if tty {
+			// When using a TTY, only copy the single combined output stream
+			// into the output stream specified in the options.
+			_, _ = io.Copy(stdout, conn.Reader)
+			return
+		}
+		// When NOT using a TTY, use Docker's own helper to demux the two
+		// multiplexed streams into stdout and stderr writers.
+		_, _ = stdcopy.StdCopy(stdout, stderr, conn.Reader)
+	}()
+	// Deal with the input stream, where necessary.
+	if in != nil {
+		go func() {
+			defer close(stdinDone)
+			_, _ = io.Copy(conn.Conn, in)
+		}()
+	} else {
+		close(stdinDone)
+	}
+	return allDone
+}
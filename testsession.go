@@ -0,0 +1,185 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/session"
+)
+
+// TestSessionLabelName defines the name of the auto-cleaning label of test
+// sessions created using [NewTestSession].
+const TestSessionLabelName = MorbydLabelNamespace + "test"
+
+// TestSessionLabel returns the auto-cleaning label in “KEY=VALUE” format for
+// a test session scoped to the test with the specified name. The label value
+// is derived from the test name as well as the current working directory; as
+// “go test” runs the tests of a package inside the package's directory, the
+// same test names in different packages thus get different labels.
+func TestSessionLabel(testname string) string {
+	h := fnv.New32a()
+	if wd, err := os.Getwd(); err == nil {
+		_, _ = h.Write([]byte(wd))
+	}
+	return fmt.Sprintf("%s=%s.%08x", TestSessionLabelName, testname, h.Sum32())
+}
+
+// NewTestSession creates a new test session scoped to the specified test,
+// failing the test if the session cannot be created. The test session uses an
+// auto-cleaning label derived from the test name, see [TestSessionLabel],
+// unless overridden using [session.WithAutoCleaning].
+//
+// NewTestSession registers a cleanup function with the test that closes the
+// session after the test and its subtests have completed. When the test has
+// failed, the cleanup function first logs the output of containers that are
+// still running or that have terminated with a non-zero exit code, see also
+// [Session.DumpContainerLogs]. Any left-overs that cannot be removed fail the
+// test.
+func NewTestSession(t testing.TB, opts ...session.Opt) *Session {
+	t.Helper()
+	sess, err := NewSession(t.Context(),
+		append([]session.Opt{session.WithAutoCleaning(TestSessionLabel(t.Name()))}, opts...)...)
+	if err != nil {
+		t.Fatalf("cannot create test session, reason: %s", err.Error())
+	}
+	t.Cleanup(func() {
+		// The test's context has already been cancelled when cleanup
+		// functions run.
+		ctx := context.Background()
+		if t.Failed() {
+			sess.DumpContainerLogs(ctx, t.Logf)
+		}
+		if err := sess.CloseErr(ctx); err != nil {
+			t.Errorf("cannot close test session, reason: %s", err.Error())
+		}
+	})
+	return sess
+}
+
+// Child returns a new child session sharing this session's Docker client, but
+// using a narrower auto-cleaning label derived from this session's
+// auto-cleaning label and the specified child name. Containers and networks
+// created in the child session carry both the parent's and the child's
+// labels, so that the child session can be cleaned independently of its
+// parent and siblings, while the parent session still cleans everything.
+//
+// If this session's auto-cleaning label is in “KEY=” format without a value,
+// the child session's auto-cleaning label instead becomes “KEY=NAME”.
+//
+// Closing a child session does not close the shared Docker client. Additional
+// options are applied to the child session after inheriting the parent's
+// options; the Docker client-related options are ignored.
+func (s *Session) Child(ctx context.Context, name string, opts ...session.Opt) (*Session, error) {
+	if name == "" || strings.Contains(name, "=") {
+		return nil, fmt.Errorf("cannot create child session, reason: invalid child name %q", name)
+	}
+	child := &Session{
+		opts:   s.opts,
+		moby:   s.moby,
+		shared: true,
+	}
+	child.opts.Labels = maps.Clone(s.opts.Labels)
+	if s.opts.AutoCleaningLabel != "" {
+		key, value, _ := strings.Cut(s.opts.AutoCleaningLabel, "=")
+		label := key + "." + name + "=" + value
+		if value == "" {
+			label = key + "=" + name
+		}
+		if err := session.WithAutoCleaning(label)(&child.opts); err != nil {
+			return nil, fmt.Errorf("cannot create child session, reason: %w", err)
+		}
+	}
+	for _, opt := range opts {
+		if err := opt(&child.opts); err != nil {
+			return nil, fmt.Errorf("cannot create child session, reason: %w", err)
+		}
+	}
+	child.AutoClean(ctx)
+	return child, nil
+}
+
+// DumpContainerLogs passes the output of the containers in this session that
+// are still running or that have terminated with a non-zero exit code to the
+// specified logf function, such as [testing.T.Logf]. The containers of this
+// session are identified by their auto-cleaning label; if the session has no
+// auto-cleaning label, DumpContainerLogs does nothing.
+func (s *Session) DumpContainerLogs(ctx context.Context, logf func(format string, args ...any)) {
+	if s.opts.AutoCleaningLabel == "" {
+		return
+	}
+	key, value, _ := strings.Cut(s.opts.AutoCleaningLabel, "=")
+	label := s.opts.AutoCleaningLabel
+	if value == "" {
+		label = key
+	}
+	cntrs, err := s.moby.ContainerList(ctx, client.ContainerListOptions{
+		All:     true,
+		Filters: make(client.Filters).Add("label", label),
+	})
+	if err != nil {
+		logf("cannot list containers, reason: %s", err.Error())
+		return
+	}
+	for _, cntr := range cntrs.Items {
+		details, err := s.moby.ContainerInspect(ctx, cntr.ID, client.ContainerInspectOptions{})
+		if err != nil {
+			continue
+		}
+		state := details.Container.State
+		if state == nil || (!state.Running && state.ExitCode == 0) {
+			continue
+		}
+		logs, err := s.containerLogs(ctx, details.Container)
+		if err != nil {
+			logf("cannot retrieve logs of container %s (%s), reason: %s",
+				details.Container.Name[1:], cntr.ID, err.Error())
+			continue
+		}
+		logf("container %s (%s), %s, exit code %d, logs:\n%s",
+			details.Container.Name[1:], cntr.ID, state.Status, state.ExitCode, logs)
+	}
+}
+
+// containerLogs returns the combined stdout and stderr output of the specified
+// container.
+func (s *Session) containerLogs(ctx context.Context, details container.InspectResponse) (string, error) {
+	rc, err := s.moby.ContainerLogs(ctx, details.ID, client.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close() //nolint:errcheck // any error is irrelevant at this point
+	var logs bytes.Buffer
+	if details.Config != nil && details.Config.Tty {
+		_, err = logs.ReadFrom(rc)
+	} else {
+		_, err = stdcopy.StdCopy(&logs, &logs, rc)
+	}
+	return logs.String(), err
+}
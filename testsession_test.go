@@ -0,0 +1,183 @@
+// Copyright 2026 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package morbyd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/moby/moby/client"
+
+	"github.com/thediveo/morbyd/v2/fakeengine"
+	"github.com/thediveo/morbyd/v2/run"
+	"github.com/thediveo/morbyd/v2/session"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+// fakeTB is a testing.TB double for checking NewTestSession; calling any
+// method not implemented by fakeTB panics.
+type fakeTB struct {
+	testing.TB
+	ctx      context.Context
+	name     string
+	failed   bool
+	cleanups []func()
+	logs     []string
+	errors   []string
+}
+
+func (t *fakeTB) Helper()                  {}
+func (t *fakeTB) Name() string             { return t.name }
+func (t *fakeTB) Context() context.Context { return t.ctx }
+func (t *fakeTB) Failed() bool             { return t.failed }
+func (t *fakeTB) Cleanup(f func())         { t.cleanups = append(t.cleanups, f) }
+
+func (t *fakeTB) Logf(format string, args ...any) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTB) Errorf(format string, args ...any) {
+	t.failed = true
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTB) Fatalf(format string, args ...any) {
+	t.Errorf(format, args...)
+	panic("fatal")
+}
+
+// cleanup runs the registered cleanup functions in reverse order.
+func (t *fakeTB) cleanup() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+var _ = Describe("per-test sessions", func() {
+
+	var engine *fakeengine.Engine
+
+	BeforeEach(func() {
+		engine = fakeengine.New()
+		engine.AddImage(fakeengine.Image{Ref: "busybox", Cmd: []string{"sh"}})
+		engine.Handle("fail", func(ctx context.Context, proc *fakeengine.Process) int {
+			_, _ = proc.Stderr.Write([]byte("D'OH!\n"))
+			return 42
+		})
+		engine.Handle("true", func(ctx context.Context, proc *fakeengine.Process) int {
+			_, _ = proc.Stdout.Write([]byte("all is fine\n"))
+			return 0
+		})
+	})
+
+	It("derives unique auto-cleaning labels from test names", func() {
+		Expect(TestSessionLabel("TestFoo")).To(MatchRegexp(
+			`^github\.com/thediveo/morbyd/test=TestFoo\.[[:xdigit:]]{8}$`))
+		Expect(TestSessionLabel("TestFoo")).To(Equal(TestSessionLabel("TestFoo")))
+		Expect(TestSessionLabel("TestFoo")).NotTo(Equal(TestSessionLabel("TestBar")))
+	})
+
+	It("cleans up after a test", func(ctx context.Context) {
+		t := &fakeTB{ctx: ctx, name: "TestCleanup"}
+		sess := NewTestSession(t, fakeengine.WithEngine(engine))
+		Expect(sess.opts.AutoCleaningLabel).To(Equal(TestSessionLabel("TestCleanup")))
+		Expect(t.cleanups).To(HaveLen(1))
+
+		cntr := Successful(sess.Run(ctx, "busybox", run.WithCommand("fail")))
+		Expect(cntr.Wait(ctx)).To(Succeed())
+		t.cleanup()
+		Expect(t.logs).To(BeEmpty())
+		Expect(t.errors).To(BeEmpty())
+		Expect(cntr.Refresh(ctx)).To(HaventFoundContainer())
+	})
+
+	It("dumps container logs of a failed test", func(ctx context.Context) {
+		t := &fakeTB{ctx: ctx, name: "TestFailing"}
+		sess := NewTestSession(t, fakeengine.WithEngine(engine))
+
+		failing := Successful(sess.Run(ctx, "busybox",
+			run.WithName("morbyd-failing"), run.WithCommand("fail")))
+		Expect(failing.Wait(ctx)).To(Succeed())
+		succeeding := Successful(sess.Run(ctx, "busybox",
+			run.WithName("morbyd-succeeding"), run.WithCommand("true")))
+		Expect(succeeding.Wait(ctx)).To(Succeed())
+		_ = Successful(sess.Run(ctx, "busybox", run.WithName("morbyd-running")))
+
+		t.failed = true
+		t.cleanup()
+		Expect(t.logs).To(ConsistOf(
+			And(ContainSubstring("container morbyd-failing"),
+				ContainSubstring("exit code 42"),
+				ContainSubstring("D'OH!")),
+			ContainSubstring("container morbyd-running"),
+		))
+		Expect(Successful(engine.ContainerList(ctx, client.ContainerListOptions{All: true})).Items).
+			To(BeEmpty())
+	})
+
+	It("cleans child sessions independently", func(ctx context.Context) {
+		t := &fakeTB{ctx: ctx, name: "TestChildren"}
+		sess := NewTestSession(t, fakeengine.WithEngine(engine))
+
+		child1 := Successful(sess.Child(ctx, "child1"))
+		Expect(child1.Client()).To(BeIdenticalTo(sess.Client()))
+		child2 := Successful(sess.Child(ctx, "child2"))
+
+		cntr1 := Successful(child1.Run(ctx, "busybox"))
+		cntr2 := Successful(child2.Run(ctx, "busybox"))
+		Expect(cntr1.Details.Container.Config.Labels).To(And(
+			HaveKey(TestSessionLabelName),
+			HaveKey(TestSessionLabelName+".child1")))
+
+		Expect(child1.CloseErr(ctx)).To(Succeed())
+		Expect(cntr1.Refresh(ctx)).To(HaventFoundContainer())
+		Expect(cntr2.Refresh(ctx)).To(Succeed())
+
+		t.cleanup()
+		Expect(t.errors).To(BeEmpty())
+		Expect(cntr2.Refresh(ctx)).To(HaventFoundContainer())
+	})
+
+	It("rejects invalid child names", func(ctx context.Context) {
+		sess := Successful(NewSession(ctx, fakeengine.WithEngine(engine)))
+		defer sess.Close(ctx)
+		Expect(sess.Child(ctx, "")).Error().To(MatchError(ContainSubstring("invalid child name")))
+		Expect(sess.Child(ctx, "foo=bar")).Error().To(MatchError(ContainSubstring("invalid child name")))
+	})
+
+	It("derives child labels from key-only labels", func(ctx context.Context) {
+		sess := Successful(NewSession(ctx,
+			fakeengine.WithEngine(engine),
+			session.WithAutoCleaning("test.morbyd.keyonly=")))
+		defer sess.Close(ctx)
+
+		child := Successful(sess.Child(ctx, "child"))
+		cntr := Successful(child.Run(ctx, "busybox"))
+		Expect(cntr.Details.Container.Config.Labels).To(
+			HaveKeyWithValue("test.morbyd.keyonly", "child"))
+		other := Successful(sess.Run(ctx, "busybox"))
+
+		Expect(child.CloseErr(ctx)).To(Succeed())
+		Expect(cntr.Refresh(ctx)).To(HaventFoundContainer())
+		Expect(other.Refresh(ctx)).To(Succeed())
+		Expect(sess.CloseErr(ctx)).To(Succeed())
+		Expect(other.Refresh(ctx)).To(HaventFoundContainer())
+	})
+
+})